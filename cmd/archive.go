@@ -0,0 +1,160 @@
+package main
+
+import (
+	"flag"    // For registering the filter flags
+	"fmt"     // For usage output
+	"log"     // For reporting errors
+	"os"      // For the usage exit status
+	"strings" // For matching domains and keywords
+	"time"    // For the time range
+
+	"github.com/hail2skins/zero-scraper/internal/digest" // The article items the archive commands work on.
+	"github.com/hail2skins/zero-scraper/internal/store"  // On-disk store of raw fetched pages.
+	"github.com/hail2skins/zero-scraper/pkg/scraper"     // Extraction of the stored pages.
+)
+
+// archiveFilter selects stored articles for the commands that read -html-dir stores: a
+// time range of fetches, sites, and keywords.
+type archiveFilter struct {
+	since, until, domains, keywords, extractors string
+}
+
+// addFlags registers the filter's flags on fs.
+func (f *archiveFilter) addFlags(fs *flag.FlagSet) {
+	fs.StringVar(&f.since, "since", "24h", "Start of the range: a duration before -until (e.g. 24h) or a date or RFC 3339 time")
+	fs.StringVar(&f.until, "until", "", "End of the range: a date or RFC 3339 time (now if empty)")
+	fs.StringVar(&f.domains, "domains", "", "Comma-separated sites to include, subdomains included (all if empty)")
+	fs.StringVar(&f.keywords, "keywords", "", "Comma-separated keywords, one of which an article must mention (any article if empty)")
+	fs.StringVar(&f.extractors, "extractors", "selectors", "Extractor chain to read the stored pages with, as for the scraper's -extractors")
+}
+
+// load returns the filter's time range and the matching articles stored in dirs, oldest
+// fetch first within each store.
+func (f *archiveFilter) load(dirs []string) (from, to time.Time, items []digest.Item) {
+	to = time.Now()
+	if f.until != "" {
+		t, err := parseTime(f.until)
+		if err != nil {
+			log.Fatalf("Invalid -until: %v", err)
+		}
+		to = t
+	}
+	from, err := parseTime(f.since)
+	if d, derr := time.ParseDuration(f.since); derr == nil {
+		from, err = to.Add(-d), nil
+	}
+	if err != nil {
+		log.Fatalf("Invalid -since: %v", err)
+	}
+	extractor, err := scraper.ParseChain(f.extractors)
+	if err != nil {
+		log.Fatalf("Error in -extractors: %v", err)
+	}
+	s := scraper.New(scraper.WithExtractor(extractor))
+
+	for _, dir := range dirs {
+		st, err := openStore(dir)
+		if err != nil {
+			log.Fatalf("Error opening HTML store: %v", err)
+		}
+		err = st.Each(func(p store.Page) error {
+			// Pages whose bodies retention has deleted have nothing left to read.
+			if p.HTML == "" || p.FetchedAt.Before(from) || p.FetchedAt.After(to) || !matchesDomain(p.URL, f.domains) {
+				return nil
+			}
+			a, err := s.ScrapeHTML(p.URL, p.HTML)
+			if err != nil {
+				log.Printf("Error extracting %s: %v", p.URL, err)
+				return nil
+			}
+			if !mentionsKeyword(a.Content, f.keywords) {
+				return nil
+			}
+			items = append(items, digest.Item{URL: p.URL, Title: a.Title, Byline: a.Byline, Authors: a.Authors, Published: a.Published, FetchedAt: p.FetchedAt, Content: a.Content})
+			return nil
+		})
+		if err != nil {
+			log.Fatalf("Error reading %s: %v", dir, err)
+		}
+	}
+	return from, to, items
+}
+
+// runArchive implements "zero-scraper archive": export writes an -html-dir store to a
+// portable dump, and import adds a dump to a store, for moving an archive between
+// machines or storage backends.
+func runArchive(args []string) {
+	usage := func() {
+		fmt.Fprintln(os.Stderr, "Usage: zero-scraper archive export html-dir dump-dir")
+		fmt.Fprintln(os.Stderr, "       zero-scraper archive import dump-dir html-dir")
+		fmt.Fprintln(os.Stderr, "A dump is a directory of pages.jsonl and the pages' HTML under bodies/.")
+	}
+	if len(args) != 3 {
+		usage()
+		os.Exit(2)
+	}
+	switch args[0] {
+	case "export":
+		st, err := openStore(args[1])
+		if err != nil {
+			log.Fatalf("Error opening HTML store: %v", err)
+		}
+		n, err := st.Export(args[2])
+		if err != nil {
+			log.Fatalf("Error exporting %s: %v", args[1], err)
+		}
+		log.Printf("Exported %d pages from %s to %s", n, args[1], args[2])
+	case "import":
+		st, err := openStore(args[2])
+		if err != nil {
+			log.Fatalf("Error opening HTML store: %v", err)
+		}
+		imported, skipped, err := st.Import(args[1])
+		if err != nil {
+			log.Fatalf("Error importing %s after %d pages: %v", args[1], imported, err)
+		}
+		log.Printf("Imported %d pages from %s into %s; kept the store's copy of %d fetched as late or later", imported, args[1], args[2], skipped)
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+// parseTime parses a date ("2006-01-02", midnight UTC) or an RFC 3339 time.
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// matchesDomain reports whether rawURL is on one of the comma-separated domains or their
+// subdomains. An empty list matches every URL.
+func matchesDomain(rawURL, domains string) bool {
+	if domains == "" {
+		return true
+	}
+	host := digest.Item{URL: rawURL}.Host()
+	for _, d := range strings.Split(domains, ",") {
+		d = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(d), "www."))
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// mentionsKeyword reports whether content mentions one of the comma-separated keywords,
+// ignoring case. An empty list matches any content.
+func mentionsKeyword(content, keywords string) bool {
+	if keywords == "" {
+		return true
+	}
+	content = strings.ToLower(content)
+	for _, k := range strings.Split(keywords, ",") {
+		if k = strings.ToLower(strings.TrimSpace(k)); k != "" && strings.Contains(content, k) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"flag"          // For the authors command's own flags
+	"fmt"           // For usage output
+	"log"           // For reporting errors
+	"os"            // For writing to stdout and the output directory
+	"path/filepath" // For naming the per-author files
+
+	"github.com/hail2skins/zero-scraper/internal/authors" // Aggregating the archive by author.
+	"github.com/hail2skins/zero-scraper/internal/digest"  // Topics of the stored articles.
+)
+
+// runAuthors implements "zero-scraper authors": it aggregates the articles stored in
+// -html-dir stores by author, with each author's outlets, topics, and first and last
+// appearance. It prints every profile as JSON, or one author's profile as JSON or RSS,
+// or writes a JSON profile and an RSS feed for every author to a directory.
+func runAuthors(args []string) {
+	fs := flag.NewFlagSet("authors", flag.ExitOnError)
+	var filter archiveFilter
+	filter.addFlags(fs)
+	author := fs.String("author", "", "Print only this author's profile, by name or slug (every author if empty)")
+	format := fs.String("format", authors.JSON, "Output format for -author: json or rss")
+	outDir := fs.String("out-dir", "", "Write <slug>.json and <slug>.rss for every author to this directory instead of printing")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: zero-scraper authors [flags] html-dir...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if *format != authors.JSON && *format != authors.RSS {
+		log.Fatalf("Invalid -format %q: want json or rss", *format)
+	}
+	if *format == authors.RSS && *author == "" && *outDir == "" {
+		log.Fatal("-format rss needs -author or -out-dir: a feed covers one author")
+	}
+
+	from, to, items := filter.load(fs.Args())
+	profiles := authors.Aggregate(digest.Build("", from, to, items))
+
+	switch {
+	case *outDir != "":
+		if err := os.MkdirAll(*outDir, 0o755); err != nil {
+			log.Fatalf("Error creating -out-dir: %v", err)
+		}
+		for _, p := range profiles {
+			for _, f := range []string{authors.JSON, authors.RSS} {
+				if err := writeProfile(filepath.Join(*outDir, p.Slug+"."+f), p, f); err != nil {
+					log.Fatalf("Error writing profile of %s: %v", p.Name, err)
+				}
+			}
+		}
+		log.Printf("Wrote %d author profiles to %s", len(profiles), *outDir)
+	case *author != "":
+		p, ok := authors.Find(profiles, *author)
+		if !ok {
+			log.Fatalf("No stored articles by %s match the filters", *author)
+		}
+		if err := authors.Render(os.Stdout, p, *format); err != nil {
+			log.Fatalf("Error writing profile: %v", err)
+		}
+	default:
+		if err := authors.RenderJSON(os.Stdout, profiles); err != nil {
+			log.Fatalf("Error writing profiles: %v", err)
+		}
+	}
+}
+
+// writeProfile writes one author's profile to path in format.
+func writeProfile(path string, p authors.Profile, format string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := authors.Render(f, p, format); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
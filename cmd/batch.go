@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"   // For holding back each URL's console output
+	"context" // For stopping the batch on interrupt
+	"io"      // For the writer each scrape prints to
+	"net/url" // For the host of each URL
+	"os"      // For writing the console output
+	"strings" // For comparing hosts regardless of case
+	"sync"    // For waiting for the workers
+
+	"github.com/hail2skins/zero-scraper/pkg/scraper" // Resolver whose lookups are prefetched.
+)
+
+// batchLookahead is how many URLs per worker the batch reads ahead of the one whose
+// output is due, to choose among when interleaving hosts.
+const batchLookahead = 4
+
+// batchJob is one URL of a batch and, once it has been scraped, its console output and error.
+type batchJob struct {
+	url  string
+	host string
+	out  bytes.Buffer
+	err  error
+	done chan struct{}
+}
+
+// runBatch scrapes every URL received from urls with up to workers scrapes at a time.
+// Each URL's console output is held back until the output of every URL before it has
+// been written, so the console reads as if the URLs had been scraped one by one, and
+// finished is called for each URL in that same order. Sinks receive articles as they
+// are scraped. runBatch returns once urls is closed and every URL taken from it is done.
+//
+// The URLs are not started strictly in order: of the next few, the one whose host was
+// started least recently goes first, so that one site's rate limits do not leave the
+// workers waiting on it while other sites' URLs queue behind. Each host's lookup is
+// prefetched through resolver, if set, as soon as its first URL is read.
+func runBatch(ctx context.Context, urls <-chan string, workers int, resolver *scraper.Resolver, scrape func(ctx context.Context, url string, out io.Writer) error, finished func(url string, err error)) {
+	jobs := make(chan *batchJob)
+	// The URLs read ahead are bounded, so a slow URL holds up only that many finished ones
+	// in memory instead of the rest of the batch.
+	window := workers * batchLookahead
+	ordered := make(chan *batchJob, window)
+	// written receives a value as each job's output is written, making room for another.
+	written := make(chan struct{}, window)
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				j.err = scrape(ctx, j.url, &j.out)
+				close(j.done)
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		defer close(ordered)
+		var pending []*batchJob     // pending are read but not started, in order.
+		started := map[string]int{} // started holds the sequence number of each host's latest start.
+		var seq, outstanding int
+		in := urls
+		for in != nil || len(pending) > 0 {
+			// Read another URL while there is room, and start the pending one whose host
+			// was started least recently.
+			read := in
+			if outstanding >= window {
+				read = nil
+			}
+			var start chan<- *batchJob
+			var next int
+			var nextJob *batchJob
+			for i, j := range pending {
+				if nextJob == nil || started[j.host] < started[nextJob.host] {
+					next, nextJob = i, j
+				}
+			}
+			if nextJob != nil {
+				start = jobs
+			}
+			select {
+			case u, ok := <-read:
+				if !ok {
+					in = nil
+					continue
+				}
+				j := &batchJob{url: u, host: hostOf(u), done: make(chan struct{})}
+				if _, seen := started[j.host]; !seen {
+					started[j.host] = 0
+					resolver.Prefetch(j.host)
+				}
+				pending = append(pending, j)
+				outstanding++
+				ordered <- j
+			case start <- nextJob:
+				seq++
+				started[nextJob.host] = seq
+				pending = append(pending[:next], pending[next+1:]...)
+			case <-written:
+				outstanding--
+			}
+		}
+	}()
+
+	for j := range ordered {
+		<-j.done
+		os.Stdout.Write(j.out.Bytes())
+		finished(j.url, j.err)
+		written <- struct{}{}
+	}
+	wg.Wait()
+}
+
+// hostOf returns the lowercase host of rawURL, or "" if it has none.
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"    // For the bench command's own flags
+	"fmt"     // For usage output
+	"log"     // For reporting errors
+	"os"      // For writing the report to stdout
+	"sort"    // For a stable extractor order
+	"strings" // For splitting the extractor list
+
+	"github.com/hail2skins/zero-scraper/internal/bench" // Extraction benchmarks over fixture corpora.
+)
+
+// runBench implements "zero-scraper bench": it runs each extractor over a directory of saved
+// HTML fixtures and reports throughput, allocations, and latency percentiles.
+func runBench(args []string) {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	iterations := fs.Int("n", 10, "Number of passes over the corpus")
+	only := fs.String("extractors", "", "Comma-separated extractors to run (default: all)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: zero-scraper bench [-n passes] [-extractors list] fixture-dir")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 || *iterations < 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	corpus, err := bench.LoadCorpus(fs.Arg(0))
+	if err != nil {
+		log.Fatalf("Error loading fixtures: %v", err)
+	}
+
+	var names []string
+	if *only != "" {
+		names = strings.Split(*only, ",")
+	} else {
+		for name := range bench.Extractors {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	var results []bench.Result
+	for _, name := range names {
+		ex, ok := bench.Extractors[name]
+		if !ok {
+			log.Fatalf("Unknown extractor %q", name)
+		}
+		results = append(results, bench.Run(name, ex, corpus, *iterations))
+	}
+	bench.Print(os.Stdout, results)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"    // For the compare-extractors command's own flags
+	"fmt"     // For usage output and the summary
+	"log"     // For reporting errors
+	"os"      // For writing the report to stdout
+	"strings" // For telling URLs from paths
+
+	"github.com/hail2skins/zero-scraper/internal/compare" // Field-level diffs between extractions.
+	"github.com/hail2skins/zero-scraper/pkg/scraper"      // The extractor configurations being compared.
+)
+
+// runCompare implements "zero-scraper compare-extractors": it runs two extractor
+// configurations over the same pages and reports every field that comes out differently,
+// so a change to the heuristics can be checked before it is rolled out. Each input is a
+// URL, fetched once and extracted both ways, or saved pages as accepted by reextract.
+func runCompare(args []string) {
+	fs := flag.NewFlagSet("compare-extractors", flag.ExitOnError)
+	specA := fs.String("a", "selectors", "Extractor chain for configuration a, as for -extractors")
+	specB := fs.String("b", "", "Extractor chain for configuration b, as for -extractors (required)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: zero-scraper compare-extractors [-a chain] -b chain url|html-dir|records.jsonl...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *specB == "" || fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	xa, err := scraper.ParseChain(*specA)
+	if err != nil {
+		log.Fatalf("Error in -a: %v", err)
+	}
+	xb, err := scraper.ParseChain(*specB)
+	if err != nil {
+		log.Fatalf("Error in -b: %v", err)
+	}
+	sa, sb := scraper.New(scraper.WithExtractor(xa)), scraper.New(scraper.WithExtractor(xb))
+
+	var pages, differing int
+	fields := make(map[string]int)
+	emit := func(url, html string) error {
+		a, err := sa.ScrapeHTML(url, html)
+		if err != nil {
+			// Extraction only fails on unparseable HTML, which fails both ways alike.
+			log.Printf("Error extracting %s: %v", url, err)
+			return nil
+		}
+		b, err := sb.ScrapeHTML(url, html)
+		if err != nil {
+			log.Printf("Error extracting %s: %v", url, err)
+			return nil
+		}
+		pages++
+		diffs := compare.Articles(a, b)
+		if len(diffs) == 0 {
+			return nil
+		}
+		differing++
+		for _, d := range diffs {
+			fields[d.Field]++
+		}
+		compare.Print(os.Stdout, url, diffs)
+		return nil
+	}
+
+	for _, input := range fs.Args() {
+		if strings.HasPrefix(input, "http://") || strings.HasPrefix(input, "https://") {
+			html, err := sa.FetchHTML(input)
+			if err != nil {
+				log.Printf("Error fetching %s: %v", input, err)
+				continue
+			}
+			emit(input, html)
+			continue
+		}
+		if err := eachSavedPage(input, emit); err != nil {
+			log.Fatalf("Error reading %s: %v", input, err)
+		}
+	}
+
+	fmt.Printf("%d of %d pages differ (title: %d, content: %d, byline: %d, published: %d, authors: %d)\n",
+		differing, pages, fields["title"], fields["content"], fields["byline"], fields["published"], fields["authors"])
+}
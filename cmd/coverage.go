@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag" // For the coverage command's own flags
+	"fmt"  // For usage output
+	"log"  // For reporting errors
+	"os"   // For writing the report to stdout
+
+	"github.com/hail2skins/zero-scraper/internal/coverage" // Cross-outlet coverage comparison.
+	"github.com/hail2skins/zero-scraper/internal/digest"   // Story clusters of stored articles.
+)
+
+// runCoverage implements "zero-scraper coverage": it compares how outlets covered one
+// story, given either by keywords every article is filtered on or by one article's URL,
+// whose story cluster is compared, and writes the report as JSON or HTML.
+func runCoverage(args []string) {
+	fs := flag.NewFlagSet("coverage", flag.ExitOnError)
+	var filter archiveFilter
+	filter.addFlags(fs)
+	story := fs.String("story", "", "URL of a stored article whose story cluster to compare (every matching article if empty)")
+	format := fs.String("format", coverage.JSON, "Output format: json or html")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: zero-scraper coverage [-keywords list | -story url] [flags] html-dir...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 || (*story == "" && filter.keywords == "") {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if *format != coverage.JSON && *format != coverage.HTML {
+		log.Fatalf("Invalid -format %q: want json or html", *format)
+	}
+
+	from, to, items := filter.load(fs.Args())
+	name := filter.keywords
+	if *story != "" {
+		items = storyCluster(digest.Build("", from, to, items), *story)
+		if items == nil {
+			log.Fatalf("%s is not among the stored articles matching the filters", *story)
+		}
+		name = *story
+	}
+	if err := coverage.Render(os.Stdout, coverage.Analyze(name, items), *format); err != nil {
+		log.Fatalf("Error writing report: %v", err)
+	}
+}
+
+// storyCluster returns the articles grouped with url in d, or nil if url is not in d. An
+// article that no other shares a topic with is its own story.
+func storyCluster(d digest.Digest, url string) []digest.Item {
+	for _, t := range d.Topics {
+		for _, it := range t.Items {
+			if it.URL != url {
+				continue
+			}
+			if t.Label == digest.OtherLabel {
+				return []digest.Item{it}
+			}
+			return t.Items
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"    // For the digest command's own flags
+	"fmt"     // For usage output
+	"log"     // For reporting errors
+	"os"      // For writing the digest to stdout
+	"strings" // For splitting the recipient list
+
+	"github.com/hail2skins/zero-scraper/internal/digest" // Grouping, summarizing, and rendering digests.
+)
+
+// runDigest implements "zero-scraper digest": it gathers the articles fetched in a time
+// range from -html-dir stores, optionally filtered by site or keyword, groups them by
+// topic with a summary each, and writes the digest as Markdown, HTML, or an email.
+func runDigest(args []string) {
+	fs := flag.NewFlagSet("digest", flag.ExitOnError)
+	var filter archiveFilter
+	filter.addFlags(fs)
+	title := fs.String("title", "News digest", "Digest title, also the email subject")
+	format := fs.String("format", digest.Markdown, "Output format: markdown, html, or email")
+	mailFrom := fs.String("mail-from", "", "From address for -format email")
+	mailTo := fs.String("mail-to", "", "Comma-separated recipients for -format email")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: zero-scraper digest [flags] html-dir...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if *format != digest.Markdown && *format != digest.HTML && *format != digest.Email {
+		log.Fatalf("Invalid -format %q: want markdown, html, or email", *format)
+	}
+
+	from, to, items := filter.load(fs.Args())
+	d := digest.Build(*title, from, to, items)
+	header := digest.EmailHeader{From: *mailFrom}
+	if *mailTo != "" {
+		header.To = strings.Split(*mailTo, ",")
+	}
+	if err := digest.Render(os.Stdout, d, *format, header); err != nil {
+		log.Fatalf("Error writing digest: %v", err)
+	}
+}
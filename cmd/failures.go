@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"           // For the failures command's own flags
+	"fmt"            // For usage output
+	"log"            // For reporting errors
+	"os"             // For writing the report to stdout
+	"text/tabwriter" // For aligning the report columns
+	"time"           // For formatting retry times
+
+	"github.com/hail2skins/zero-scraper/internal/retry" // Persistent retry queue for failed URLs.
+)
+
+// runFailures implements "zero-scraper failures": it lists dead-lettered URLs (or, with
+// -pending, URLs still waiting for a retry) from a retry directory for manual review.
+func runFailures(args []string) {
+	fs := flag.NewFlagSet("failures", flag.ExitOnError)
+	pending := fs.Bool("pending", false, "List URLs still waiting for a retry instead of dead-lettered ones")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: zero-scraper failures [-pending] retry-dir")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	// The limits only matter when recording failures, so any values will do here.
+	q, err := retry.Open(fs.Arg(0), 1, time.Minute)
+	if err != nil {
+		log.Fatalf("Error opening retry queue: %v", err)
+	}
+
+	var entries []retry.Entry
+	if *pending {
+		entries = q.Pending()
+	} else if entries, err = q.Dead(); err != nil {
+		log.Fatalf("Error reading dead letters: %v", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "URL\tATTEMPTS\tCLASS\tFIRST FAILED\tNEXT ATTEMPT\tLAST ERROR")
+	for _, e := range entries {
+		next := "-"
+		if !e.NextAttempt.IsZero() {
+			next = e.NextAttempt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\n",
+			e.URL, e.Attempts, e.ErrorClass, e.FirstFailed.Format(time.RFC3339), next, e.LastError)
+	}
+	w.Flush()
+}
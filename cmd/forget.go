@@ -0,0 +1,129 @@
+package main
+
+import (
+	"flag"    // For the forget command's own flags
+	"fmt"     // For usage output
+	"log"     // For reporting what was removed
+	"os"      // For the usage exit status
+	"strings" // For splitting the store list
+	"time"    // For the tombstones' dates
+
+	"github.com/hail2skins/zero-scraper/internal/dedup"     // Duplicate index entries to drop.
+	"github.com/hail2skins/zero-scraper/internal/llm"       // Cached model replies to delete.
+	"github.com/hail2skins/zero-scraper/internal/retry"     // Retry queue entries to drop.
+	"github.com/hail2skins/zero-scraper/internal/store"     // Stored pages to delete.
+	"github.com/hail2skins/zero-scraper/internal/tombstone" // Tombstones that keep forgotten pages from being scraped again.
+	"github.com/hail2skins/zero-scraper/pkg/scraper"        // Cached pages to delete.
+)
+
+// runForget implements "zero-scraper forget": it removes everything kept about the
+// given URLs or sites, for honoring removal requests, and buries each under a
+// tombstone so that the scraper never fetches it again.
+func runForget(args []string) {
+	fs := flag.NewFlagSet("forget", flag.ExitOnError)
+	htmlDirs := fs.String("html-dir", "", "Comma-separated -html-dir stores to delete the pages from")
+	cacheDir := fs.String("cache-dir", "", "Page cache to delete the pages from, as for -cache-dir; forgetting a site empties it, as the cache cannot tell sites apart")
+	llmCacheDir := fs.String("llm-cache-dir", "", "LLM reply cache to delete the replies from, as for -llm-cache-dir")
+	dedupState := fs.String("dedup-state", "dedup.json", "Duplicate index to drop the entries from, as for -dedup-state (skipped if missing)")
+	retryDir := fs.String("retry-dir", "", "Retry queue to drop the entries from, dead letters included, as for -retry-dir")
+	tombstones := fs.String("tombstones", "tombstones.json", "File of tombstones to add to, as for -tombstones")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: zero-scraper forget [flags] url|domain...")
+		fmt.Fprintln(fs.Output(), "A domain covers its subdomains too.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	targets := fs.Args()
+	match := func(rawURL string) bool {
+		for _, t := range targets {
+			if tombstone.Matches(t, rawURL) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// The tombstones go first, so a scraper running meanwhile, which reads the file again
+	// whenever it changes, cannot bring back what is being removed.
+	list, err := tombstone.Open(*tombstones)
+	if err != nil {
+		log.Fatalf("Error opening tombstones: %v", err)
+	}
+	for _, t := range targets {
+		if err := list.Bury(t, time.Now()); err != nil {
+			log.Fatalf("Error burying %s: %v", t, err)
+		}
+	}
+
+	if *htmlDirs != "" {
+		for _, dir := range strings.Split(*htmlDirs, ",") {
+			st, err := openStore(dir)
+			if err != nil {
+				log.Fatalf("Error opening HTML store: %v", err)
+			}
+			n, err := st.Purge(func(m store.Meta) bool { return match(m.URL) })
+			if err != nil {
+				log.Fatalf("Error deleting pages from %s: %v", dir, err)
+			}
+			log.Printf("Deleted %d pages from %s", n, dir)
+		}
+	}
+	if *cacheDir != "" {
+		var site bool
+		for _, t := range targets {
+			if _, ok := tombstone.Target(t); ok {
+				site = true
+			}
+		}
+		var n int
+		if site {
+			n, err = scraper.ClearCache(*cacheDir)
+		} else {
+			for _, t := range targets {
+				var removed bool
+				if removed, err = scraper.RemoveCached(*cacheDir, t); err != nil {
+					break
+				}
+				if removed {
+					n++
+				}
+			}
+		}
+		if err != nil {
+			log.Fatalf("Error deleting cached pages from %s: %v", *cacheDir, err)
+		}
+		log.Printf("Deleted %d cached pages from %s", n, *cacheDir)
+	}
+	if *llmCacheDir != "" {
+		n, err := llm.ForgetCached(*llmCacheDir, match)
+		if err != nil {
+			log.Fatalf("Error deleting cached LLM replies from %s: %v", *llmCacheDir, err)
+		}
+		log.Printf("Deleted %d cached LLM replies from %s", n, *llmCacheDir)
+	}
+	idx, err := dedup.Open(*dedupState, nil)
+	if err != nil {
+		log.Fatalf("Error opening duplicate index: %v", err)
+	}
+	n, err := idx.Forget(match)
+	if err != nil {
+		log.Fatalf("Error updating duplicate index: %v", err)
+	}
+	log.Printf("Dropped %d duplicate index entries", n)
+	if *retryDir != "" {
+		q, err := retry.Open(*retryDir, 1, 0)
+		if err != nil {
+			log.Fatalf("Error opening retry queue: %v", err)
+		}
+		n, err := q.Forget(match)
+		if err != nil {
+			log.Fatalf("Error updating retry queue: %v", err)
+		}
+		log.Printf("Dropped %d retry queue entries", n)
+	}
+	log.Printf("Buried %d tombstones in %s", len(targets), *tombstones)
+}
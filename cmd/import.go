@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag" // For the import command's own flags
+	"fmt"  // For printing the imported URLs
+	"log"  // For reporting errors
+
+	"github.com/hail2skins/zero-scraper/internal/importer" // Reading-list parsers and URL normalization.
+)
+
+// runImport implements "zero-scraper import": it reads reading-list exports, normalizes
+// and de-duplicates their URLs, and prints only the ones not imported before.
+// The output is one URL per line, ready to be POSTed to the serve-mode /urls endpoint.
+func runImport(args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	seenPath := fs.String("seen", "imported.txt", "File listing URLs imported by earlier runs; updated after this run")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: zero-scraper import [-seen file] export-file...")
+		fmt.Fprintln(fs.Output(), "Accepts browser bookmark exports, Pocket exports (HTML or CSV), CSV files, and plain URL lists.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		log.Fatal("Please provide at least one file to import")
+	}
+
+	seen, err := importer.LoadSeen(*seenPath)
+	if err != nil {
+		log.Fatalf("Error reading %s: %v", *seenPath, err)
+	}
+
+	// Dedupe across every file given on this run as well as earlier runs.
+	var fresh []string
+	for _, path := range fs.Args() {
+		urls, err := importer.ReadFile(path)
+		if err != nil {
+			log.Fatalf("Error reading %s: %v", path, err)
+		}
+		fresh = append(fresh, importer.Dedupe(urls, seen)...)
+	}
+
+	for _, u := range fresh {
+		fmt.Println(u)
+	}
+	log.Printf("Imported %d new URLs", len(fresh))
+
+	if err := importer.SaveSeen(*seenPath, seen); err != nil {
+		log.Fatalf("Error writing %s: %v", *seenPath, err)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"           // For the paywalls command's own flags
+	"fmt"            // For printing the report
+	"log"            // For reporting errors
+	"os"             // For writing the report to stdout
+	"text/tabwriter" // For aligning the report columns
+
+	"github.com/hail2skins/zero-scraper/internal/paywall" // Paywall rates by site.
+)
+
+// runPaywalls implements "zero-scraper paywalls": it prints the paywall rate of every
+// site in a -paywall-state file, and whether the given thresholds flag it.
+func runPaywalls(args []string) {
+	fs := flag.NewFlagSet("paywalls", flag.ExitOnError)
+	threshold := fs.Float64("threshold", 0.8, "Paywall rate, from 0 to 1, at or above which a site is flagged, as for -paywall-threshold")
+	minSamples := fs.Int("min-samples", 5, "Articles a site needs before it can be flagged, as for -paywall-min-samples")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: zero-scraper paywalls [flags] paywall-state-file")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	policy := paywall.Policy{Action: paywall.ActionReport, Threshold: *threshold, MinSamples: *minSamples}
+	t, err := paywall.Open(fs.Arg(0), policy)
+	if err != nil {
+		log.Fatalf("Error opening paywall state: %v", err)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "DOMAIN\tSCRAPED\tPAYWALLED\tRECENT RATE\tFLAGGED\tLAST CHECKED")
+	for _, s := range t.Sites() {
+		flagged := "no"
+		if policy.Flagged(s) {
+			flagged = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.0f%% of %d\t%s\t%s\n",
+			s.Domain, s.Scraped, s.Paywalled, 100*s.Rate(), len(s.Recent), flagged, s.LastChecked.Format("2006-01-02 15:04"))
+	}
+	w.Flush()
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"  // For fetching imported profiles
+	"errors"   // For recognizing a missing profiles file
+	"flag"     // For the profile command's own flags
+	"fmt"      // For usage output
+	"log"      // For reporting errors
+	"net/http" // For the headers and cookies profiles send
+	"net/url"  // For the address profile cookies are set for
+	"os"       // For writing exported profiles to stdout
+	"strings"  // For listing conflicting domains
+	"time"     // For the import timeout
+
+	"github.com/hail2skins/zero-scraper/internal/profile" // Per-site scrape profiles.
+	"github.com/hail2skins/zero-scraper/pkg/scraper"      // The scraping library this command wraps.
+)
+
+// profileOptions returns the scraper options for the selectors, headers, and signing of
+// profiles, and sets their cookies in jar.
+func profileOptions(profiles []profile.Profile, jar http.CookieJar) []scraper.Option {
+	opts := profileExtractors(profiles)
+	for _, p := range profiles {
+		if len(p.Headers) > 0 {
+			h := make(http.Header, len(p.Headers))
+			for k, v := range p.Headers {
+				h.Set(k, v)
+			}
+			opts = append(opts, scraper.WithSiteHeaders(p.Domain, h))
+		}
+		if p.Signing != nil {
+			if sg, err := profileSigner(*p.Signing); err != nil {
+				// The site's API will refuse unsigned requests, but the other sites need not fail.
+				log.Printf("Not signing requests to %s: %v", p.Domain, err)
+			} else {
+				opts = append(opts, scraper.WithSiteSigner(p.Domain, sg))
+			}
+		}
+		if len(p.Cookies) > 0 {
+			// A domain cookie, so the site's subdomains are sent it too.
+			cookies := make([]*http.Cookie, 0, len(p.Cookies))
+			for name, value := range p.Cookies {
+				cookies = append(cookies, &http.Cookie{Name: name, Value: value, Domain: p.Domain, Path: "/"})
+			}
+			jar.SetCookies(&url.URL{Scheme: "https", Host: p.Domain, Path: "/"}, cookies)
+		}
+	}
+	return opts
+}
+
+// profileExtractors returns the scraper options for the selectors of profiles.
+func profileExtractors(profiles []profile.Profile) []scraper.Option {
+	var opts []scraper.Option
+	for _, p := range profiles {
+		if sel := p.Selectors; sel != nil {
+			opts = append(opts, scraper.WithSiteExtractor(p.Domain, scraper.SelectorExtractor{Content: sel.Content, Byline: sel.Byline, BylineNames: sel.BylineNames, Title: sel.Title, Published: sel.Date}))
+		}
+	}
+	return opts
+}
+
+// profileSigner returns the signer of a profile's signing.
+func profileSigner(s profile.Signing) (scraper.Signer, error) {
+	s, err := s.Resolve()
+	if err != nil {
+		return nil, err
+	}
+	if s.Scheme == profile.SigningOAuth1 {
+		return scraper.OAuth1Signer{ConsumerKey: s.ConsumerKey, ConsumerSecret: s.ConsumerSecret, Token: s.Token, TokenSecret: s.TokenSecret}, nil
+	}
+	return scraper.HMACSigner{KeyID: s.KeyID, Key: []byte(s.Secret)}, nil
+}
+
+// profileLimits returns a limit rule for each profile with limits, covering its domain
+// and subdomains. They come before any global rule, as the first matching rule applies.
+func profileLimits(profiles []profile.Profile) []scraper.LimitRule {
+	var rules []scraper.LimitRule
+	for _, p := range profiles {
+		if l := p.Limits; l != nil {
+			for _, glob := range []string{p.Domain, "*." + p.Domain} {
+				rules = append(rules, scraper.LimitRule{DomainGlob: glob, Delay: l.Delay, RandomDelay: l.RandomDelay, Parallelism: l.Parallelism})
+			}
+		}
+	}
+	return rules
+}
+
+// profileDomains returns the domains of the profiles for which match is true.
+func profileDomains(profiles []profile.Profile, match func(profile.Profile) bool) []string {
+	var domains []string
+	for _, p := range profiles {
+		if match(p) {
+			domains = append(domains, p.Domain)
+		}
+	}
+	return domains
+}
+
+// runProfile implements "zero-scraper profile": import merges a shared profiles file,
+// local or fetched from a URL such as a community repository, into a -profiles file
+// once it passes the schema; export prints profiles for sharing; schema prints the
+// schema they are checked against.
+func runProfile(args []string) {
+	usage := func() {
+		fmt.Fprintln(os.Stderr, "Usage: zero-scraper profile import [flags] url-or-path...")
+		fmt.Fprintln(os.Stderr, "       zero-scraper profile export [flags] [domain...]")
+		fmt.Fprintln(os.Stderr, "       zero-scraper profile schema")
+	}
+	if len(args) == 0 {
+		usage()
+		os.Exit(2)
+	}
+	switch args[0] {
+	case "import":
+		runProfileImport(args[1:])
+	case "export":
+		runProfileExport(args[1:])
+	case "schema":
+		os.Stdout.Write(profile.Schema())
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+// runProfileImport implements "zero-scraper profile import".
+func runProfileImport(args []string) {
+	fs := flag.NewFlagSet("profile import", flag.ExitOnError)
+	profilesFile := fs.String("profiles", "profiles.json", "Profiles file to import into; created if missing")
+	replace := fs.Bool("replace", false, "Replace the profiles of domains already in -profiles rather than keeping them")
+	timeout := fs.Duration("timeout", 30*time.Second, "Time allowed for fetching each URL")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: zero-scraper profile import [flags] url-or-path...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	profiles, err := profile.Load(*profilesFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Fatalf("Error loading %s: %v", *profilesFile, err)
+	}
+	// Every file is fetched and checked before any is written, so a bad one leaves
+	// -profiles as it was.
+	var imported []profile.Profile
+	client := &http.Client{Timeout: *timeout}
+	for _, location := range fs.Args() {
+		ps, err := profile.Fetch(context.Background(), client, location)
+		if err != nil {
+			log.Fatalf("Error importing %s: %v", location, err)
+		}
+		imported, _ = profile.Merge(imported, ps, true)
+	}
+	merged, conflicts := profile.Merge(profiles, imported, *replace)
+	if err := profile.Save(*profilesFile, merged); err != nil {
+		log.Fatalf("Error writing %s: %v", *profilesFile, err)
+	}
+	if len(conflicts) > 0 {
+		verb := "Kept"
+		if *replace {
+			verb = "Replaced"
+		}
+		log.Printf("%s the existing profiles of %s", verb, strings.Join(conflicts, ", "))
+	}
+	added := len(imported)
+	if !*replace {
+		added -= len(conflicts)
+	}
+	log.Printf("Imported %d profiles into %s", added, *profilesFile)
+}
+
+// runProfileExport implements "zero-scraper profile export".
+func runProfileExport(args []string) {
+	fs := flag.NewFlagSet("profile export", flag.ExitOnError)
+	profilesFile := fs.String("profiles", "profiles.json", "Profiles file to export from")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: zero-scraper profile export [flags] [domain...]")
+		fmt.Fprintln(fs.Output(), "Prints the profiles of the given domains, or of every domain, as a profiles file.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	profiles, err := profile.Load(*profilesFile)
+	if err != nil {
+		log.Fatalf("Error loading %s: %v", *profilesFile, err)
+	}
+	if fs.NArg() > 0 {
+		want := make(map[string]bool, fs.NArg())
+		for _, d := range fs.Args() {
+			want[strings.TrimPrefix(strings.ToLower(d), "www.")] = true
+		}
+		var picked []profile.Profile
+		for _, p := range profiles {
+			if want[p.Domain] {
+				picked = append(picked, p)
+				delete(want, p.Domain)
+			}
+		}
+		for d := range want {
+			log.Fatalf("No profile for %s in %s", d, *profilesFile)
+		}
+		profiles = picked
+	}
+	data, err := profile.Marshal(profiles)
+	if err != nil {
+		log.Fatalf("Error encoding profiles: %v", err)
+	}
+	os.Stdout.Write(data)
+}
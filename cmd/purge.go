@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context" // For stopping the janitor with the run
+	"flag"    // For the purge command's own flags
+	"fmt"     // For usage output
+	"log"     // For reporting what was purged
+	"os"      // For the usage exit status
+	"strings" // For splitting the URL list
+	"time"    // For the janitor's interval
+
+	"github.com/hail2skins/zero-scraper/internal/store" // On-disk store of raw fetched pages.
+)
+
+// janitorInterval is how often long-running modes apply the retention policy.
+const janitorInterval = time.Hour
+
+// retentionFlags are the flags giving a store.Retention, shared by the scraper and the
+// purge command.
+type retentionFlags struct {
+	compressAfter, deleteAfter string
+}
+
+// addFlags registers the retention flags on fs.
+func (f *retentionFlags) addFlags(fs *flag.FlagSet) {
+	fs.StringVar(&f.compressAfter, "retain-compress-after", "", "Age, such as 30d or 12h, at which a stored page's HTML, if still uncompressed, is compressed (never if empty)")
+	fs.StringVar(&f.deleteAfter, "retain-delete-after", "", "Age, such as 90d, at which a stored page's HTML is deleted; its metadata is kept (never if empty)")
+}
+
+// retention returns the policy the flags give.
+func (f *retentionFlags) retention() (store.Retention, error) {
+	var r store.Retention
+	var err error
+	if f.compressAfter != "" {
+		if r.CompressAfter, err = store.ParseAge(f.compressAfter); err != nil {
+			return r, fmt.Errorf("-retain-compress-after: %w", err)
+		}
+	}
+	if f.deleteAfter != "" {
+		if r.DeleteAfter, err = store.ParseAge(f.deleteAfter); err != nil {
+			return r, fmt.Errorf("-retain-delete-after: %w", err)
+		}
+	}
+	return r, nil
+}
+
+// runJanitor applies r to every store now and then every janitorInterval, until ctx is
+// cancelled.
+func runJanitor(ctx context.Context, stores []*store.Store, r store.Retention) {
+	ticker := time.NewTicker(janitorInterval)
+	defer ticker.Stop()
+	for {
+		enforceRetention(stores, r)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// enforceRetention applies r to every store, logging what it did.
+func enforceRetention(stores []*store.Store, r store.Retention) {
+	var compressed, deleted int
+	for _, st := range stores {
+		c, d, err := st.Enforce(r, time.Now())
+		compressed, deleted = compressed+c, deleted+d
+		if err != nil {
+			log.Printf("Error applying retention: %v", err)
+		}
+	}
+	if compressed+deleted > 0 {
+		log.Printf("Retention compressed %d and deleted %d stored pages", compressed, deleted)
+	}
+}
+
+// runPurge implements "zero-scraper purge": it removes the stored pages of the given
+// sites or URLs, metadata and all, for takedowns, and applies a retention policy to
+// what is left.
+func runPurge(args []string) {
+	fs := flag.NewFlagSet("purge", flag.ExitOnError)
+	domains := fs.String("domains", "", "Comma-separated sites, subdomains included, whose stored pages are deleted outright")
+	urls := fs.String("urls", "", "Comma-separated URLs whose stored pages are deleted outright")
+	var rf retentionFlags
+	rf.addFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: zero-scraper purge [flags] html-dir...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	r, err := rf.retention()
+	if err != nil {
+		log.Fatalf("Invalid %v", err)
+	}
+	if fs.NArg() == 0 || (*domains == "" && *urls == "" && r == store.Retention{}) {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	listed := make(map[string]bool)
+	if *urls != "" {
+		for _, u := range strings.Split(*urls, ",") {
+			listed[strings.TrimSpace(u)] = true
+		}
+	}
+	var stores []*store.Store
+	for _, dir := range fs.Args() {
+		st, err := openStore(dir)
+		if err != nil {
+			log.Fatalf("Error opening HTML store: %v", err)
+		}
+		stores = append(stores, st)
+		if *domains == "" && *urls == "" {
+			continue
+		}
+		n, err := st.Purge(func(m store.Meta) bool {
+			return listed[m.URL] || (*domains != "" && matchesDomain(m.URL, *domains))
+		})
+		if err != nil {
+			log.Fatalf("Error purging %s: %v", dir, err)
+		}
+		log.Printf("Purged %d pages from %s", n, dir)
+	}
+	enforceRetention(stores, r)
+}
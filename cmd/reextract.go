@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bufio"         // For reading record files line by line
+	"encoding/json" // For reading and writing records
+	"flag"          // For the reextract command's own flags
+	"fmt"           // For usage output
+	"log"           // For reporting errors
+	"os"            // For reading inputs and writing to stdout
+
+	"github.com/hail2skins/zero-scraper/internal/sink"  // The record format written out.
+	"github.com/hail2skins/zero-scraper/internal/store" // On-disk store of raw fetched pages.
+	"github.com/hail2skins/zero-scraper/pkg/scraper"    // The extractors being re-run.
+)
+
+// runReextract implements "zero-scraper reextract": it re-runs the current extractors over
+// stored HTML and writes the new records to stdout as JSON Lines, without fetching anything.
+// Each input is either an -html-dir store or a JSON Lines file of records saved with
+// -include-html. The extractors are configured as for a live scrape, from the same
+// -extractors, -sites-dir, -profiles, and -byline-locales settings.
+func runReextract(args []string) {
+	fs := flag.NewFlagSet("reextract", flag.ExitOnError)
+	includeHTML := fs.Bool("include-html", false, "Keep the raw HTML in the records written out")
+	extractors := fs.String("extractors", "selectors", "Extractors to try in order for each field, as for -extractors")
+	sitesDir := fs.String("sites-dir", "sites.d", "Directory of per-domain selector files, as for -sites-dir (skipped if the default is missing)")
+	profilesFile := fs.String("profiles", "", "Profiles file whose selectors and byline locales to use, as for -profiles")
+	bylineLocales := fs.String("byline-locales", "", "Per-domain byline and date languages, as for -byline-locales")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: zero-scraper reextract [flags] html-dir|records.jsonl...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	extractor, err := scraper.ParseChain(*extractors)
+	if err != nil {
+		log.Fatalf("Error in -extractors: %v", err)
+	}
+	var flaggedLocales map[string]string
+	if *bylineLocales != "" {
+		if flaggedLocales, err = parseBylineLocales(*bylineLocales); err != nil {
+			log.Fatalf("Invalid -byline-locales: %v", err)
+		}
+	}
+	config, err := loadSiteConfig(*profilesFile, *sitesDir)
+	if err != nil {
+		log.Fatalf("Error loading site configuration: %v", err)
+	}
+	config.applyLocales(flaggedLocales)
+	// Profile selectors come after, so a profile overrides a site file for its domain,
+	// as in a live scrape.
+	opts := append([]scraper.Option{scraper.WithExtractor(extractor)}, config.siteOptions()...)
+	s := scraper.New(append(opts, profileExtractors(config.profiles)...)...)
+	enc := json.NewEncoder(os.Stdout)
+	var done, failed int
+	emit := func(url, html string) error {
+		a, err := s.ScrapeHTML(url, html)
+		if err != nil {
+			// One bad page should not stop a re-extraction of a whole crawl.
+			log.Printf("Error re-extracting %s: %v", url, err)
+			failed++
+			return nil
+		}
+		record := articleRecord(url, a)
+		if *includeHTML {
+			record.HTML = html
+		}
+		if err := sink.Validate(record); err != nil {
+			log.Printf("Error re-extracting %s: %v", url, err)
+			failed++
+			return nil
+		}
+		done++
+		return enc.Encode(record)
+	}
+
+	for _, path := range fs.Args() {
+		if err := eachSavedPage(path, emit); err != nil {
+			log.Fatalf("Error reading %s: %v", path, err)
+		}
+	}
+	log.Printf("Re-extracted %d pages (%d failed)", done, failed)
+}
+
+// eachSavedPage calls emit for every page saved at path: an -html-dir store or a JSON
+// Lines file of records saved with -include-html.
+func eachSavedPage(path string, emit func(url, html string) error) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return reextractRecords(path, emit)
+	}
+	st, err := openStore(path)
+	if err != nil {
+		return err
+	}
+	return st.Each(func(p store.Page) error {
+		if p.HTML == "" {
+			log.Printf("Skipping %s: retention has deleted its HTML", p.URL)
+			return nil
+		}
+		return emit(p.URL, p.HTML)
+	})
+}
+
+// reextractRecords calls emit for every record in a JSON Lines file that carries its HTML.
+func reextractRecords(path string, emit func(url, html string) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	// Records with embedded HTML are long lines.
+	sc.Buffer(make([]byte, 0, 1<<20), 64<<20)
+	for n := 1; sc.Scan(); n++ {
+		var record sink.Article
+		if err := json.Unmarshal(sc.Bytes(), &record); err != nil {
+			return fmt.Errorf("line %d: %w", n, err)
+		}
+		if record.HTML == "" {
+			log.Printf("Skipping %s: the record has no HTML (was it saved with -include-html?)", record.URL)
+			continue
+		}
+		if err := emit(record.URL, record.HTML); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"       // For stopping the watch with the run
+	"errors"        // For recognizing a missing sites directory
+	"fmt"           // For building the change stamp
+	"io/fs"         // For recognizing a missing sites directory
+	"log"           // For reporting reloads
+	"os"            // For checking the files for changes
+	"path/filepath" // For the files in the sites directory
+	"strings"       // For building the change stamp
+	"time"          // For the polling interval
+
+	"github.com/hail2skins/zero-scraper/internal/byline"     // Per-domain byline and date languages.
+	"github.com/hail2skins/zero-scraper/internal/profile"    // Per-site scrape profiles.
+	"github.com/hail2skins/zero-scraper/internal/siteconfig" // Selector rules per site from YAML files.
+	"github.com/hail2skins/zero-scraper/pkg/scraper"         // The scraping library this command wraps.
+)
+
+// siteConfig is the configuration read from -profiles and -sites-dir, which worker and
+// serve modes reload as the files change.
+type siteConfig struct {
+	profiles []profile.Profile
+	sites    []siteconfig.Site
+}
+
+// loadSiteConfig reads the profiles file, if one is given, and the sites directory,
+// which may be missing when it is the default.
+func loadSiteConfig(profilesFile, sitesDir string) (siteConfig, error) {
+	var config siteConfig
+	if profilesFile != "" {
+		profiles, err := profile.Load(profilesFile)
+		if err != nil {
+			return siteConfig{}, fmt.Errorf("-profiles: %w", err)
+		}
+		config.profiles = profiles
+	}
+	sites, err := siteconfig.Load(sitesDir)
+	switch {
+	case err == nil:
+		config.sites = sites
+		log.Printf("Loaded selectors for %d sites from %s", len(sites), sitesDir)
+	case sitesDir != "sites.d" || !errors.Is(err, fs.ErrNotExist):
+		return siteConfig{}, fmt.Errorf("-sites-dir: %w", err)
+	}
+	return config, nil
+}
+
+// siteOptions returns the scraper options for the selectors of the site files.
+func (c siteConfig) siteOptions() []scraper.Option {
+	var opts []scraper.Option
+	for _, site := range c.sites {
+		for _, domain := range site.Domains {
+			opts = append(opts, scraper.WithSiteExtractor(domain, site.Extractor()))
+		}
+	}
+	return opts
+}
+
+// applyLocales sets the byline and date language of every domain whose profile gives
+// one, or that flagged, from -byline-locales, names; flagged takes precedence.
+func (c siteConfig) applyLocales(flagged map[string]string) {
+	locales := make(map[string]string)
+	for _, p := range c.profiles {
+		if p.BylineLocale != "" {
+			locales[p.Domain] = p.BylineLocale
+		}
+	}
+	for domain, lang := range flagged {
+		locales[domain] = lang
+	}
+	byline.SetDomainLocales(locales)
+}
+
+// watchConfig checks the profiles file and the sites directory every interval until ctx
+// is done, and calls apply with the configuration whenever either has changed. A
+// configuration that fails to load is reported and the one in use kept, so that a
+// half-saved edit cannot take the scraper down.
+func watchConfig(ctx context.Context, interval time.Duration, profilesFile, sitesDir string, apply func(siteConfig)) {
+	last := configStamp(profilesFile, sitesDir)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		stamp := configStamp(profilesFile, sitesDir)
+		if stamp == last {
+			continue
+		}
+		last = stamp
+		config, err := loadSiteConfig(profilesFile, sitesDir)
+		if err != nil {
+			log.Printf("Error reloading site configuration, keeping the current one: %v", err)
+			continue
+		}
+		apply(config)
+		log.Printf("Reloaded %d profiles", len(config.profiles))
+	}
+}
+
+// configStamp describes the size and modification time of the profiles file and of
+// every site file, so that any edit, addition, or removal changes it.
+func configStamp(profilesFile, sitesDir string) string {
+	var b strings.Builder
+	stamp := func(path string) {
+		if info, err := os.Stat(path); err == nil {
+			fmt.Fprintf(&b, "%s %d %d\n", path, info.Size(), info.ModTime().UnixNano())
+		}
+	}
+	if profilesFile != "" {
+		stamp(profilesFile)
+	}
+	entries, _ := os.ReadDir(sitesDir)
+	for _, e := range entries {
+		stamp(filepath.Join(sitesDir, e.Name()))
+	}
+	return b.String()
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"   // For holding back each scrape's console output
+	"context" // For cancelling the retry loop
+	"log"     // For reporting retry-queue errors
+	"sync"    // For serializing console output between concurrent scrapes
+	"time"    // For the retry polling interval
+
+	"github.com/hail2skins/zero-scraper/internal/retry"  // Persistent retry queue for failed URLs.
+	"github.com/hail2skins/zero-scraper/internal/sink"   // Destinations that scraped articles can be published to.
+	"github.com/hail2skins/zero-scraper/internal/source" // Inputs that feed URLs to the scraper.
+)
+
+// retryPollInterval is how often long-running modes look for retries that have come due.
+const retryPollInterval = 30 * time.Second
+
+// retryingHandler wraps the pipeline's scrapeAndOutput so that failures are recorded in the retry queue
+// and successes clear any earlier failure. With a nil queue it only scrapes.
+// Calls may run concurrently, as the retry loop and serve mode's workers do; each one's
+// console output is written in one piece once it is done.
+func retryingHandler(p *pipeline, q *retry.Queue) source.Handler {
+	var mu sync.Mutex
+	return func(ctx context.Context, req source.Request) (sink.Article, error) {
+		var out bytes.Buffer
+		up := *p
+		up.out = &out
+		article, err := up.scrapeAndOutput(ctx, req)
+		mu.Lock()
+		p.out.Write(out.Bytes())
+		mu.Unlock()
+		if q == nil {
+			return article, err
+		}
+		if err != nil {
+			if qerr := q.Fail(req.Tenant, req.URL, err); qerr != nil {
+				log.Printf("Error recording failure for %s: %v", req.URL, qerr)
+			}
+		} else if qerr := q.Succeed(req.Tenant, req.URL); qerr != nil {
+			log.Printf("Error clearing retry for %s: %v", req.URL, qerr)
+		}
+		return article, err
+	}
+}
+
+// runRetries re-attempts due URLs from the retry queue until ctx is cancelled.
+func runRetries(ctx context.Context, q *retry.Queue, handle source.Handler) {
+	ticker := time.NewTicker(retryPollInterval)
+	defer ticker.Stop()
+	for {
+		for _, e := range q.Due(time.Now()) {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("Retrying %s", e.URL)
+			// The handler records the outcome; the error was already logged there.
+			handle(ctx, source.Request{URL: e.URL, Tenant: e.Tenant})
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
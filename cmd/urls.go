@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bufio"   // For reading URL lists line by line
+	"io"      // For reading URL lists from any reader
+	"os"      // For opening URL list files
+	"strings" // For trimming lines and joining flag values
+)
+
+// urlList collects the values of a repeatable flag.
+type urlList []string
+
+// String implements flag.Value.
+func (l *urlList) String() string { return strings.Join(*l, ",") }
+
+// Set implements flag.Value.
+func (l *urlList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// readURLsFile returns the URLs listed in the file at path, as read by scanURLs.
+func readURLsFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var urls []string
+	err = scanURLs(f, func(u string) bool {
+		urls = append(urls, u)
+		return true
+	})
+	return urls, err
+}
+
+// scanURLs calls fn for every URL in r, one per line, as each line arrives, until fn
+// returns false. Surrounding space is trimmed, and blank lines and lines starting with #
+// are skipped, so lists can be annotated.
+func scanURLs(r io.Reader, fn func(url string) bool) error {
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !fn(line) {
+			break
+		}
+	}
+	return sc.Err()
+}
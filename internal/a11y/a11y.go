@@ -0,0 +1,51 @@
+// Package a11y renders articles as plain, linear text for screen readers and refreshable
+// Braille displays. Structure that sighted readers get from layout is announced in words
+// instead: the headline says its heading level, and the start and end of the article are
+// stated explicitly. Decoration is left out entirely.
+package a11y
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Render produces the accessible text profile for an extracted article: the title as
+// its first heading, then the byline, then content, one paragraph per line. Only what
+// extraction kept is read, so the page's navigation, footers, and related links are not.
+//
+// The output deliberately contains no bidirectional control characters or box-drawing
+// separators, which Braille displays show as stray cells.
+func Render(title, byline, content string) string {
+	var b strings.Builder
+	if title = clean(title); title != "" {
+		fmt.Fprintf(&b, "Heading level 1: %s.\n", title)
+	}
+	if byline = clean(byline); byline != "" {
+		fmt.Fprintf(&b, "Byline: %s.\n", byline)
+	}
+	b.WriteString("Start of article text.\n\n")
+	for _, p := range strings.Split(content, "\n") {
+		if p = clean(p); !decorative(p) {
+			fmt.Fprintf(&b, "%s\n\n", p)
+		}
+	}
+	b.WriteString("End of article.")
+	return b.String()
+}
+
+// clean collapses white space, including the line breaks left over from page layout.
+func clean(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// decorative reports whether text contains no letters or digits, such as the "* * *" or
+// "———" separators some sites place between sections.
+func decorative(text string) bool {
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
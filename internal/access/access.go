@@ -0,0 +1,118 @@
+// Package access gives the API keys of serve mode roles, so that a key handed to a
+// dashboard can read the feed without being able to queue scrapes, and only an admin
+// key can see the server's internals.
+//
+// The keys file is a JSON object keyed by the name of each key's holder:
+//
+//	{
+//	  "dashboard": {"key": "9d2e...", "role": "read"},
+//	  "ingest":    {"key": "5a0c...", "role": "submit"},
+//	  "ops":       {"key": "e81f...", "role": "admin"}
+//	}
+//
+// Each role may do everything the ones before it may.
+package access
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Role is what an API key may do.
+type Role int
+
+// Roles, each including the ones before it.
+const (
+	// None is the role of a missing or unknown key.
+	None Role = iota
+	// Read may read what the server publishes, such as the feed.
+	Read
+	// Submit may also queue URLs to scrape.
+	Submit
+	// Admin may also see the server's internals, such as its metrics.
+	Admin
+)
+
+// roleNames are the roles by the names used in keys files.
+var roleNames = map[string]Role{"read": Read, "submit": Submit, "admin": Admin}
+
+// ParseRole returns the role called name: read, submit, or admin.
+func ParseRole(name string) (Role, error) {
+	if r, ok := roleNames[name]; ok {
+		return r, nil
+	}
+	return None, fmt.Errorf("unknown role %q: want read, submit, or admin", name)
+}
+
+// String returns the role's name.
+func (r Role) String() string {
+	for name, role := range roleNames {
+		if role == r {
+			return name
+		}
+	}
+	return "none"
+}
+
+// UnmarshalJSON reads a role by name.
+func (r *Role) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	role, err := ParseRole(name)
+	if err != nil {
+		return err
+	}
+	*r = role
+	return nil
+}
+
+// Keys look up the roles of API keys.
+type Keys interface {
+	// Role returns the role of key, or None if key is unknown.
+	Role(key string) Role
+}
+
+// KeyFile is the keys of a keys file. It implements Keys.
+type KeyFile map[string]Role
+
+// Role implements Keys.
+func (k KeyFile) Role(key string) Role {
+	if key == "" {
+		return None
+	}
+	return k[key]
+}
+
+// Load reads a keys file.
+func Load(path string) (KeyFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("access: %w", err)
+	}
+	var file map[string]struct {
+		Key  string `json:"key"`
+		Role Role   `json:"role"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("access: reading %s: %w", path, err)
+	}
+	keys := make(KeyFile, len(file))
+	holders := make(map[string]string, len(file)) // holders maps keys to their holders' names.
+	for name, k := range file {
+		if k.Key == "" {
+			return nil, fmt.Errorf("access: %s: %s has no key", path, name)
+		}
+		if k.Role == None {
+			return nil, fmt.Errorf("access: %s: %s has no role", path, name)
+		}
+		if other, dup := holders[k.Key]; dup {
+			return nil, fmt.Errorf("access: %s: %s and %s share a key", path, other, name)
+		}
+		holders[k.Key] = name
+		keys[k.Key] = k.Role
+	}
+	return keys, nil
+}
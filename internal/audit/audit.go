@@ -0,0 +1,136 @@
+// Package audit writes an append-only JSON Lines log of every outbound request the
+// scraper makes and every policy decision it takes, for compliance review.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Event kinds recorded in the log.
+const (
+	// EventRequest is an outbound HTTP request, including each hop of a redirect.
+	EventRequest = "request"
+	// EventPolicy is a decision about what to do with a fetched page.
+	EventPolicy = "policy"
+)
+
+// Entry is one line of the audit log.
+type Entry struct {
+	Time   time.Time `json:"time"`
+	Event  string    `json:"event"`
+	URL    string    `json:"url"`
+	Method string    `json:"method,omitempty"`
+	Status int       `json:"status,omitempty"`
+	Bytes  int64     `json:"bytes,omitempty"`
+	// Duration is how long the request took, in milliseconds, until its body was closed.
+	Duration int64  `json:"duration_ms,omitempty"`
+	Error    string `json:"error,omitempty"`
+	// Policy names the policy that made a decision (e.g. "robots-meta") and Decision its outcome.
+	Policy   string `json:"policy,omitempty"`
+	Decision string `json:"decision,omitempty"`
+}
+
+// Log is an audit log file. It is safe for concurrent use.
+type Log struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+// Open opens path for appending, creating it if needed. Existing entries are never rewritten.
+func Open(path string) (*Log, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("audit: %w", err)
+	}
+	return &Log{f: f}, nil
+}
+
+// Record appends an entry, stamping it with the current time if it has none.
+func (l *Log) Record(e Entry) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+	return nil
+}
+
+// Close flushes and closes the log file.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.f.Sync(); err != nil {
+		l.f.Close()
+		return err
+	}
+	return l.f.Close()
+}
+
+// Transport wraps next so every request it carries is recorded in l. Response sizes are
+// counted as the body is read, and the entry is written when the body is closed.
+func Transport(next http.RoundTripper, l *Log) http.RoundTripper {
+	return &transport{next: next, log: l}
+}
+
+// transport is the auditing http.RoundTripper returned by Transport.
+type transport struct {
+	next http.RoundTripper
+	log  *Log
+}
+
+// RoundTrip performs the request and arranges for it to be recorded.
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	entry := Entry{Time: start.UTC(), Event: EventRequest, URL: req.URL.String(), Method: req.Method}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		entry.Error = err.Error()
+		entry.Duration = time.Since(start).Milliseconds()
+		t.log.Record(entry)
+		return nil, err
+	}
+
+	entry.Status = resp.StatusCode
+	resp.Body = &countingBody{ReadCloser: resp.Body, done: func(n int64) {
+		entry.Bytes = n
+		entry.Duration = time.Since(start).Milliseconds()
+		t.log.Record(entry)
+	}}
+	return resp, nil
+}
+
+// countingBody counts the bytes read from a response body and reports them once on Close.
+type countingBody struct {
+	io.ReadCloser
+	n    int64
+	once sync.Once
+	done func(n int64)
+}
+
+// Read reads from the underlying body, counting bytes.
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	return n, err
+}
+
+// Close closes the underlying body and records the entry.
+func (b *countingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.done(b.n) })
+	return err
+}
@@ -0,0 +1,284 @@
+// Package auth signs the scraper in to the sites it holds subscriptions to, so that it
+// is sent the articles a subscriber would be. A site authenticates with HTTP basic auth,
+// a bearer token, or a login form posted once before scraping, whose session cookie the
+// scraper then sends like a browser would.
+//
+// The auth file is a JSON object keyed by site; credentials given as "env:NAME" are read
+// from the environment variable NAME, so that they need not be written down:
+//
+//	{
+//	  "example.com": {"basic": {"username": "me", "password": "env:EXAMPLE_PASSWORD"}},
+//	  "news.example": {"bearer": "env:NEWS_TOKEN"},
+//	  "paper.example": {"form": {
+//	    "url": "https://paper.example/login",
+//	    "fields": {"email": "me@example.org", "password": "env:PAPER_PASSWORD"},
+//	    "csrf_field": "authenticity_token",
+//	    "session_cookie": "_paper_session"
+//	  }}
+//	}
+//
+// Credentials are only ever sent to their site and its subdomains.
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Site is how the scraper authenticates with one site. Basic and Bearer are exclusive.
+type Site struct {
+	// Domain is the site, subdomains included, e.g. "example.com".
+	Domain string `json:"-"`
+	// Basic sends a username and password with every request.
+	Basic *Basic `json:"basic,omitempty"`
+	// Bearer sends "Authorization: Bearer token" with every request.
+	Bearer string `json:"bearer,omitempty"`
+	// Form logs in once before scraping.
+	Form *Form `json:"form,omitempty"`
+}
+
+// Basic holds the credentials for HTTP basic auth.
+type Basic struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// Form is a login form.
+type Form struct {
+	// URL is where the form is posted, and read from first for CSRFField.
+	URL string `json:"url"`
+	// Fields are posted form-encoded, e.g. the username and password.
+	Fields map[string]string `json:"fields"`
+	// CSRFField, if set, names a hidden input of the login page whose value is posted
+	// with Fields, for sites that reject a form not read first.
+	CSRFField string `json:"csrf_field,omitempty"`
+	// SessionCookie, if set, is the cookie a successful login sets. A login that does not
+	// set it fails, and none is attempted while the jar already holds it.
+	SessionCookie string `json:"session_cookie,omitempty"`
+}
+
+// Load reads an auth file, sorted by site.
+func Load(path string) ([]Site, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("auth: %w", err)
+	}
+	var file map[string]Site
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("auth: reading %s: %w", path, err)
+	}
+	sites := make([]Site, 0, len(file))
+	for domain, s := range file {
+		s.Domain = normalizeDomain(domain)
+		if err := s.resolve(); err != nil {
+			return nil, fmt.Errorf("auth: %s: %s: %w", path, domain, err)
+		}
+		sites = append(sites, s)
+	}
+	sort.Slice(sites, func(i, j int) bool { return sites[i].Domain < sites[j].Domain })
+	return sites, nil
+}
+
+// resolve checks s and reads its credentials from the environment where asked to.
+func (s *Site) resolve() error {
+	if s.Basic == nil && s.Bearer == "" && s.Form == nil {
+		return errors.New("no basic, bearer, or form")
+	}
+	if s.Basic != nil && s.Bearer != "" {
+		return errors.New("both basic and bearer")
+	}
+	var err error
+	if s.Basic != nil {
+		if s.Basic.Username, err = secret(s.Basic.Username); err != nil {
+			return err
+		}
+		if s.Basic.Password, err = secret(s.Basic.Password); err != nil {
+			return err
+		}
+	}
+	if s.Bearer, err = secret(s.Bearer); err != nil {
+		return err
+	}
+	if s.Form != nil {
+		u, err := url.Parse(s.Form.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("invalid form url %q", s.Form.URL)
+		}
+		if !matches(normalizeDomain(u.Hostname()), s.Domain) {
+			return fmt.Errorf("form url %s is not on the site", s.Form.URL)
+		}
+		for name, value := range s.Form.Fields {
+			if s.Form.Fields[name], err = secret(value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// secret returns v, or the environment variable it names as "env:NAME".
+func secret(v string) (string, error) {
+	name, ok := strings.CutPrefix(v, "env:")
+	if !ok {
+		return v, nil
+	}
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, nil
+}
+
+// Transport returns an http.RoundTripper that sends each request through next with the
+// basic auth or bearer token of the site it is for, unless it already has an
+// Authorization header.
+func Transport(next http.RoundTripper, sites []Site) http.RoundTripper {
+	return &transport{next: next, sites: sites}
+}
+
+// transport is the http.RoundTripper returned by Transport.
+type transport struct {
+	next  http.RoundTripper
+	sites []Site
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	s := siteFor(t.sites, req.URL.Hostname())
+	if s == nil || (s.Basic == nil && s.Bearer == "") || req.Header.Get("Authorization") != "" {
+		return t.next.RoundTrip(req)
+	}
+	// A RoundTripper must not change the request it is given.
+	req = req.Clone(req.Context())
+	if s.Basic != nil {
+		req.SetBasicAuth(s.Basic.Username, s.Basic.Password)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+s.Bearer)
+	}
+	return t.next.RoundTrip(req)
+}
+
+// siteFor returns the most specific of sites that host is on, or nil if none.
+func siteFor(sites []Site, host string) *Site {
+	for host = normalizeDomain(host); host != ""; {
+		for i := range sites {
+			if sites[i].Domain == host {
+				return &sites[i]
+			}
+		}
+		_, parent, ok := strings.Cut(host, ".")
+		if !ok {
+			break
+		}
+		host = parent
+	}
+	return nil
+}
+
+// Login posts s's login form through client, whose cookie jar keeps the session, and
+// reports whether it did: it does not when s has no form, or the jar already holds the
+// session cookie, as when it was loaded from an earlier run.
+func Login(ctx context.Context, client *http.Client, s Site) (bool, error) {
+	f := s.Form
+	if f == nil {
+		return false, nil
+	}
+	// resolve has checked the URL.
+	u, _ := url.Parse(f.URL)
+	if f.SessionCookie != "" && client.Jar != nil && hasCookie(client.Jar, u, f.SessionCookie) {
+		return false, nil
+	}
+	form := make(url.Values, len(f.Fields)+1)
+	for name, value := range f.Fields {
+		form.Set(name, value)
+	}
+	if f.CSRFField != "" {
+		token, err := csrfToken(ctx, client, f.URL, f.CSRFField)
+		if err != nil {
+			return false, fmt.Errorf("auth: %s: %w", s.Domain, err)
+		}
+		form.Set(f.CSRFField, token)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.URL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, fmt.Errorf("auth: %s: %w", s.Domain, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("auth: %s: %w", s.Domain, err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return false, fmt.Errorf("auth: %s: login returned %s", s.Domain, resp.Status)
+	}
+	if f.SessionCookie != "" && (client.Jar == nil || !hasCookie(client.Jar, u, f.SessionCookie)) {
+		return false, fmt.Errorf("auth: %s: login did not set cookie %s; check the credentials", s.Domain, f.SessionCookie)
+	}
+	return true, nil
+}
+
+// csrfToken reads the login page at loginURL and returns the value of its input named
+// field.
+func csrfToken(ctx context.Context, client *http.Client, loginURL, field string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, loginURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return "", fmt.Errorf("login page returned %s", resp.Status)
+	}
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	var token string
+	var found bool
+	doc.Find("input").EachWithBreak(func(_ int, input *goquery.Selection) bool {
+		if name, _ := input.Attr("name"); name == field {
+			token, _ = input.Attr("value")
+			found = true
+		}
+		return !found
+	})
+	if !found {
+		return "", fmt.Errorf("login page has no %s input", field)
+	}
+	return token, nil
+}
+
+// hasCookie reports whether jar sends a cookie called name to u.
+func hasCookie(jar http.CookieJar, u *url.URL, name string) bool {
+	for _, c := range jar.Cookies(u) {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether host is domain or one of its subdomains.
+func matches(host, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// normalizeDomain lowercases a domain and drops a leading "www.".
+func normalizeDomain(domain string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "www.")
+}
@@ -0,0 +1,235 @@
+// Package authors aggregates an archive of articles by author, for following what
+// particular journalists cover: how much, for which outlets, on which topics, and when.
+package authors
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/hail2skins/zero-scraper/internal/digest"
+)
+
+// Formats Render can write.
+const (
+	JSON = "json"
+	RSS  = "rss"
+)
+
+// Count is how many of an author's articles share an outlet or topic.
+type Count struct {
+	Name     string `json:"name"`
+	Articles int    `json:"articles"`
+}
+
+// Article is one article in an author's profile.
+type Article struct {
+	URL       string    `json:"url"`
+	Title     string    `json:"title,omitempty"`
+	Outlet    string    `json:"outlet"`
+	Topic     string    `json:"topic,omitempty"`
+	Published time.Time `json:"published,omitzero"`
+	FetchedAt time.Time `json:"fetched_at"`
+	Summary   string    `json:"summary,omitempty"`
+}
+
+// time returns when the article was published, or fetched if the page does not say.
+func (a Article) time() time.Time {
+	if a.Published.IsZero() {
+		return a.FetchedAt
+	}
+	return a.Published
+}
+
+// Profile is everything the archive holds by one author.
+type Profile struct {
+	Name string `json:"name"`
+	// Slug is the name reduced to lowercase letters, digits, and hyphens, for file names.
+	Slug    string  `json:"slug"`
+	Outlets []Count `json:"outlets"`
+	Topics  []Count `json:"topics"`
+	// FirstSeen and LastSeen are the earliest and latest publication times of the
+	// author's articles, taking the fetch time of an article whose page gives none.
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	// Articles are ordered newest first.
+	Articles []Article `json:"articles"`
+}
+
+// Aggregate builds a profile for every author of the digest's articles, most prolific
+// first. Articles take their topic from the digest; those it could not group with any
+// other have none. Names are matched ignoring case, and each profile is named by the
+// spelling of its author's first article.
+func Aggregate(d digest.Digest) []Profile {
+	type acc struct {
+		profile Profile
+		outlets map[string]int
+		topics  map[string]int
+	}
+	byName := make(map[string]*acc)
+	for _, t := range d.Topics {
+		topic := t.Label
+		if topic == digest.OtherLabel {
+			topic = ""
+		}
+		for _, it := range t.Items {
+			for _, name := range it.Authors {
+				key := strings.ToLower(name)
+				a, ok := byName[key]
+				if !ok {
+					a = &acc{profile: Profile{Name: name, Slug: Slug(name)}, outlets: make(map[string]int), topics: make(map[string]int)}
+					byName[key] = a
+				}
+				a.profile.Articles = append(a.profile.Articles, Article{URL: it.URL, Title: it.Title, Outlet: it.Host(), Topic: topic, Published: it.Published, FetchedAt: it.FetchedAt, Summary: it.Summary})
+				a.outlets[it.Host()]++
+				if topic != "" {
+					a.topics[topic]++
+				}
+			}
+		}
+	}
+
+	profiles := make([]Profile, 0, len(byName))
+	for _, a := range byName {
+		p := a.profile
+		sort.Slice(p.Articles, func(i, j int) bool { return p.Articles[i].time().After(p.Articles[j].time()) })
+		p.LastSeen = p.Articles[0].time()
+		p.FirstSeen = p.Articles[len(p.Articles)-1].time()
+		p.Outlets, p.Topics = counts(a.outlets), counts(a.topics)
+		profiles = append(profiles, p)
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		if len(profiles[i].Articles) != len(profiles[j].Articles) {
+			return len(profiles[i].Articles) > len(profiles[j].Articles)
+		}
+		return profiles[i].Name < profiles[j].Name
+	})
+	return profiles
+}
+
+// counts lists a tally, largest first.
+func counts(m map[string]int) []Count {
+	out := make([]Count, 0, len(m))
+	for name, n := range m {
+		out = append(out, Count{Name: name, Articles: n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Articles != out[j].Articles {
+			return out[i].Articles > out[j].Articles
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// Find returns the profile of the named author, ignoring case, and whether there is one.
+func Find(profiles []Profile, name string) (Profile, bool) {
+	for _, p := range profiles {
+		if strings.EqualFold(p.Name, name) || p.Slug == name {
+			return p, true
+		}
+	}
+	return Profile{}, false
+}
+
+// Slug reduces name to lowercase letters and digits joined by hyphens.
+func Slug(name string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), "-")
+}
+
+// Render writes one profile as indented JSON or as an RSS 2.0 feed of the author's articles.
+func Render(w io.Writer, p Profile, format string) error {
+	switch format {
+	case JSON:
+		return RenderJSON(w, p)
+	case RSS:
+		return renderRSS(w, p)
+	}
+	return fmt.Errorf("authors: unknown format %q (want json or rss)", format)
+}
+
+// RenderJSON writes v, a profile or a list of them, as indented JSON.
+func RenderJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// rssFeed is the root element of an RSS 2.0 document.
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	DC      string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+// rssChannel describes the author's feed.
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+// rssItem is one of the author's articles.
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+	Creator     string  `xml:"dc:creator"`
+	Category    string  `xml:"category,omitempty"`
+	Description string  `xml:"description,omitempty"`
+}
+
+// rssGUID identifies an item by its URL.
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// renderRSS writes the profile's articles as an RSS feed, newest first. RSS requires a
+// channel link, so the channel points at the author's latest article.
+func renderRSS(w io.Writer, p Profile) error {
+	feed := rssFeed{Version: "2.0", DC: "http://purl.org/dc/elements/1.1/", Channel: rssChannel{
+		Title:       "Articles by " + p.Name,
+		Description: "The archived articles of " + p.Name,
+	}}
+	if len(p.Articles) > 0 {
+		feed.Channel.Link = p.Articles[0].URL
+		feed.Channel.LastBuildDate = p.LastSeen.Format(time.RFC1123Z)
+	}
+	for _, a := range p.Articles {
+		title := a.Title
+		if title == "" {
+			title = a.Outlet
+		}
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       title,
+			Link:        a.URL,
+			GUID:        rssGUID{IsPermaLink: true, Value: a.URL},
+			PubDate:     a.time().Format(time.RFC1123Z),
+			Creator:     p.Name,
+			Category:    a.Topic,
+			Description: a.Summary,
+		})
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(feed); err != nil {
+		return fmt.Errorf("authors: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,135 @@
+// Package bench measures extraction performance over a corpus of saved HTML fixtures,
+// so regressions in the extraction paths show up as numbers rather than anecdotes.
+package bench
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hail2skins/zero-scraper/pkg/scraper"
+)
+
+// Extractor runs one extraction strategy over already-fetched HTML.
+type Extractor func(url, html string) error
+
+// selectorScraper runs the scraper's default selector-based extraction.
+var selectorScraper = scraper.New()
+
+// chainScraper runs every built-in extractor as a fallback chain, the slowest configuration.
+var chainScraper = scraper.New(scraper.WithExtractor(scraper.Chain{
+	scraper.DefaultExtractor, scraper.JSONLDExtractor{}, scraper.MetaExtractor{}, scraper.ReadabilityExtractor{},
+}))
+
+// Extractors are the extraction paths the bench command knows how to measure, by name.
+var Extractors = map[string]Extractor{
+	"selectors": func(url, html string) error {
+		_, err := selectorScraper.ScrapeHTML(url, html)
+		return err
+	},
+	"chain": func(url, html string) error {
+		_, err := chainScraper.ScrapeHTML(url, html)
+		return err
+	},
+}
+
+// Fixture is one HTML file from the corpus.
+type Fixture struct {
+	name string
+	html string
+}
+
+// Result summarizes the measurements for a single extractor.
+type Result struct {
+	Extractor   string
+	Docs        int           // Docs is the number of extractions performed (fixtures × iterations).
+	Bytes       int64         // Bytes is the total HTML processed.
+	Elapsed     time.Duration // Elapsed is the wall-clock time spent extracting.
+	AllocsPerOp uint64
+	BytesPerOp  uint64
+	P50, P95    time.Duration // Per-document latency percentiles.
+	Max         time.Duration
+	Errors      int
+}
+
+// LoadCorpus reads every *.html or *.htm file under dir.
+func LoadCorpus(dir string) ([]Fixture, error) {
+	var fixtures []Fixture
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		ext := strings.ToLower(filepath.Ext(path))
+		if ext != ".html" && ext != ".htm" {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		fixtures = append(fixtures, Fixture{name: path, html: string(data)})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(fixtures) == 0 {
+		return nil, fmt.Errorf("no .html fixtures found in %s", dir)
+	}
+	return fixtures, nil
+}
+
+// Run measures ex over the corpus, repeating the whole corpus iterations times.
+func Run(name string, ex Extractor, corpus []Fixture, iterations int) Result {
+	res := Result{Extractor: name}
+	latencies := make([]time.Duration, 0, len(corpus)*iterations)
+
+	// Warm up once so one-time initialization does not skew the first sample.
+	ex("http://fixture.invalid/warmup", corpus[0].html)
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for i := 0; i < iterations; i++ {
+		for _, f := range corpus {
+			t := time.Now()
+			if err := ex("http://fixture.invalid/"+filepath.Base(f.name), f.html); err != nil {
+				res.Errors++
+			}
+			latencies = append(latencies, time.Since(t))
+			res.Bytes += int64(len(f.html))
+		}
+	}
+	res.Elapsed = time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	res.Docs = len(latencies)
+	res.AllocsPerOp = (after.Mallocs - before.Mallocs) / uint64(res.Docs)
+	res.BytesPerOp = (after.TotalAlloc - before.TotalAlloc) / uint64(res.Docs)
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	res.P50 = latencies[len(latencies)/2]
+	res.P95 = latencies[len(latencies)*95/100]
+	res.Max = latencies[len(latencies)-1]
+	return res
+}
+
+// Print writes a human-readable report line for each result.
+func Print(w io.Writer, results []Result) {
+	fmt.Fprintf(w, "%-12s %8s %10s %10s %12s %12s %10s %10s %10s %6s\n",
+		"extractor", "docs", "docs/s", "MB/s", "allocs/op", "B/op", "p50", "p95", "max", "errors")
+	for _, r := range results {
+		secs := r.Elapsed.Seconds()
+		fmt.Fprintf(w, "%-12s %8d %10.1f %10.2f %12d %12d %10s %10s %10s %6d\n",
+			r.Extractor, r.Docs, float64(r.Docs)/secs, float64(r.Bytes)/secs/1e6,
+			r.AllocsPerOp, r.BytesPerOp,
+			r.P50.Round(time.Microsecond), r.P95.Round(time.Microsecond), r.Max.Round(time.Microsecond),
+			r.Errors)
+	}
+}
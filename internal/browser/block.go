@@ -0,0 +1,89 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// resourceTypes are the DevTools names of the kinds of requests a page makes, keyed by
+// their lowercase form.
+var resourceTypes = map[string]string{
+	"stylesheet": "Stylesheet", "image": "Image", "media": "Media", "font": "Font", "script": "Script",
+	"texttrack": "TextTrack", "xhr": "XHR", "fetch": "Fetch", "prefetch": "Prefetch",
+	"eventsource": "EventSource", "websocket": "WebSocket", "manifest": "Manifest", "ping": "Ping",
+	"other": "Other",
+}
+
+// AdHosts are common advertising, analytics, and tracking services, blocked with their
+// subdomains by the "ads" entry of a block list.
+var AdHosts = []string{
+	"doubleclick.net", "googlesyndication.com", "googleadservices.com", "googletagservices.com",
+	"googletagmanager.com", "google-analytics.com", "adservice.google.com", "amazon-adsystem.com",
+	"adnxs.com", "adsrvr.org", "criteo.com", "criteo.net", "pubmatic.com", "rubiconproject.com",
+	"casalemedia.com", "openx.net", "taboola.com", "outbrain.com", "scorecardresearch.com",
+	"quantserve.com", "chartbeat.com", "chartbeat.net", "hotjar.com", "moatads.com",
+	"facebook.net", "connect.facebook.net", "bat.bing.com", "nr-data.net", "parsely.com",
+}
+
+// DefaultBlock is the block list for rendering when none is given: the resources that
+// only cost bandwidth and time, since nothing but the DOM is read.
+const DefaultBlock = "image,media,font,ads"
+
+// ParseBlock parses a comma-separated block list of resource types, such as image, font,
+// media, or stylesheet, and "ads" for AdHosts. An empty list blocks nothing.
+func ParseBlock(list string) (types, hosts []string, err error) {
+	for _, item := range strings.Split(list, ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		switch {
+		case item == "":
+		case item == "ads":
+			hosts = append(hosts, AdHosts...)
+		case resourceTypes[item] != "":
+			types = append(types, resourceTypes[item])
+		default:
+			return nil, nil, fmt.Errorf("browser: cannot block %q (want ads or a resource type such as image, media, font, or stylesheet)", item)
+		}
+	}
+	return types, hosts, nil
+}
+
+// blockPatterns returns the Fetch interception patterns for blocking the resource types
+// and hosts, subdomains included.
+func blockPatterns(types, hosts []string) []map[string]string {
+	var patterns []map[string]string
+	for _, t := range types {
+		patterns = append(patterns, map[string]string{"resourceType": t})
+	}
+	for _, h := range hosts {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if h == "" {
+			continue
+		}
+		patterns = append(patterns, map[string]string{"urlPattern": "*://" + h + "/*"}, map[string]string{"urlPattern": "*://*." + h + "/*"})
+	}
+	return patterns
+}
+
+// block makes t's page fail every request matching patterns before it is sent.
+func (t *tab) block(ctx context.Context, patterns []map[string]string) error {
+	if len(patterns) == 0 {
+		return nil
+	}
+	c, session := t.conn, t.session
+	c.handle(session, "Fetch.requestPaused", func(params json.RawMessage) {
+		var paused struct {
+			RequestID string `json:"requestId"`
+		}
+		if json.Unmarshal(params, &paused) != nil {
+			return
+		}
+		// Only matching requests are paused, so every one is failed. An error means the
+		// page is gone, and the request with it.
+		ctx, cancel := context.WithTimeout(context.Background(), healthTimeout)
+		defer cancel()
+		c.call(ctx, session, "Fetch.failRequest", map[string]any{"requestId": paused.RequestID, "errorReason": "BlockedByClient"}, nil)
+	})
+	return c.call(ctx, session, "Fetch.enable", map[string]any{"patterns": patterns}, nil)
+}
@@ -0,0 +1,216 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/websocket"
+)
+
+// errClosed is returned for calls on a connection whose websocket has gone away, for
+// example because the browser crashed.
+var errClosed = errors.New("browser: connection closed")
+
+// conn is a Chrome DevTools Protocol connection to a browser. Commands to a page are
+// sent over the same connection in the page's session, as Chrome's flattened session
+// mode allows.
+type conn struct {
+	ws *websocket.Conn
+
+	mu       sync.Mutex
+	nextID   int64
+	pending  map[int64]chan message
+	waiters  map[string][]chan json.RawMessage // waiters is keyed by session ID and event method.
+	handlers map[string]func(json.RawMessage)  // handlers is keyed like waiters.
+	err      error                             // err is set once the connection has failed.
+	done     chan struct{}
+}
+
+// message is a DevTools Protocol message: a command, its reply, or an event.
+type message struct {
+	ID        int64           `json:"id,omitempty"`
+	SessionID string          `json:"sessionId,omitempty"`
+	Method    string          `json:"method,omitempty"`
+	Params    any             `json:"params,omitempty"`
+	Result    json.RawMessage `json:"result,omitempty"`
+	Error     *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// incoming is a message as read, with its parameters left raw.
+type incoming struct {
+	message
+	RawParams json.RawMessage `json:"params,omitempty"`
+}
+
+// dial connects to the browser-level DevTools websocket at wsURL.
+func dial(wsURL string) (*conn, error) {
+	cfg, err := websocket.NewConfig(wsURL, "http://localhost")
+	if err != nil {
+		return nil, fmt.Errorf("browser: %w", err)
+	}
+	ws, err := websocket.DialConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("browser: connecting to %s: %w", wsURL, err)
+	}
+	// Rendered pages can be large; allow the whole of one in a single reply.
+	ws.MaxPayloadBytes = 256 << 20
+	c := &conn{
+		ws:       ws,
+		pending:  make(map[int64]chan message),
+		waiters:  make(map[string][]chan json.RawMessage),
+		handlers: make(map[string]func(json.RawMessage)),
+		done:     make(chan struct{}),
+	}
+	go c.read()
+	return c, nil
+}
+
+// read dispatches replies and events until the websocket fails.
+func (c *conn) read() {
+	for {
+		var m incoming
+		if err := websocket.JSON.Receive(c.ws, &m); err != nil {
+			c.mu.Lock()
+			c.err = fmt.Errorf("%w: %v", errClosed, err)
+			for id, ch := range c.pending {
+				close(ch)
+				delete(c.pending, id)
+			}
+			c.mu.Unlock()
+			close(c.done)
+			return
+		}
+		c.mu.Lock()
+		if m.ID != 0 {
+			if ch, ok := c.pending[m.ID]; ok {
+				ch <- m.message
+				delete(c.pending, m.ID)
+			}
+		} else {
+			key := m.SessionID + " " + m.Method
+			for _, ch := range c.waiters[key] {
+				ch <- m.RawParams
+			}
+			delete(c.waiters, key)
+			if h := c.handlers[key]; h != nil {
+				// Handlers usually answer with a command, whose reply this loop must be
+				// free to read.
+				go h(m.RawParams)
+			}
+		}
+		c.mu.Unlock()
+	}
+}
+
+// call sends a command, in session if it is not empty, and decodes its result into result
+// unless that is nil.
+func (c *conn) call(ctx context.Context, session, method string, params, result any) error {
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return c.err
+	}
+	c.nextID++
+	id := c.nextID
+	ch := make(chan message, 1)
+	c.pending[id] = ch
+	c.mu.Unlock()
+
+	if params == nil {
+		params = struct{}{}
+	}
+	if err := websocket.JSON.Send(c.ws, message{ID: id, SessionID: session, Method: method, Params: params}); err != nil {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+		return fmt.Errorf("browser: sending %s: %w", method, err)
+	}
+	select {
+	case m, ok := <-ch:
+		if !ok {
+			return c.failure()
+		}
+		if m.Error != nil {
+			return fmt.Errorf("browser: %s: %s", method, m.Error.Message)
+		}
+		if result != nil {
+			if err := json.Unmarshal(m.Result, result); err != nil {
+				return fmt.Errorf("browser: decoding %s reply: %w", method, err)
+			}
+		}
+		return nil
+	case <-ctx.Done():
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// failure returns the error the connection failed with.
+func (c *conn) failure() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// expect registers interest in the next event method in session. Call it before the
+// command that causes the event, then receive from the channel; call cancel if the
+// event is no longer wanted.
+func (c *conn) expect(session, method string) (events <-chan json.RawMessage, cancel func()) {
+	ch := make(chan json.RawMessage, 1)
+	key := session + " " + method
+	c.mu.Lock()
+	c.waiters[key] = append(c.waiters[key], ch)
+	c.mu.Unlock()
+	return ch, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		list := c.waiters[key]
+		for i, w := range list {
+			if w == ch {
+				c.waiters[key] = append(list[:i], list[i+1:]...)
+				break
+			}
+		}
+		if len(c.waiters[key]) == 0 {
+			delete(c.waiters, key)
+		}
+	}
+}
+
+// handle calls h with the parameters of every event method in session, until forget.
+func (c *conn) handle(session, method string, h func(json.RawMessage)) {
+	c.mu.Lock()
+	c.handlers[session+" "+method] = h
+	c.mu.Unlock()
+}
+
+// forget drops the handlers and waiters of session, once its page is closed.
+func (c *conn) forget(session string) {
+	prefix := session + " "
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.handlers {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.handlers, key)
+		}
+	}
+	for key := range c.waiters {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.waiters, key)
+		}
+	}
+}
+
+// close closes the websocket.
+func (c *conn) close() error {
+	return c.ws.Close()
+}
@@ -0,0 +1,420 @@
+// Package browser renders pages in headless Chrome for sites that build their articles
+// with JavaScript. A Pool keeps a few browser contexts, each an isolated set of cookies
+// and storage with one tab, open across pages, so a batch does not pay for a cold browser
+// start per URL. Contexts are checked before each use and replaced when they fail or
+// have rendered their share of pages, which keeps memory growth and leaked state of
+// long runs in check.
+//
+// The browser is driven over the Chrome DevTools Protocol, either by launching a local
+// Chrome or Chromium or by connecting to one already running with remote debugging on.
+package browser
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Options configure a Pool.
+type Options struct {
+	// Endpoint is the browser websocket URL of a running Chrome, as printed after
+	// "DevTools listening on". If empty, a headless browser is launched.
+	Endpoint string
+	// Exec is the browser executable to launch. If empty, common Chrome and Chromium
+	// names are looked up on PATH.
+	Exec string
+	// Contexts is how many pages are rendered at once, each in its own browser context.
+	Contexts int
+	// PagesPerContext is how many pages a context renders before it is replaced.
+	// Zero never replaces a healthy context.
+	PagesPerContext int
+	// Timeout limits how long one page may take to load and render, strategy included.
+	Timeout time.Duration
+	// Strategies say what to wait for after each page loads; without one the page is
+	// read as soon as its load event fires.
+	Strategies Strategies
+	// BlockTypes are the DevTools resource types, such as "Image" or "Font", whose
+	// requests pages may not make; ParseBlock builds them and BlockHosts from a list.
+	BlockTypes []string
+	// BlockHosts are hosts, subdomains included, that pages may not request from.
+	BlockHosts []string
+	// Stealth, if set, disguises the headless browser as an ordinary one.
+	Stealth *Stealth
+	// Proxy, if set, is the HTTP, HTTPS, or SOCKS5 proxy a launched browser sends its
+	// requests through. Chrome cannot be given a proxy's credentials this way.
+	Proxy *url.URL
+}
+
+// DefaultOptions are the settings used for zero fields of the Options given to New.
+var DefaultOptions = Options{Contexts: 2, PagesPerContext: 50, Timeout: 30 * time.Second}
+
+// executables are the browser names looked up on PATH when Options.Exec is empty.
+var executables = []string{"chromium", "chromium-browser", "google-chrome", "google-chrome-stable", "headless-shell", "chrome"}
+
+// healthTimeout is how long a context has to answer the check before it is used.
+const healthTimeout = 5 * time.Second
+
+// Pool renders pages through a fixed number of reusable browser contexts. It implements
+// the scraper's Renderer and is safe for concurrent use.
+type Pool struct {
+	opts     Options
+	patterns []map[string]string // patterns select the requests blocked in every page.
+
+	mu      sync.Mutex // mu guards conn, cmd, dataDir, and opts.Strategies.
+	conn    *conn
+	cmd     *exec.Cmd // cmd is the launched browser, nil when connected to an Endpoint.
+	dataDir string    // dataDir is the launched browser's throwaway profile.
+
+	// slots holds one entry per context: the context, or nil when it has yet to be
+	// created or was discarded.
+	slots chan *tab
+}
+
+// tab is one browser context and the page open in it.
+type tab struct {
+	conn      *conn // conn is the connection the context was created on.
+	contextID string
+	session   string
+	rendered  int
+}
+
+// New launches or connects to the browser. Contexts are created as pages need them.
+func New(opts Options) (*Pool, error) {
+	if opts.Contexts <= 0 {
+		opts.Contexts = DefaultOptions.Contexts
+	}
+	if opts.PagesPerContext < 0 {
+		opts.PagesPerContext = 0
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultOptions.Timeout
+	}
+	if opts.Proxy != nil && opts.Proxy.User != nil {
+		return nil, errors.New("browser: Chrome cannot log in to a proxy; give one without credentials")
+	}
+	p := &Pool{opts: opts, patterns: blockPatterns(opts.BlockTypes, opts.BlockHosts), slots: make(chan *tab, opts.Contexts)}
+	for range opts.Contexts {
+		p.slots <- nil
+	}
+	if err := p.start(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// start connects to the browser, launching it first unless an endpoint was given.
+// p.mu must be held or p not yet shared.
+func (p *Pool) start() error {
+	endpoint := p.opts.Endpoint
+	if endpoint == "" {
+		var err error
+		if endpoint, err = p.launch(); err != nil {
+			return err
+		}
+	}
+	c, err := dial(endpoint)
+	if err != nil {
+		p.stop()
+		return err
+	}
+	p.conn = c
+	return nil
+}
+
+// launch starts a headless browser with a fresh profile and returns its websocket URL.
+func (p *Pool) launch() (string, error) {
+	path := p.opts.Exec
+	if path == "" {
+		for _, name := range executables {
+			if found, err := exec.LookPath(name); err == nil {
+				path = found
+				break
+			}
+		}
+		if path == "" {
+			return "", fmt.Errorf("browser: no Chrome or Chromium found on PATH (tried %s)", strings.Join(executables, ", "))
+		}
+	}
+	dir, err := os.MkdirTemp("", "zero-scraper-browser-*")
+	if err != nil {
+		return "", fmt.Errorf("browser: %w", err)
+	}
+	args := []string{
+		"--headless=new",
+		"--remote-debugging-port=0",
+		"--remote-allow-origins=*",
+		"--user-data-dir=" + dir,
+		"--no-first-run",
+		"--no-default-browser-check",
+		"--disable-gpu",
+		"--disable-extensions",
+		"--mute-audio",
+	}
+	if proxy := p.opts.Proxy; proxy != nil {
+		// Chrome resolves names through a SOCKS5 proxy already, and knows no socks5h.
+		scheme := proxy.Scheme
+		if scheme == "socks5h" {
+			scheme = "socks5"
+		}
+		args = append(args, "--proxy-server="+scheme+"://"+proxy.Host)
+	}
+	cmd := exec.Command(path, append(args, "about:blank")...)
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("browser: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("browser: starting %s: %w", path, err)
+	}
+	p.cmd, p.dataDir = cmd, dir
+
+	// The browser prints its websocket URL on stderr once it is listening.
+	found := make(chan string, 1)
+	go func() {
+		sc := bufio.NewScanner(stderr)
+		for sc.Scan() {
+			if rest, ok := strings.CutPrefix(sc.Text(), "DevTools listening on "); ok {
+				found <- strings.TrimSpace(rest)
+				break
+			}
+		}
+		close(found)
+		// Keep draining, or the browser blocks once the pipe fills.
+		io.Copy(io.Discard, stderr)
+	}()
+	select {
+	case endpoint, ok := <-found:
+		if ok {
+			return endpoint, nil
+		}
+		p.stop()
+		return "", fmt.Errorf("browser: %s exited without opening a DevTools endpoint", path)
+	case <-time.After(20 * time.Second):
+		p.stop()
+		return "", fmt.Errorf("browser: %s did not open a DevTools endpoint in time", path)
+	}
+}
+
+// stop kills a launched browser and removes its profile. p.mu must be held.
+func (p *Pool) stop() {
+	if p.cmd != nil {
+		p.cmd.Process.Kill()
+		p.cmd.Wait()
+		p.cmd = nil
+	}
+	if p.dataDir != "" {
+		os.RemoveAll(p.dataDir)
+		p.dataDir = ""
+	}
+}
+
+// connection returns the live browser connection, restarting a launched browser whose
+// connection has failed, for example because it crashed.
+func (p *Pool) connection() (*conn, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn == nil {
+		return nil, errors.New("browser: pool is closed")
+	}
+	if p.conn.failure() == nil {
+		return p.conn, nil
+	}
+	if p.opts.Endpoint != "" {
+		return nil, p.conn.failure()
+	}
+	p.conn.close()
+	p.stop()
+	if err := p.start(); err != nil {
+		p.conn = nil
+		return nil, err
+	}
+	return p.conn, nil
+}
+
+// Render loads url in a pooled context and returns the page's HTML once it has loaded.
+func (p *Pool) Render(url string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.opts.Timeout)
+	defer cancel()
+
+	t := <-p.slots
+	html, err := p.render(ctx, &t, url)
+	if t != nil && (err != nil || (p.opts.PagesPerContext > 0 && t.rendered >= p.opts.PagesPerContext)) {
+		// A context that failed may be wedged, and one that has rendered its share may
+		// have grown; replace either with a fresh one on next use.
+		p.dispose(t)
+		t = nil
+	}
+	p.slots <- t
+	return html, err
+}
+
+// render renders url in *t, creating the context first if there is none or the one
+// there fails its health check.
+func (p *Pool) render(ctx context.Context, t **tab, url string) (string, error) {
+	c, err := p.connection()
+	if err != nil {
+		return "", err
+	}
+	if *t != nil && ((*t).conn != c || !p.healthy(*t)) {
+		p.dispose(*t)
+		*t = nil
+	}
+	if *t == nil {
+		if *t, err = p.newTab(ctx, c); err != nil {
+			return "", err
+		}
+	}
+	(*t).rendered++
+	p.mu.Lock()
+	st := p.opts.Strategies.forURL(url)
+	p.mu.Unlock()
+	return (*t).navigate(ctx, url, st)
+}
+
+// SetStrategies replaces the pool's strategies. Pages already being rendered keep the
+// strategy they started with.
+func (p *Pool) SetStrategies(s Strategies) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.opts.Strategies = s
+}
+
+// newTab creates a browser context with one page in it, attached to c.
+func (p *Pool) newTab(ctx context.Context, c *conn) (*tab, error) {
+	var created struct {
+		BrowserContextID string `json:"browserContextId"`
+	}
+	if err := c.call(ctx, "", "Target.createBrowserContext", map[string]any{"disposeOnDetach": true}, &created); err != nil {
+		return nil, err
+	}
+	t := &tab{conn: c, contextID: created.BrowserContextID}
+	var target struct {
+		TargetID string `json:"targetId"`
+	}
+	if err := c.call(ctx, "", "Target.createTarget", map[string]any{"url": "about:blank", "browserContextId": t.contextID}, &target); err != nil {
+		p.dispose(t)
+		return nil, err
+	}
+	var attached struct {
+		SessionID string `json:"sessionId"`
+	}
+	if err := c.call(ctx, "", "Target.attachToTarget", map[string]any{"targetId": target.TargetID, "flatten": true}, &attached); err != nil {
+		p.dispose(t)
+		return nil, err
+	}
+	t.session = attached.SessionID
+	if err := c.call(ctx, t.session, "Page.enable", nil, nil); err != nil {
+		p.dispose(t)
+		return nil, err
+	}
+	if err := t.block(ctx, p.patterns); err != nil {
+		p.dispose(t)
+		return nil, err
+	}
+	if err := t.disguise(ctx, p.opts.Stealth); err != nil {
+		p.dispose(t)
+		return nil, err
+	}
+	return t, nil
+}
+
+// healthy reports whether t's page still answers.
+func (p *Pool) healthy(t *tab) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), healthTimeout)
+	defer cancel()
+	_, err := t.eval(ctx, "1+1")
+	return err == nil
+}
+
+// dispose closes t's browser context and its page, ignoring errors: the context may
+// already be gone with a crashed browser.
+func (p *Pool) dispose(t *tab) {
+	ctx, cancel := context.WithTimeout(context.Background(), healthTimeout)
+	defer cancel()
+	t.conn.call(ctx, "", "Target.disposeBrowserContext", map[string]any{"browserContextId": t.contextID}, nil)
+	if t.session != "" {
+		t.conn.forget(t.session)
+	}
+}
+
+// eval evaluates the JavaScript expression in the tab's page and returns its value,
+// waiting for it to settle if it is a promise.
+func (t *tab) eval(ctx context.Context, expression string) (any, error) {
+	var out struct {
+		Result struct {
+			Value any `json:"value"`
+		} `json:"result"`
+		ExceptionDetails *struct {
+			Text      string `json:"text"`
+			Exception struct {
+				Description string `json:"description"`
+			} `json:"exception"`
+		} `json:"exceptionDetails"`
+	}
+	params := map[string]any{"expression": expression, "returnByValue": true, "awaitPromise": true}
+	if err := t.conn.call(ctx, t.session, "Runtime.evaluate", params, &out); err != nil {
+		return nil, err
+	}
+	if e := out.ExceptionDetails; e != nil {
+		if e.Exception.Description != "" {
+			return nil, errors.New(e.Exception.Description)
+		}
+		return nil, errors.New(e.Text)
+	}
+	return out.Result.Value, nil
+}
+
+// navigate loads url in the tab, waits for its load event and then as st says, and
+// returns the document's HTML.
+func (t *tab) navigate(ctx context.Context, url string, st Strategy) (string, error) {
+	loaded, cancel := t.conn.expect(t.session, "Page.loadEventFired")
+	defer cancel()
+	var nav struct {
+		ErrorText string `json:"errorText"`
+	}
+	if err := t.conn.call(ctx, t.session, "Page.navigate", map[string]any{"url": url}, &nav); err != nil {
+		return "", err
+	}
+	if nav.ErrorText != "" {
+		return "", fmt.Errorf("browser: loading %s: %s", url, nav.ErrorText)
+	}
+	select {
+	case <-loaded:
+	case <-t.conn.done:
+		return "", t.conn.failure()
+	case <-ctx.Done():
+		return "", fmt.Errorf("browser: loading %s: %w", url, ctx.Err())
+	}
+	if err := t.settle(ctx, st); err != nil {
+		return "", fmt.Errorf("%w at %s", err, url)
+	}
+	value, err := t.eval(ctx, "document.documentElement.outerHTML")
+	if err != nil {
+		return "", fmt.Errorf("browser: reading %s: %w", url, err)
+	}
+	html, _ := value.(string)
+	return html, nil
+}
+
+// Close closes every context and shuts down a launched browser.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn == nil {
+		return nil
+	}
+	err := p.conn.close()
+	p.conn = nil
+	p.stop()
+	return err
+}
@@ -0,0 +1,116 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Stealth makes rendered pages look like an ordinary desktop browser rather than a
+// headless one, for pages that are open to visitors but break, or refuse to load, when
+// a script spots the headless signature. Every setting is applied to each page before
+// it loads.
+type Stealth struct {
+	// UserAgent is the User-Agent header and navigator.userAgent. If empty, the
+	// browser's own is used with "HeadlessChrome" changed to "Chrome".
+	UserAgent string
+	// Width and Height are the window's size in CSS pixels, reported consistently by
+	// the viewport, the screen, and the outer window. Zero uses DefaultViewport.
+	Width, Height int
+	// Timezone is an IANA time zone, such as "America/New_York", for the page's clock.
+	// Empty keeps the machine's.
+	Timezone string
+	// Locale is a BCP 47 language tag, such as "en-US", for Accept-Language,
+	// navigator.language, and Intl formatting. Empty keeps the browser's.
+	Locale string
+}
+
+// DefaultViewport is the window size of a Stealth that gives none, a common laptop screen.
+var DefaultViewport = [2]int{1366, 768}
+
+// ParseViewport parses a window size written as WIDTHxHEIGHT, such as "1366x768".
+func ParseViewport(s string) (width, height int, err error) {
+	w, h, ok := strings.Cut(strings.ToLower(s), "x")
+	if ok {
+		width, err = strconv.Atoi(strings.TrimSpace(w))
+		if err == nil {
+			height, err = strconv.Atoi(strings.TrimSpace(h))
+		}
+	}
+	if !ok || err != nil || width <= 0 || height <= 0 {
+		return 0, 0, fmt.Errorf("browser: viewport %q is not WIDTHxHEIGHT", s)
+	}
+	return width, height, nil
+}
+
+// stealthScript patches what a headless browser gives away to scripts on the page. It
+// runs before any of the page's own scripts. %s is the navigator.languages list and %d
+// the window width and height.
+const stealthScript = `(() => {
+	const define = (obj, prop, value) => Object.defineProperty(obj, prop, {get: () => value, configurable: true});
+	define(Navigator.prototype, "webdriver", false);
+	const languages = %s;
+	if (languages.length > 0) define(Navigator.prototype, "languages", Object.freeze(languages));
+	if (!window.chrome) window.chrome = {runtime: {}};
+	define(window, "outerWidth", %d);
+	define(window, "outerHeight", %d);
+})();`
+
+// disguise applies s to t's page.
+func (t *tab) disguise(ctx context.Context, s *Stealth) error {
+	if s == nil {
+		return nil
+	}
+	c, session := t.conn, t.session
+	ua := s.UserAgent
+	if ua == "" {
+		var version struct {
+			UserAgent string `json:"userAgent"`
+		}
+		if err := c.call(ctx, "", "Browser.getVersion", nil, &version); err != nil {
+			return err
+		}
+		ua = strings.ReplaceAll(version.UserAgent, "HeadlessChrome", "Chrome")
+	}
+	override := map[string]any{"userAgent": ua}
+	var languages []string
+	if s.Locale != "" {
+		lang, _, _ := strings.Cut(s.Locale, "-")
+		languages = []string{s.Locale}
+		if lang != s.Locale {
+			languages = append(languages, lang)
+		}
+		override["acceptLanguage"] = strings.Join(languages, ",")
+	}
+	if err := c.call(ctx, session, "Network.setUserAgentOverride", override, nil); err != nil {
+		return err
+	}
+
+	width, height := s.Width, s.Height
+	if width <= 0 || height <= 0 {
+		width, height = DefaultViewport[0], DefaultViewport[1]
+	}
+	metrics := map[string]any{"width": width, "height": height, "deviceScaleFactor": 1, "mobile": false, "screenWidth": width, "screenHeight": height}
+	if err := c.call(ctx, session, "Emulation.setDeviceMetricsOverride", metrics, nil); err != nil {
+		return err
+	}
+	if s.Timezone != "" {
+		if err := c.call(ctx, session, "Emulation.setTimezoneOverride", map[string]any{"timezoneId": s.Timezone}, nil); err != nil {
+			return fmt.Errorf("browser: time zone %s: %w", s.Timezone, err)
+		}
+	}
+	if s.Locale != "" {
+		if err := c.call(ctx, session, "Emulation.setLocaleOverride", map[string]any{"locale": s.Locale}, nil); err != nil {
+			return fmt.Errorf("browser: locale %s: %w", s.Locale, err)
+		}
+	}
+
+	list, _ := json.Marshal(languages)
+	if languages == nil {
+		list = []byte("[]")
+	}
+	script := fmt.Sprintf(stealthScript, list, width, height)
+	return c.call(ctx, session, "Page.addScriptToEvaluateOnNewDocument", map[string]any{"source": script}, nil)
+}
@@ -0,0 +1,180 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Strategy says what to wait for after a page's load event before its HTML is read, for
+// sites whose articles or images arrive later: infinite scroll, lazy loading, or
+// content fetched by script. The steps run in field order; a zero field skips its step.
+type Strategy struct {
+	// WaitFor is a CSS selector that must match before the page is read, such as the
+	// article body a script fills in.
+	WaitFor string
+	// Scrolls is how many times to scroll to the bottom of the page, pausing after each
+	// for what it loads. Scrolling stops early once the page stops growing.
+	Scrolls int
+	// NetworkIdle waits until no resource has finished loading for a moment.
+	NetworkIdle bool
+	// Delay is a fixed wait at the end, for pages nothing else catches.
+	Delay time.Duration
+}
+
+// Strategies choose a Strategy by the domain of the page being rendered. A domain's
+// strategy applies to its subdomains too, the most specific domain winning; the key
+// "*" applies to every other page.
+type Strategies map[string]Strategy
+
+// scrollPause is how long to wait after each scroll for the content it triggers.
+const scrollPause = 750 * time.Millisecond
+
+// idleQuiet is how long no resource may finish loading for the network to count as idle.
+const idleQuiet = 500 * time.Millisecond
+
+// UnmarshalJSON reads a strategy written with snake_case keys and the delay in Go
+// duration syntax: {"wait_for": ".story-body", "scrolls": 5, "network_idle": true, "delay": "1s"}.
+func (st *Strategy) UnmarshalJSON(data []byte) error {
+	var f struct {
+		WaitFor     string `json:"wait_for"`
+		Scrolls     int    `json:"scrolls"`
+		NetworkIdle bool   `json:"network_idle"`
+		Delay       string `json:"delay"`
+	}
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+	*st = Strategy{WaitFor: f.WaitFor, Scrolls: f.Scrolls, NetworkIdle: f.NetworkIdle}
+	if f.Delay != "" {
+		d, err := time.ParseDuration(f.Delay)
+		if err != nil {
+			return fmt.Errorf("delay: %w", err)
+		}
+		st.Delay = d
+	}
+	if st.Scrolls < 0 || st.Delay < 0 {
+		return errors.New("negative scrolls or delay")
+	}
+	return nil
+}
+
+// MarshalJSON writes st as UnmarshalJSON reads it.
+func (st Strategy) MarshalJSON() ([]byte, error) {
+	f := struct {
+		WaitFor     string `json:"wait_for,omitempty"`
+		Scrolls     int    `json:"scrolls,omitempty"`
+		NetworkIdle bool   `json:"network_idle,omitempty"`
+		Delay       string `json:"delay,omitempty"`
+	}{WaitFor: st.WaitFor, Scrolls: st.Scrolls, NetworkIdle: st.NetworkIdle}
+	if st.Delay > 0 {
+		f.Delay = st.Delay.String()
+	}
+	return json.Marshal(f)
+}
+
+// Set records st as the strategy of domain and its subdomains.
+func (s Strategies) Set(domain string, st Strategy) {
+	s[strings.TrimPrefix(strings.ToLower(domain), "www.")] = st
+}
+
+// LoadStrategies reads strategies from a JSON file mapping domains to strategies:
+//
+//	{"*": {"network_idle": true}, "example.com": {"wait_for": ".story-body", "scrolls": 5, "delay": "1s"}}
+func LoadStrategies(path string) (Strategies, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("browser: %w", err)
+	}
+	var file map[string]Strategy
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("browser: reading %s: %w", path, err)
+	}
+	s := make(Strategies, len(file))
+	for domain, st := range file {
+		s.Set(domain, st)
+	}
+	return s, nil
+}
+
+// forURL returns the strategy for rawURL: its host's, its closest parent domain's, or
+// the "*" strategy.
+func (s Strategies) forURL(rawURL string) Strategy {
+	if len(s) == 0 {
+		return Strategy{}
+	}
+	if u, err := url.Parse(rawURL); err == nil {
+		for host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www."); host != ""; {
+			if st, ok := s[host]; ok {
+				return st
+			}
+			_, parent, ok := strings.Cut(host, ".")
+			if !ok {
+				break
+			}
+			host = parent
+		}
+	}
+	return s["*"]
+}
+
+// settle runs st on the loaded page in t.
+func (t *tab) settle(ctx context.Context, st Strategy) error {
+	if st.WaitFor != "" {
+		sel, _ := json.Marshal(st.WaitFor)
+		if _, err := t.eval(ctx, `new Promise(resolve => {
+			const check = () => document.querySelector(`+string(sel)+`) ? resolve(true) : setTimeout(check, 100);
+			check();
+		})`); err != nil {
+			return fmt.Errorf("browser: waiting for %s: %w", st.WaitFor, err)
+		}
+	}
+	for range st.Scrolls {
+		grew, err := t.eval(ctx, `(() => {
+			const before = document.documentElement.scrollHeight;
+			window.scrollTo(0, before);
+			return new Promise(resolve => setTimeout(() => resolve(document.documentElement.scrollHeight > before), `+fmt.Sprint(scrollPause.Milliseconds())+`));
+		})()`)
+		if err != nil {
+			return fmt.Errorf("browser: scrolling: %w", err)
+		}
+		if grew != true {
+			break
+		}
+	}
+	if st.NetworkIdle {
+		// Resource timing lists only the requests that have finished, so the page counts
+		// as idle once that list has stopped growing. The buffer is raised first so that
+		// a full one does not look idle on a page with many resources.
+		if _, err := t.eval(ctx, `new Promise(resolve => {
+			performance.setResourceTimingBufferSize(100000);
+			let seen = -1, since = Date.now();
+			const check = () => {
+				const n = performance.getEntriesByType("resource").length;
+				if (n !== seen || document.readyState !== "complete") {
+					seen = n;
+					since = Date.now();
+				} else if (Date.now() - since >= `+fmt.Sprint(idleQuiet.Milliseconds())+`) {
+					return resolve(true);
+				}
+				setTimeout(check, 100);
+			};
+			check();
+		})`); err != nil {
+			return fmt.Errorf("browser: waiting for the network: %w", err)
+		}
+	}
+	if st.Delay > 0 {
+		select {
+		case <-time.After(st.Delay):
+		case <-ctx.Done():
+			return fmt.Errorf("browser: %w", ctx.Err())
+		}
+	}
+	return nil
+}
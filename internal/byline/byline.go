@@ -0,0 +1,151 @@
+// Package byline splits a scraped byline such as "By Jane Doe and John Roe" or
+// "Von Anna Müller und Jens Schmidt" into individual author names. Bylines in other
+// languages use their own prefixes and separators, which can be chosen per domain.
+package byline
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// prefixes are the words that introduce a byline, by language. They are matched
+// case-insensitively at the start of the byline and must be followed by white space.
+var prefixes = map[string][]string{
+	"en": {"Written by", "Reporting by", "Story by", "By"},
+	"de": {"Ein Beitrag von", "Von"},
+	"fr": {"Propos recueillis par", "Par"},
+	"es": {"Por"},
+	"it": {"A cura di", "Di"},
+	"pt": {"Por"},
+	"nl": {"Door"},
+}
+
+// separators are the words that join author names, by language. Commas, semicolons,
+// ampersands, and slashes separate names in every language.
+var separators = map[string][]string{
+	"en": {"and"},
+	"de": {"und"},
+	"fr": {"et"},
+	"es": {"y", "e"},
+	"it": {"e", "ed"},
+	"pt": {"e"},
+	"nl": {"en"},
+}
+
+var (
+	mu sync.RWMutex
+	// domainLocales maps a host (without "www.") to the language of its bylines.
+	domainLocales = map[string]string{}
+	// splitters caches the compiled separator pattern for each language.
+	splitters = map[string]*regexp.Regexp{}
+)
+
+// Languages returns the language codes that have byline rules.
+func Languages() []string {
+	langs := make([]string, 0, len(prefixes))
+	for l := range prefixes {
+		langs = append(langs, l)
+	}
+	return langs
+}
+
+// SetDomainLocales replaces the per-domain language settings, e.g. {"spiegel.de": "de"}.
+func SetDomainLocales(locales map[string]string) {
+	mu.Lock()
+	defer mu.Unlock()
+	domainLocales = make(map[string]string, len(locales))
+	for host, lang := range locales {
+		domainLocales[strings.TrimPrefix(strings.ToLower(host), "www.")] = lang
+	}
+}
+
+// LocaleFor returns the byline language configured for the host of rawURL, or "" if none.
+// Subdomains inherit their parent domain's setting.
+func LocaleFor(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+
+	mu.RLock()
+	defer mu.RUnlock()
+	for host != "" {
+		if lang, ok := domainLocales[host]; ok {
+			return lang
+		}
+		_, parent, ok := strings.Cut(host, ".")
+		if !ok {
+			break
+		}
+		host = parent
+	}
+	return ""
+}
+
+// ParseURL splits the byline of an article scraped from rawURL using that domain's locale.
+func ParseURL(rawURL, byline string) []string {
+	return Parse(byline, LocaleFor(rawURL))
+}
+
+// Parse splits byline into author names. Any known prefix ("By", "Von", "Par", "Por",
+// "Di", ...) is removed. Names are split on punctuation, the English "and", and, when
+// lang is set, that language's separators; without a locale only English words are used,
+// since short words like "e" or "y" are too ambiguous to treat as separators everywhere.
+func Parse(byline, lang string) []string {
+	byline = strings.Join(strings.Fields(byline), " ")
+	byline = stripPrefix(byline, lang)
+
+	var names []string
+	for _, n := range splitter(lang).Split(byline, -1) {
+		if n = strings.Trim(n, " .:"); n != "" {
+			names = append(names, n)
+		}
+	}
+	return names
+}
+
+// stripPrefix removes a leading byline prefix. With a locale only that language and
+// English are tried; otherwise every language's prefixes are.
+func stripPrefix(byline, lang string) string {
+	langs := Languages()
+	if lang != "" {
+		langs = []string{lang, "en"}
+	}
+	lower := strings.ToLower(byline)
+	for _, l := range langs {
+		for _, p := range prefixes[l] {
+			p = strings.ToLower(p) + " "
+			if strings.HasPrefix(lower, p) {
+				return byline[len(p):]
+			}
+		}
+	}
+	return byline
+}
+
+// splitter returns the compiled separator pattern for lang, building it on first use.
+func splitter(lang string) *regexp.Regexp {
+	mu.RLock()
+	re, ok := splitters[lang]
+	mu.RUnlock()
+	if ok {
+		return re
+	}
+
+	words := append([]string{}, separators["en"]...)
+	if lang != "en" {
+		words = append(words, separators[lang]...)
+	}
+	for i, w := range words {
+		words[i] = regexp.QuoteMeta(w)
+	}
+	re = regexp.MustCompile(`\s*(?:[,;&/]|\s(?:` + strings.Join(words, "|") + `)\s)\s*`)
+
+	mu.Lock()
+	splitters[lang] = re
+	mu.Unlock()
+	return re
+}
@@ -0,0 +1,110 @@
+// Package compare reports field-level differences between two extractions of the same
+// page, so a change to extraction heuristics or a new site extractor can be checked
+// against real pages before it is rolled out.
+package compare
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/hail2skins/zero-scraper/pkg/scraper"
+)
+
+// Difference is one field that came out differently under the two configurations. A and B
+// describe the field's value under each, including the extractor that produced it.
+type Difference struct {
+	Field  string
+	A, B   string
+	Detail string // Detail adds a measure of how far apart the values are, when there is one.
+}
+
+// Articles returns the fields that differ between a and b, which should be extractions
+// of the same HTML. Fields that are equal are not reported, even if different extractors
+// produced them.
+func Articles(a, b scraper.Article) []Difference {
+	var diffs []Difference
+	if a.Title != b.Title {
+		diffs = append(diffs, Difference{Field: "title", A: describe(a, "title", a.Title), B: describe(b, "title", b.Title)})
+	}
+	if strings.TrimSpace(a.Content) != strings.TrimSpace(b.Content) {
+		diffs = append(diffs, Difference{
+			Field:  "content",
+			A:      describeContent(a),
+			B:      describeContent(b),
+			Detail: fmt.Sprintf("%.0f%% of words shared", 100*wordOverlap(a.Content, b.Content)),
+		})
+	}
+	if a.Byline != b.Byline {
+		diffs = append(diffs, Difference{Field: "byline", A: describe(a, "byline", a.Byline), B: describe(b, "byline", b.Byline)})
+	}
+	if !a.Published.Equal(b.Published) {
+		diffs = append(diffs, Difference{Field: "published", A: describe(a, "published", formatDate(a.Published)), B: describe(b, "published", formatDate(b.Published))})
+	}
+	if a.Publisher != b.Publisher {
+		diffs = append(diffs, Difference{Field: "publisher", A: describe(a, "publisher", a.Publisher), B: describe(b, "publisher", b.Publisher)})
+	}
+	if strings.Join(a.Authors, "\x00") != strings.Join(b.Authors, "\x00") {
+		diffs = append(diffs, Difference{Field: "authors", A: fmt.Sprintf("%q", a.Authors), B: fmt.Sprintf("%q", b.Authors)})
+	}
+	return diffs
+}
+
+// describeContent summarizes an article's content by length, since whole articles are
+// too long to print side by side.
+func describeContent(a scraper.Article) string {
+	return describe(a, "content", fmt.Sprintf("%d chars", len([]rune(strings.TrimSpace(a.Content)))))
+}
+
+// formatDate renders a publication time as RFC 3339, or nothing if there is none.
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// describe renders a field's value with the source and score recorded for it.
+func describe(a scraper.Article, field, value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	c, ok := a.Confidence[field]
+	if !ok || c.Source == "" {
+		return value
+	}
+	return fmt.Sprintf("%s (%s, %.2f)", value, c.Source, c.Score)
+}
+
+// wordOverlap is the share of words two texts have in common, counting repeated words as
+// often as they occur in both: 1 for the same words in any order, 0 for nothing in common.
+func wordOverlap(a, b string) float64 {
+	wa, wb := strings.Fields(strings.ToLower(a)), strings.Fields(strings.ToLower(b))
+	if len(wa)+len(wb) == 0 {
+		return 1
+	}
+	counts := make(map[string]int, len(wa))
+	for _, w := range wa {
+		counts[w]++
+	}
+	var common int
+	for _, w := range wb {
+		if counts[w] > 0 {
+			counts[w]--
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(wa)+len(wb))
+}
+
+// Print writes the differences found for one page.
+func Print(w io.Writer, url string, diffs []Difference) {
+	fmt.Fprintln(w, url)
+	for _, d := range diffs {
+		fmt.Fprintf(w, "  %s:\n    a: %s\n    b: %s\n", d.Field, d.A, d.B)
+		if d.Detail != "" {
+			fmt.Fprintf(w, "    %s\n", d.Detail)
+		}
+	}
+}
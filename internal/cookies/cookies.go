@@ -0,0 +1,266 @@
+// Package cookies provides a cookie jar that can be saved to a file and loaded again,
+// so that the session and consent cookies a site hands out outlive a single run. Sites
+// behind soft paywalls or consent walls often send the full article only with them.
+//
+// A cookies file is either a JSON array, the format Save writes,
+//
+//	[{"name": "session", "value": "d41d...", "domain": "example.com", "path": "/", "secure": true, "expires": "2026-11-15T09:30:00Z"}]
+//
+// or a Netscape cookies.txt, as exported by browser extensions, curl, and wget, with one
+// tab-separated cookie per line: domain, subdomains (TRUE or FALSE), path, secure (TRUE
+// or FALSE), expiry in Unix seconds (0 for a session cookie), name, and value.
+package cookies
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cookie is a cookie as kept in a cookies file.
+type Cookie struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	// Domain is the site the cookie is sent to, e.g. "example.com".
+	Domain string `json:"domain"`
+	// HostOnly keeps the cookie from Domain's subdomains.
+	HostOnly bool   `json:"host_only,omitempty"`
+	Path     string `json:"path"`
+	Secure   bool   `json:"secure,omitempty"`
+	HTTPOnly bool   `json:"http_only,omitempty"`
+	// Expires is when the cookie expires; zero for a session cookie, which is kept
+	// until a site removes it.
+	Expires time.Time `json:"expires,omitzero"`
+}
+
+// Jar is an http.CookieJar that remembers every cookie set in it, so that they can be
+// saved. It is safe for concurrent use.
+type Jar struct {
+	jar *cookiejar.Jar
+
+	mu      sync.Mutex
+	cookies map[string]Cookie // cookies maps domain, path, and name to the cookies set.
+}
+
+// New returns an empty jar.
+func New() *Jar {
+	// cookiejar.New only fails on invalid options, and there are none.
+	jar, _ := cookiejar.New(nil)
+	return &Jar{jar: jar, cookies: make(map[string]Cookie)}
+}
+
+// Cookies implements http.CookieJar.
+func (j *Jar) Cookies(u *url.URL) []*http.Cookie {
+	return j.jar.Cookies(u)
+}
+
+// SetCookies implements http.CookieJar.
+func (j *Jar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.jar.SetCookies(u, cookies)
+	now := time.Now()
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, c := range cookies {
+		rec := Cookie{Name: c.Name, Value: c.Value, Path: c.Path, Secure: c.Secure, HTTPOnly: c.HttpOnly}
+		host := strings.ToLower(u.Hostname())
+		rec.Domain = strings.TrimPrefix(strings.ToLower(c.Domain), ".")
+		switch {
+		case rec.Domain == "":
+			rec.Domain, rec.HostOnly = host, true
+		case host != rec.Domain && !strings.HasSuffix(host, "."+rec.Domain):
+			// The jar refuses a cookie for another site, so it is not kept either.
+			continue
+		}
+		if !strings.HasPrefix(rec.Path, "/") {
+			rec.Path = defaultPath(u.Path)
+		}
+		switch {
+		case c.MaxAge < 0:
+			rec.Expires = now
+		case c.MaxAge > 0:
+			rec.Expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+		default:
+			rec.Expires = c.Expires
+		}
+		key := rec.Domain + ";" + rec.Path + ";" + rec.Name
+		if !rec.Expires.IsZero() && !rec.Expires.After(now) {
+			delete(j.cookies, key)
+			continue
+		}
+		j.cookies[key] = rec
+	}
+}
+
+// Set adds c to the jar, as if the site had set it.
+func (j *Jar) Set(c Cookie) {
+	hc := &http.Cookie{Name: c.Name, Value: c.Value, Path: c.Path, Secure: c.Secure, HttpOnly: c.HTTPOnly, Expires: c.Expires}
+	if !c.HostOnly {
+		hc.Domain = c.Domain
+	}
+	j.SetCookies(&url.URL{Scheme: "https", Host: c.Domain, Path: c.Path}, []*http.Cookie{hc})
+}
+
+// All returns the unexpired cookies in the jar, sorted by domain, path, and name.
+func (j *Jar) All() []Cookie {
+	now := time.Now()
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	all := make([]Cookie, 0, len(j.cookies))
+	for _, c := range j.cookies {
+		if c.Expires.IsZero() || c.Expires.After(now) {
+			all = append(all, c)
+		}
+	}
+	sort.Slice(all, func(a, b int) bool {
+		if all[a].Domain != all[b].Domain {
+			return all[a].Domain < all[b].Domain
+		}
+		if all[a].Path != all[b].Path {
+			return all[a].Path < all[b].Path
+		}
+		return all[a].Name < all[b].Name
+	})
+	return all
+}
+
+// Load adds the cookies in the file at path, JSON or Netscape, to the jar, and returns
+// how many there were. A missing file adds none.
+func (j *Jar) Load(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("cookies: %w", err)
+	}
+	var cookies []Cookie
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		err = json.Unmarshal(data, &cookies)
+	} else {
+		cookies, err = parseNetscape(data)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("cookies: %s: %w", path, err)
+	}
+	for _, c := range cookies {
+		j.Set(c)
+	}
+	return len(cookies), nil
+}
+
+// Save writes the unexpired cookies in the jar to a temporary file and renames it to
+// path: in Netscape format if path ends in ".txt", and as JSON otherwise.
+func (j *Jar) Save(path string) error {
+	var data []byte
+	if strings.HasSuffix(path, ".txt") {
+		data = formatNetscape(j.All())
+	} else {
+		var err error
+		if data, err = json.MarshalIndent(j.All(), "", "  "); err != nil {
+			return fmt.Errorf("cookies: %w", err)
+		}
+		data = append(data, '\n')
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".cookies-*")
+	if err != nil {
+		return fmt.Errorf("cookies: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("cookies: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("cookies: %w", err)
+	}
+	// The file holds sessions, so it is kept from other users.
+	if err := os.Chmod(tmp.Name(), 0o600); err != nil {
+		return fmt.Errorf("cookies: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// httpOnlyPrefix marks an HttpOnly cookie in a Netscape file, in place of the comment
+// it would otherwise look like.
+const httpOnlyPrefix = "#HttpOnly_"
+
+// parseNetscape reads a Netscape cookies.txt.
+func parseNetscape(data []byte) ([]Cookie, error) {
+	var cookies []Cookie
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		var c Cookie
+		if rest, ok := strings.CutPrefix(line, httpOnlyPrefix); ok {
+			line, c.HTTPOnly = rest, true
+		}
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("line %d: want 7 tab-separated fields, got %d", n, len(fields))
+		}
+		expires, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid expiry %q", n, fields[4])
+		}
+		if expires > 0 {
+			c.Expires = time.Unix(expires, 0).UTC()
+		}
+		c.Domain = strings.TrimPrefix(strings.ToLower(fields[0]), ".")
+		c.HostOnly = !strings.EqualFold(fields[1], "TRUE")
+		c.Path = fields[2]
+		c.Secure = strings.EqualFold(fields[3], "TRUE")
+		c.Name, c.Value = fields[5], fields[6]
+		cookies = append(cookies, c)
+	}
+	return cookies, scanner.Err()
+}
+
+// formatNetscape writes cookies as a Netscape cookies.txt.
+func formatNetscape(cookies []Cookie) []byte {
+	var b bytes.Buffer
+	b.WriteString("# Netscape HTTP Cookie File\n")
+	for _, c := range cookies {
+		domain, subdomains := c.Domain, "FALSE"
+		if !c.HostOnly {
+			domain, subdomains = "."+domain, "TRUE"
+		}
+		if c.HTTPOnly {
+			domain = httpOnlyPrefix + domain
+		}
+		var expires int64
+		if !c.Expires.IsZero() {
+			expires = c.Expires.Unix()
+		}
+		secure := "FALSE"
+		if c.Secure {
+			secure = "TRUE"
+		}
+		fmt.Fprintf(&b, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n", domain, subdomains, c.Path, secure, expires, c.Name, c.Value)
+	}
+	return b.Bytes()
+}
+
+// defaultPath returns the path a cookie set without one applies to: the directory of
+// the request's path.
+func defaultPath(path string) string {
+	i := strings.LastIndex(path, "/")
+	if i <= 0 {
+		return "/"
+	}
+	return path[:i]
+}
@@ -0,0 +1,240 @@
+// Package coverage compares how different outlets covered one story: how much each wrote
+// and when, the tone of their articles, and which quotes and figures each of them carried.
+package coverage
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/hail2skins/zero-scraper/internal/digest"
+)
+
+// Article is one outlet's article about the story.
+type Article struct {
+	URL       string    `json:"url"`
+	Title     string    `json:"title,omitempty"`
+	Byline    string    `json:"byline,omitempty"`
+	Published time.Time `json:"published,omitzero"`
+	FetchedAt time.Time `json:"fetched_at"`
+	Words     int       `json:"words"`
+	// Sentiment runs from -1 (every loaded word negative) to 1 (every one positive).
+	Sentiment float64 `json:"sentiment"`
+}
+
+// Outlet summarizes one site's coverage.
+type Outlet struct {
+	Name     string    `json:"name"`
+	Articles []Article `json:"articles"`
+	Words    int       `json:"words"` // Words is the total across the outlet's articles.
+	// FirstSeen and LastSeen are the earliest and latest publication times of the
+	// outlet's articles, taking the fetch time of an article whose page gives none.
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	Sentiment float64   `json:"sentiment"` // Sentiment is the mean over the outlet's articles.
+}
+
+// Passage is a quote or figure and the outlets that carried it.
+type Passage struct {
+	Text    string   `json:"text"`
+	Outlets []string `json:"outlets"`
+}
+
+// Report is the comparison of every outlet's coverage.
+type Report struct {
+	Story   string    `json:"story"`
+	Outlets []Outlet  `json:"outlets"`
+	Quotes  []Passage `json:"quotes"`
+	Figures []Passage `json:"figures"`
+}
+
+// Analyze compares the coverage in items, the articles about story. Outlets are ordered
+// by when they first had the story; quotes and figures by how many outlets carried them.
+func Analyze(story string, items []digest.Item) Report {
+	r := Report{Story: story}
+	outlets := make(map[string]*Outlet)
+	quotes := make(map[string]*passageSet)
+	figures := make(map[string]*passageSet)
+	for _, it := range items {
+		name := it.Host()
+		o, ok := outlets[name]
+		if !ok {
+			o = &Outlet{Name: name, FirstSeen: it.Time(), LastSeen: it.Time()}
+			outlets[name] = o
+		}
+		a := Article{URL: it.URL, Title: it.Title, Byline: it.Byline, Published: it.Published, FetchedAt: it.FetchedAt, Words: len(strings.Fields(it.Content)), Sentiment: Sentiment(it.Content)}
+		o.Articles = append(o.Articles, a)
+		o.Words += a.Words
+		if it.Time().Before(o.FirstSeen) {
+			o.FirstSeen = it.Time()
+		}
+		if it.Time().After(o.LastSeen) {
+			o.LastSeen = it.Time()
+		}
+		for _, q := range Quotes(it.Content) {
+			addPassage(quotes, q, name)
+		}
+		for _, f := range Figures(it.Content) {
+			addPassage(figures, f, name)
+		}
+	}
+
+	for _, o := range outlets {
+		var sum float64
+		for _, a := range o.Articles {
+			sum += a.Sentiment
+		}
+		o.Sentiment = sum / float64(len(o.Articles))
+		r.Outlets = append(r.Outlets, *o)
+	}
+	sort.Slice(r.Outlets, func(i, j int) bool {
+		if !r.Outlets[i].FirstSeen.Equal(r.Outlets[j].FirstSeen) {
+			return r.Outlets[i].FirstSeen.Before(r.Outlets[j].FirstSeen)
+		}
+		return r.Outlets[i].Name < r.Outlets[j].Name
+	})
+	r.Quotes = passages(quotes)
+	r.Figures = passages(figures)
+	return r
+}
+
+// passageSet collects the outlets carrying one passage, keyed by its normalized text.
+type passageSet struct {
+	text    string // text is the first wording seen.
+	outlets map[string]bool
+}
+
+// addPassage records that outlet carried text.
+func addPassage(set map[string]*passageSet, text, outlet string) {
+	key := normalize(text)
+	p, ok := set[key]
+	if !ok {
+		p = &passageSet{text: text, outlets: make(map[string]bool)}
+		set[key] = p
+	}
+	p.outlets[outlet] = true
+}
+
+// passages lists a set's passages, most widely carried first.
+func passages(set map[string]*passageSet) []Passage {
+	out := make([]Passage, 0, len(set))
+	for _, p := range set {
+		pass := Passage{Text: p.text}
+		for o := range p.outlets {
+			pass.Outlets = append(pass.Outlets, o)
+		}
+		sort.Strings(pass.Outlets)
+		out = append(out, pass)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if len(out[i].Outlets) != len(out[j].Outlets) {
+			return len(out[i].Outlets) > len(out[j].Outlets)
+		}
+		return out[i].Text < out[j].Text
+	})
+	return out
+}
+
+// normalize reduces a passage to lowercase letters and digits separated by single
+// spaces, so that different typography does not split one quote in two.
+func normalize(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// quotePattern matches text in straight or curly double quotes.
+var quotePattern = regexp.MustCompile(`["“]([^"“”]+)["”]`)
+
+// minQuoteWords is the fewest words a quotation needs to count as a quote rather than a
+// scare-quoted term or a title.
+const minQuoteWords = 5
+
+// Quotes returns the quotations in content.
+func Quotes(content string) []string {
+	var out []string
+	for _, m := range quotePattern.FindAllStringSubmatch(content, -1) {
+		if q := strings.TrimSpace(m[1]); len(strings.Fields(q)) >= minQuoteWords {
+			out = append(out, q)
+		}
+	}
+	return out
+}
+
+// figurePattern matches numbers with their unit: money, percentages, and counted nouns
+// such as "$1.2 billion", "37 percent", or "12,000 people".
+var figurePattern = regexp.MustCompile(`(?i)[$€£]?\d[\d,.]*\s*(?:%|percent|per cent|million|billion|trillion|thousand|[a-z]+)`)
+
+// figureUnits are the words after a number that make it a figure worth comparing, rather
+// than a date or an address.
+var figureUnits = map[string]bool{
+	"%": true, "percent": true, "per cent": true, "million": true, "billion": true, "trillion": true, "thousand": true,
+	"people": true, "dead": true, "killed": true, "injured": true, "votes": true, "jobs": true, "homes": true,
+	"acres": true, "miles": true, "kilometers": true, "years": true, "dollars": true, "troops": true, "cases": true,
+}
+
+// Figures returns the figures in content.
+func Figures(content string) []string {
+	var out []string
+	for _, m := range figurePattern.FindAllString(content, -1) {
+		num := strings.TrimLeft(m, "$€£")
+		currency := len(num) < len(m)
+		i := strings.IndexFunc(num, func(r rune) bool { return !unicode.IsDigit(r) && r != ',' && r != '.' })
+		if i < 0 {
+			i = len(num)
+		}
+		switch unit := strings.ToLower(strings.TrimSpace(num[i:])); {
+		case figureUnits[unit]:
+			out = append(out, m)
+		case currency:
+			// An amount followed by an ordinary word: keep just the amount.
+			out = append(out, strings.TrimRight(m[:len(m)-len(num)+i], " .,"))
+		}
+	}
+	return out
+}
+
+// Sentiment scores the tone of content by the loaded words it uses: -1 when all of them
+// are negative, 1 when all are positive, and 0 for balanced or neutral text. It is a
+// lexicon count, good enough to tell alarmed coverage from upbeat coverage of the same
+// story, not to grade a single sentence.
+func Sentiment(content string) float64 {
+	var pos, neg int
+	for _, w := range strings.FieldsFunc(strings.ToLower(content), func(r rune) bool { return !unicode.IsLetter(r) }) {
+		switch {
+		case positive[w]:
+			pos++
+		case negative[w]:
+			neg++
+		}
+	}
+	if pos+neg == 0 {
+		return 0
+	}
+	return float64(pos-neg) / float64(pos+neg)
+}
+
+// positive and negative are the sentiment lexicon.
+var positive, negative = lexicon(`
+	achieve achieved agreement benefit benefits boost boosted breakthrough celebrate celebrated
+	deal gain gains good great growth hope hopeful improve improved improvement praise praised
+	progress rally recover recovery relief rescue rescued resolve resolved safe success
+	successful support supported surge thrive victory welcome welcomed win won`, `
+	accuse accused attack attacked bad blame chaos collapse collapsed concern concerns crisis
+	damage damaged danger dead death decline declined destroy destroyed disaster fail failed
+	failure fear fears fell fire injured kill killed loss losses plunge protest risk scandal
+	shutdown threat threatened violence warn warned worse worst`)
+
+// lexicon builds the word sets from whitespace-separated lists.
+func lexicon(pos, neg string) (map[string]bool, map[string]bool) {
+	set := func(list string) map[string]bool {
+		m := make(map[string]bool)
+		for _, w := range strings.Fields(list) {
+			m[w] = true
+		}
+		return m
+	}
+	return set(pos), set(neg)
+}
@@ -0,0 +1,85 @@
+package coverage
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+)
+
+// Formats Render can write.
+const (
+	JSON = "json"
+	HTML = "html"
+)
+
+// Render writes the report to w as indented JSON or as a standalone HTML page.
+func Render(w io.Writer, r Report, format string) error {
+	switch format {
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case HTML:
+		return htmlTemplate.Execute(w, r)
+	}
+	return fmt.Errorf("coverage: unknown format %q (want json or html)", format)
+}
+
+// htmlTemplate renders the report with one table per section. The page is in English,
+// but quotes, figures, and article titles and bylines take their direction from their
+// own text, so that right-to-left coverage reads correctly.
+var htmlTemplate = template.Must(template.New("coverage").Funcs(template.FuncMap{
+	"time": func(t interface{ Format(string) string }) string { return t.Format("Jan 2 15:04 MST") },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Coverage of {{.Story}}</title>
+<style>
+body { font-family: sans-serif; max-width: 60em; margin: 0 auto; padding: 1em; color: #222 }
+table { border-collapse: collapse; width: 100%; margin-bottom: 2em }
+th, td { border-bottom: 1px solid #ddd; padding: 0.4em; text-align: left; vertical-align: top }
+td.num { text-align: right }
+</style>
+</head>
+<body>
+<h1>Coverage of {{.Story}}</h1>
+<h2>Outlets</h2>
+<table>
+<tr><th>Outlet</th><th>Articles</th><th>Words</th><th>First seen</th><th>Last seen</th><th>Sentiment</th></tr>
+{{- range .Outlets}}
+<tr><td>{{.Name}}</td><td class="num">{{len .Articles}}</td><td class="num">{{.Words}}</td><td>{{time .FirstSeen}}</td><td>{{time .LastSeen}}</td><td class="num">{{printf "%+.2f" .Sentiment}}</td></tr>
+{{- end}}
+</table>
+<h2>Quotes</h2>
+{{- if .Quotes}}
+<table>
+<tr><th>Quote</th><th>Carried by</th></tr>
+{{- range .Quotes}}
+<tr><td dir="auto">“{{.Text}}”</td><td>{{range $i, $o := .Outlets}}{{if $i}}, {{end}}{{$o}}{{end}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>No quotes found.</p>
+{{- end}}
+<h2>Figures</h2>
+{{- if .Figures}}
+<table>
+<tr><th>Figure</th><th>Carried by</th></tr>
+{{- range .Figures}}
+<tr><td dir="auto">{{.Text}}</td><td>{{range $i, $o := .Outlets}}{{if $i}}, {{end}}{{$o}}{{end}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>No figures found.</p>
+{{- end}}
+<h2>Articles</h2>
+<ul>
+{{- range .Outlets}}{{range .Articles}}
+<li><a href="{{.URL}}">{{if .Title}}<bdi dir="auto">{{.Title}}</bdi>{{else}}{{.URL}}{{end}}</a>{{if .Byline}} — <bdi dir="auto">{{.Byline}}</bdi>{{end}} ({{.Words}} words)</li>
+{{- end}}{{end}}
+</ul>
+</body>
+</html>
+`))
@@ -0,0 +1,250 @@
+// Package dedup suppresses duplicate articles according to configurable rules. Each rule
+// pairs a scope (what counts as the same story) with a window (how long a story is
+// remembered), for example "the exact URL forever, the same content within 30 days, and
+// nearly the same content within 7 days".
+package dedup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hail2skins/zero-scraper/internal/provenance"
+)
+
+// Scopes a rule can apply to.
+const (
+	// ScopeURL matches the exact same URL.
+	ScopeURL = "url"
+	// ScopeContent matches byte-identical article content, whatever its URL.
+	ScopeContent = "content"
+	// ScopeSimhash matches near-identical content: syndicated copies and pages whose
+	// text differs only by a correction or a changed headline.
+	ScopeSimhash = "simhash"
+)
+
+// simhashDistance is the most bits two simhashes may differ by and still match.
+const simhashDistance = 3
+
+// Rule says that articles matching in Scope within Window of each other are duplicates.
+// A zero Window means forever.
+type Rule struct {
+	Scope  string
+	Window time.Duration
+}
+
+// ParseRules parses "scope=window,..." such as "url=forever,content=30d,simhash=7d".
+// Windows are Go durations ("168h"), a number of days ("7d"), or "forever".
+func ParseRules(spec string) ([]Rule, error) {
+	var rules []Rule
+	for _, part := range strings.Split(spec, ",") {
+		scope, window, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return nil, fmt.Errorf("dedup: %q is not scope=window", part)
+		}
+		if scope != ScopeURL && scope != ScopeContent && scope != ScopeSimhash {
+			return nil, fmt.Errorf("dedup: unknown scope %q (want url, content, or simhash)", scope)
+		}
+		d, err := parseWindow(window)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, Rule{Scope: scope, Window: d})
+	}
+	return rules, nil
+}
+
+// parseWindow parses a rule's window.
+func parseWindow(s string) (time.Duration, error) {
+	if s == "forever" {
+		return 0, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("dedup: invalid window %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("dedup: invalid window %q", s)
+	}
+	return d, nil
+}
+
+// entry is an article remembered by the index.
+type entry struct {
+	URL string `json:"url"`
+	// Tenant is the serve-mode tenant the article was published for; empty without tenants.
+	Tenant      string    `json:"tenant,omitempty"`
+	ContentHash string    `json:"content_hash"`
+	Simhash     uint64    `json:"simhash,string"`
+	Seen        time.Time `json:"seen"`
+}
+
+// Index remembers published articles in a state file so duplicates are recognized across
+// runs. It is safe for concurrent use.
+type Index struct {
+	mu      sync.Mutex
+	path    string
+	rules   []Rule
+	entries []entry
+}
+
+// Open loads the index stored at path, starting empty if the file does not exist yet.
+func Open(path string, rules []Rule) (*Index, error) {
+	idx := &Index{path: path, rules: rules}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return idx, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("dedup: %w", err)
+	}
+	if err := json.Unmarshal(data, &idx.entries); err != nil {
+		return nil, fmt.Errorf("dedup: %s: %w", path, err)
+	}
+	return idx, nil
+}
+
+// Check reports whether the article is a duplicate under any rule, and which scope
+// matched. An article that is not a duplicate is remembered, so later copies of it are.
+// Articles are only compared with others of the same tenant, so that tenants sharing
+// the index neither lose articles to each other nor learn what the others read.
+func (idx *Index) Check(tenant, url, content string, now time.Time) (string, bool, error) {
+	e := entry{URL: url, Tenant: tenant, ContentHash: provenance.HashContent(content), Simhash: Simhash(content), Seen: now.UTC()}
+
+	// Articles without text are only ever compared by URL; they would all match each other.
+	empty := strings.TrimSpace(content) == ""
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for _, r := range idx.rules {
+		if empty && r.Scope != ScopeURL {
+			continue
+		}
+		for _, old := range idx.entries {
+			if old.Tenant != tenant {
+				continue
+			}
+			if r.Window > 0 && now.Sub(old.Seen) > r.Window {
+				continue
+			}
+			if matches(r.Scope, old, e) {
+				return r.Scope, true, nil
+			}
+		}
+	}
+	idx.entries = append(idx.prune(now), e)
+	return "", false, idx.save()
+}
+
+// Forget drops every remembered article whose URL match accepts, and returns how many
+// it dropped.
+func (idx *Index) Forget(match func(url string) bool) (int, error) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	kept := idx.entries[:0]
+	for _, e := range idx.entries {
+		if !match(e.URL) {
+			kept = append(kept, e)
+		}
+	}
+	dropped := len(idx.entries) - len(kept)
+	idx.entries = kept
+	if dropped == 0 {
+		return 0, nil
+	}
+	return dropped, idx.save()
+}
+
+// matches reports whether a and b are the same story in scope.
+func matches(scope string, a, b entry) bool {
+	switch scope {
+	case ScopeURL:
+		return a.URL == b.URL
+	case ScopeContent:
+		return a.ContentHash == b.ContentHash
+	case ScopeSimhash:
+		return bits.OnesCount64(a.Simhash^b.Simhash) <= simhashDistance
+	}
+	return false
+}
+
+// prune drops entries that no rule can match any more.
+func (idx *Index) prune(now time.Time) []entry {
+	var longest time.Duration
+	for _, r := range idx.rules {
+		if r.Window == 0 {
+			return idx.entries
+		}
+		longest = max(longest, r.Window)
+	}
+	kept := idx.entries[:0]
+	for _, e := range idx.entries {
+		if now.Sub(e.Seen) <= longest {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
+// save writes the index to a temporary file and renames it into place.
+func (idx *Index) save() error {
+	data, err := json.Marshal(idx.entries)
+	if err != nil {
+		return fmt.Errorf("dedup: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(idx.path), ".dedup-*.json")
+	if err != nil {
+		return fmt.Errorf("dedup: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("dedup: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("dedup: %w", err)
+	}
+	return os.Rename(tmp.Name(), idx.path)
+}
+
+// Simhash computes a 64-bit similarity hash of text over its consecutive lowercase word
+// pairs, so that texts differing by a few words have hashes differing by a few bits.
+func Simhash(text string) uint64 {
+	var weights [64]int
+	words := strings.Fields(strings.ToLower(text))
+	for i := range words {
+		h := fnv.New64a()
+		h.Write([]byte(words[i]))
+		if i+1 < len(words) {
+			h.Write([]byte{' '})
+			h.Write([]byte(words[i+1]))
+		}
+		sum := h.Sum64()
+		for b := range weights {
+			if sum&(1<<b) != 0 {
+				weights[b]++
+			} else {
+				weights[b]--
+			}
+		}
+	}
+	var hash uint64
+	for i, w := range weights {
+		if w > 0 {
+			hash |= 1 << i
+		}
+	}
+	return hash
+}
@@ -0,0 +1,258 @@
+// Package digest assembles a media-monitoring digest: the articles of a period, grouped
+// into topics by the words they share, each with a short summary, rendered as Markdown,
+// HTML, or an email ready to send.
+package digest
+
+import (
+	"math"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Item is one article in a digest.
+type Item struct {
+	URL       string
+	Title     string
+	Byline    string
+	Authors   []string  // Authors are the names parsed from the byline.
+	Published time.Time // Published is zero when the page does not say.
+	FetchedAt time.Time
+	Content   string
+	// Summary is filled in by Build from the content.
+	Summary string
+}
+
+// Host returns the item's site, for labelling it when the article has no title.
+func (it Item) Host() string {
+	u, err := url.Parse(it.URL)
+	if err != nil {
+		return it.URL
+	}
+	return strings.TrimPrefix(u.Hostname(), "www.")
+}
+
+// Time returns when the item was published, or fetched if the page does not say.
+func (it Item) Time() time.Time {
+	if it.Published.IsZero() {
+		return it.FetchedAt
+	}
+	return it.Published
+}
+
+// Topic is a group of items about the same subject.
+type Topic struct {
+	// Label names the topic by the keywords its articles share.
+	Label string
+	Items []Item
+}
+
+// Digest is the content of one digest, ready to render.
+type Digest struct {
+	Title      string
+	From, To   time.Time
+	Topics     []*Topic
+	TotalItems int
+}
+
+// OtherLabel labels the topic gathering the articles no other article is like.
+const OtherLabel = "Other coverage"
+
+// minSimilarity is how similar, by cosine over tf-idf weighted words, an article must be
+// to a topic to join it.
+const minSimilarity = 0.2
+
+// summaryLength is the longest summary, in characters, before it is cut at a sentence.
+const summaryLength = 320
+
+// Build groups items into topics and summarizes them. Topics are ordered by size, largest
+// first, and items within a topic by publication time, newest first; articles that share a
+// topic with no other article are gathered under OtherLabel at the end.
+func Build(title string, from, to time.Time, items []Item) Digest {
+	d := Digest{Title: title, From: from, To: to, TotalItems: len(items)}
+	vectors := weigh(items)
+
+	type cluster struct {
+		topic    *Topic
+		centroid map[string]float64 // centroid sums the vectors of the topic's items.
+		docs     map[string]int     // docs counts how many of the topic's items use each word.
+	}
+	var clusters []*cluster
+	for i, it := range items {
+		it.Summary = Summarize(it.Content)
+		var best *cluster
+		bestSim := minSimilarity
+		for _, c := range clusters {
+			if sim := cosine(vectors[i], c.centroid); sim >= bestSim {
+				best, bestSim = c, sim
+			}
+		}
+		if best == nil {
+			best = &cluster{topic: &Topic{}, centroid: make(map[string]float64), docs: make(map[string]int)}
+			clusters = append(clusters, best)
+		}
+		best.topic.Items = append(best.topic.Items, it)
+		for w, x := range vectors[i] {
+			best.centroid[w] += x
+			best.docs[w]++
+		}
+	}
+
+	other := &Topic{Label: OtherLabel}
+	for _, c := range clusters {
+		if len(c.topic.Items) == 1 {
+			other.Items = append(other.Items, c.topic.Items...)
+			continue
+		}
+		c.topic.Label = label(c.centroid, c.docs)
+		d.Topics = append(d.Topics, c.topic)
+	}
+	sort.SliceStable(d.Topics, func(i, j int) bool { return len(d.Topics[i].Items) > len(d.Topics[j].Items) })
+	if len(other.Items) > 0 {
+		d.Topics = append(d.Topics, other)
+	}
+	for _, t := range d.Topics {
+		sort.SliceStable(t.Items, func(i, j int) bool { return t.Items[i].Time().After(t.Items[j].Time()) })
+	}
+	return d
+}
+
+// label names a topic by the three heaviest words that more than one of its articles use.
+func label(centroid map[string]float64, docs map[string]int) string {
+	var ranked []string
+	for w := range centroid {
+		if docs[w] > 1 {
+			ranked = append(ranked, w)
+		}
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if centroid[ranked[i]] != centroid[ranked[j]] {
+			return centroid[ranked[i]] > centroid[ranked[j]]
+		}
+		return ranked[i] < ranked[j]
+	})
+	if len(ranked) > 3 {
+		ranked = ranked[:3]
+	}
+	for i, w := range ranked {
+		r, n := utf8.DecodeRuneInString(w)
+		ranked[i] = string(unicode.ToUpper(r)) + w[n:]
+	}
+	return strings.Join(ranked, ", ")
+}
+
+// weigh returns each item's words weighted by tf-idf: frequent in the article, rare
+// across the set, so that what every article mentions counts for little when grouping.
+func weigh(items []Item) []map[string]float64 {
+	counts := make([]map[string]int, len(items))
+	docs := make(map[string]int)
+	for i, it := range items {
+		counts[i] = make(map[string]int)
+		for _, w := range words(it.Content) {
+			if counts[i][w] == 0 {
+				docs[w]++
+			}
+			counts[i][w]++
+		}
+	}
+	vectors := make([]map[string]float64, len(items))
+	for i, c := range counts {
+		vectors[i] = make(map[string]float64, len(c))
+		for w, n := range c {
+			// The smoothed form keeps words all articles share above zero, so a set of
+			// two articles on the same story still groups.
+			vectors[i][w] = float64(n) * math.Log(1+float64(len(items))/float64(docs[w]))
+		}
+	}
+	return vectors
+}
+
+// cosine returns the cosine similarity of two word vectors.
+func cosine(a, b map[string]float64) float64 {
+	var dot, na, nb float64
+	for w, x := range a {
+		dot += x * b[w]
+		na += x * x
+	}
+	for _, y := range b {
+		nb += y * y
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(na*nb)
+}
+
+// words returns the lowercase words of text worth comparing: at least four letters long
+// and not among the commonest English words.
+func words(text string) []string {
+	var out []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	}) {
+		w = strings.Trim(w, "'")
+		if utf8.RuneCountInString(w) >= 4 && !stopwords[w] {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// stopwords are common English words of four letters or more that say nothing about a topic.
+var stopwords = make(map[string]bool)
+
+func init() {
+	for _, w := range strings.Fields(`about above after again against also among another around because
+		been before being below between both came come could does doing down during each even every
+		first from further have having here herself himself into itself just last like made make many
+		more most much must never next only other over said same says should since some still such
+		than that their them themselves then there these they this those through told under until very
+		want were what when where which while whom will with would year years your yours people time
+		according percent including however week`) {
+		stopwords[w] = true
+	}
+}
+
+// Summarize returns the opening sentences of content, up to summaryLength characters, as
+// news articles lead with their most important facts.
+func Summarize(content string) string {
+	text := strings.Join(strings.Fields(content), " ")
+	if utf8.RuneCountInString(text) <= summaryLength {
+		return text
+	}
+	var summary string
+	for rest := text; rest != ""; {
+		i := sentenceEnd(rest)
+		next := summary + rest[:i]
+		if summary != "" && utf8.RuneCountInString(next) > summaryLength {
+			break
+		}
+		summary, rest = next, rest[i:]
+		if utf8.RuneCountInString(summary) > summaryLength {
+			// A single very long first sentence: cut it at a word.
+			cut := string([]rune(summary)[:summaryLength])
+			if sp := strings.LastIndex(cut, " "); sp > 0 {
+				cut = cut[:sp]
+			}
+			return cut + "…"
+		}
+	}
+	return strings.TrimSpace(summary)
+}
+
+// sentenceEnd returns the index just past the first sentence of text, including the
+// space after it.
+func sentenceEnd(text string) int {
+	for i := 0; i < len(text)-1; i++ {
+		switch text[i] {
+		case '.', '!', '?':
+			if text[i+1] == ' ' {
+				return i + 2
+			}
+		}
+	}
+	return len(text)
+}
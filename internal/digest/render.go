@@ -0,0 +1,136 @@
+package digest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"io"
+	"mime"
+	"strings"
+	"time"
+)
+
+// Formats Render can write.
+const (
+	Markdown = "markdown"
+	HTML     = "html"
+	Email    = "email"
+)
+
+// EmailHeader addresses a digest rendered as an email.
+type EmailHeader struct {
+	From string
+	To   []string
+}
+
+// Period describes the digest's time range for headings.
+func (d Digest) Period() string {
+	const layout = "Jan 2, 2006 15:04 MST"
+	return d.From.Format(layout) + " to " + d.To.Format(layout)
+}
+
+// Render writes the digest to w in format. The email format is a complete MIME message,
+// with the Markdown as its plain text part and the HTML as its rich part, that can be
+// piped to sendmail or uploaded to a mail API.
+func Render(w io.Writer, d Digest, format string, header EmailHeader) error {
+	switch format {
+	case Markdown:
+		return renderMarkdown(w, d)
+	case HTML:
+		return htmlTemplate.Execute(w, d)
+	case Email:
+		return renderEmail(w, d, header)
+	}
+	return fmt.Errorf("digest: unknown format %q (want markdown, html, or email)", format)
+}
+
+// renderMarkdown writes the digest as Markdown.
+func renderMarkdown(w io.Writer, d Digest) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n%d articles, %s\n", d.Title, d.TotalItems, d.Period())
+	for _, t := range d.Topics {
+		fmt.Fprintf(&b, "\n## %s (%d)\n", t.Label, len(t.Items))
+		for _, it := range t.Items {
+			if it.Title != "" {
+				fmt.Fprintf(&b, "\n- **[%s](%s)** (%s)", it.Title, it.URL, it.Host())
+			} else {
+				fmt.Fprintf(&b, "\n- **[%s](%s)**", it.Host(), it.URL)
+			}
+			if it.Byline != "" {
+				fmt.Fprintf(&b, " — %s", it.Byline)
+			}
+			if it.Summary != "" {
+				fmt.Fprintf(&b, "\n  %s", it.Summary)
+			}
+			b.WriteString("\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// htmlTemplate renders the digest as a standalone HTML page, with inline styles only so
+// that it survives email clients. The page is in English, but each article's title,
+// byline, and summary take their direction from their own text, so that right-to-left
+// articles read correctly.
+var htmlTemplate = template.Must(template.New("digest").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+</head>
+<body style="font-family: Georgia, serif; max-width: 42em; margin: 0 auto; padding: 1em; color: #222">
+<h1 style="margin-bottom: 0.2em">{{.Title}}</h1>
+<p style="color: #666; margin-top: 0">{{.TotalItems}} articles, {{.Period}}</p>
+{{- range .Topics}}
+<h2 style="border-bottom: 1px solid #ddd; padding-bottom: 0.2em">{{.Label}} ({{len .Items}})</h2>
+<ul style="list-style: none; padding: 0">
+{{- range .Items}}
+<li style="margin-bottom: 1em"><a href="{{.URL}}" style="font-weight: bold">{{if .Title}}<bdi dir="auto">{{.Title}}</bdi></a> ({{.Host}}){{else}}{{.Host}}</a>{{end}}{{if .Byline}} — <bdi dir="auto">{{.Byline}}</bdi>{{end}}
+{{- if .Summary}}<div dir="auto">{{.Summary}}</div>{{end}}</li>
+{{- end}}
+</ul>
+{{- end}}
+</body>
+</html>
+`))
+
+// renderEmail writes the digest as a multipart/alternative email.
+func renderEmail(w io.Writer, d Digest, h EmailHeader) error {
+	var text, html strings.Builder
+	if err := renderMarkdown(&text, d); err != nil {
+		return err
+	}
+	if err := htmlTemplate.Execute(&html, d); err != nil {
+		return err
+	}
+	var nonce [12]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return fmt.Errorf("digest: %w", err)
+	}
+	boundary := "digest-" + hex.EncodeToString(nonce[:])
+
+	var b strings.Builder
+	if h.From != "" {
+		fmt.Fprintf(&b, "From: %s\r\n", h.From)
+	}
+	if len(h.To) > 0 {
+		fmt.Fprintf(&b, "To: %s\r\n", strings.Join(h.To, ", "))
+	}
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", d.Title))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&b, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", boundary)
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain", text.String()},
+		{"text/html", html.String()},
+	} {
+		fmt.Fprintf(&b, "--%s\r\nContent-Type: %s; charset=utf-8\r\nContent-Transfer-Encoding: 8bit\r\n\r\n", boundary, part.contentType)
+		b.WriteString(strings.ReplaceAll(part.body, "\n", "\r\n"))
+		b.WriteString("\r\n")
+	}
+	fmt.Fprintf(&b, "--%s--\r\n", boundary)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,163 @@
+// Package embed finds third-party embeds (YouTube videos, X posts, Instagram posts, and
+// TikTok videos) in an article page and resolves them through the providers' oEmbed
+// endpoints. This gives records a title, author, and thumbnail for each embed, where the
+// page itself holds only an empty iframe or a placeholder blockquote.
+package embed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Providers recognized by Find.
+const (
+	YouTube   = "youtube"
+	X         = "x"
+	Instagram = "instagram"
+	TikTok    = "tiktok"
+)
+
+// Embed is a piece of third-party content embedded in an article.
+type Embed struct {
+	// Provider is one of the provider constants, e.g. "youtube".
+	Provider string `json:"provider" avro:"provider"`
+	// URL is the canonical address of the embedded content.
+	URL string `json:"url" avro:"url"`
+	// The remaining fields come from the provider's oEmbed response and are empty if it
+	// could not be resolved.
+	Type         string `json:"type,omitempty" avro:"type"`
+	Title        string `json:"title,omitempty" avro:"title"`
+	AuthorName   string `json:"author_name,omitempty" avro:"author_name"`
+	AuthorURL    string `json:"author_url,omitempty" avro:"author_url"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty" avro:"thumbnail_url"`
+}
+
+// Find returns the embeds in html, in document order, without duplicates.
+func Find(html string) ([]Embed, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return nil, fmt.Errorf("embed: %w", err)
+	}
+	var embeds []Embed
+	seen := make(map[string]bool)
+	add := func(provider, u string) {
+		if u != "" && !seen[u] {
+			seen[u] = true
+			embeds = append(embeds, Embed{Provider: provider, URL: u})
+		}
+	}
+
+	doc.Find("iframe[src], blockquote.twitter-tweet, blockquote.instagram-media, blockquote.tiktok-embed").Each(func(_ int, s *goquery.Selection) {
+		switch {
+		case s.Is("iframe"):
+			if u := youTubeURL(s.AttrOr("src", "")); u != "" {
+				add(YouTube, u)
+			}
+		case s.HasClass("twitter-tweet"):
+			// The post's own link is the last status link in the placeholder; earlier
+			// ones may point to quoted posts.
+			var post string
+			s.Find("a[href]").Each(func(_ int, a *goquery.Selection) {
+				if href := a.AttrOr("href", ""); strings.Contains(href, "/status/") {
+					post = strings.Split(href, "?")[0]
+				}
+			})
+			add(X, post)
+		case s.HasClass("instagram-media"):
+			add(Instagram, strings.Split(s.AttrOr("data-instgrm-permalink", ""), "?")[0])
+		case s.HasClass("tiktok-embed"):
+			add(TikTok, s.AttrOr("cite", ""))
+		}
+	})
+	return embeds, nil
+}
+
+// youTubeURL turns a YouTube embed iframe address into the video's watch URL, or returns
+// "" if src is not a YouTube embed.
+func youTubeURL(src string) string {
+	if strings.HasPrefix(src, "//") {
+		src = "https:" + src
+	}
+	u, err := url.Parse(src)
+	if err != nil {
+		return ""
+	}
+	host := strings.TrimPrefix(u.Hostname(), "www.")
+	if host != "youtube.com" && host != "youtube-nocookie.com" {
+		return ""
+	}
+	id, ok := strings.CutPrefix(u.Path, "/embed/")
+	if !ok || id == "" || strings.Contains(id, "/") {
+		return ""
+	}
+	return "https://www.youtube.com/watch?v=" + id
+}
+
+// endpoints are the providers' oEmbed endpoints.
+var endpoints = map[string]string{
+	YouTube:   "https://www.youtube.com/oembed",
+	X:         "https://publish.twitter.com/oembed",
+	Instagram: "https://graph.facebook.com/v18.0/instagram_oembed",
+	TikTok:    "https://www.tiktok.com/oembed",
+}
+
+// oembedResponse holds the oEmbed response fields that are kept.
+type oembedResponse struct {
+	Type         string `json:"type"`
+	Title        string `json:"title"`
+	AuthorName   string `json:"author_name"`
+	AuthorURL    string `json:"author_url"`
+	ThumbnailURL string `json:"thumbnail_url"`
+}
+
+// Resolver queries oEmbed endpoints.
+type Resolver struct {
+	client *http.Client
+	// instagramToken is a Meta app access token; Instagram's endpoint refuses requests without one.
+	instagramToken string
+}
+
+// NewResolver creates a resolver that sends requests through transport (the default
+// transport if nil). Instagram embeds are only resolved when instagramToken is set.
+func NewResolver(transport http.RoundTripper, instagramToken string) *Resolver {
+	return &Resolver{client: &http.Client{Transport: transport}, instagramToken: instagramToken}
+}
+
+// Resolve fills in e from its provider's oEmbed endpoint.
+func (r *Resolver) Resolve(ctx context.Context, e *Embed) error {
+	endpoint, ok := endpoints[e.Provider]
+	if !ok {
+		return fmt.Errorf("embed: unknown provider %q", e.Provider)
+	}
+	q := url.Values{"url": {e.URL}, "format": {"json"}}
+	if e.Provider == Instagram {
+		if r.instagramToken == "" {
+			return fmt.Errorf("embed: resolving Instagram embeds requires an access token")
+		}
+		q.Set("access_token", r.instagramToken)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+q.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("embed: %w", err)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("embed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("embed: %s oEmbed returned %s for %s", e.Provider, resp.Status, e.URL)
+	}
+	var o oembedResponse
+	if err := json.NewDecoder(resp.Body).Decode(&o); err != nil {
+		return fmt.Errorf("embed: decoding %s oEmbed response: %w", e.Provider, err)
+	}
+	e.Type, e.Title, e.AuthorName, e.AuthorURL, e.ThumbnailURL = o.Type, o.Title, o.AuthorName, o.AuthorURL, o.ThumbnailURL
+	return nil
+}
@@ -0,0 +1,185 @@
+// Package gnews resolves Google News article links (news.google.com/rss/articles/CBMi…
+// and news.google.com/articles/CBMi…) to the publisher URL they point at, so feeding
+// Google News RSS to the scraper fetches the article rather than Google's wrapper page.
+package gnews
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// batchURL is the endpoint Google News' own pages call to decode an article ID.
+const batchURL = "https://news.google.com/_/DotsSplashUi/data/batchexecute"
+
+// IsWrapper reports whether rawURL is a Google News article link.
+func IsWrapper(rawURL string) bool {
+	_, ok := articleID(rawURL)
+	return ok
+}
+
+// articleID extracts the encoded article ID from a Google News link.
+func articleID(rawURL string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() != "news.google.com" {
+		return "", false
+	}
+	path := strings.TrimPrefix(u.Path, "/rss")
+	for _, prefix := range []string{"/articles/", "/read/"} {
+		if id, ok := strings.CutPrefix(path, prefix); ok && id != "" && !strings.Contains(id, "/") {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// Resolver turns Google News links into publisher URLs.
+type Resolver struct {
+	client *http.Client
+}
+
+// NewResolver creates a resolver that makes any requests it needs through transport
+// (the default transport if nil).
+func NewResolver(transport http.RoundTripper) *Resolver {
+	return &Resolver{client: &http.Client{Transport: transport}}
+}
+
+// Resolve returns the publisher URL behind a Google News link. Links that are not Google
+// News links are returned unchanged.
+//
+// Older article IDs embed the URL directly and are decoded without a request. Newer ones
+// are opaque, so Resolve asks Google News to decode them the same way its own pages do.
+func (r *Resolver) Resolve(ctx context.Context, rawURL string) (string, error) {
+	id, ok := articleID(rawURL)
+	if !ok {
+		return rawURL, nil
+	}
+	if u, ok := decodeOffline(id); ok {
+		return u, nil
+	}
+	sig, ts, err := r.params(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	return r.decodeOnline(ctx, id, sig, ts)
+}
+
+// decodeOffline reads the URL out of an ID that is a base64-encoded protocol buffer holding
+// the URL as a length-prefixed string.
+func decodeOffline(id string) (string, bool) {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(id, "="))
+	if err != nil {
+		return "", false
+	}
+	// The message starts with field 1 (varint) and then field 4 (bytes) holding the URL.
+	rest, ok := strings.CutPrefix(string(data), "\x08\x13\x22")
+	if !ok {
+		return "", false
+	}
+	n, size := binary.Uvarint([]byte(rest))
+	if size <= 0 || uint64(len(rest)-size) < n {
+		return "", false
+	}
+	u := rest[size : size+int(n)]
+	if !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+		// Newer IDs hold an opaque token ("AU_yqL…") here instead of the URL.
+		return "", false
+	}
+	return u, true
+}
+
+// sigPattern and tsPattern find the signature and timestamp Google News puts on its article page.
+var (
+	sigPattern = regexp.MustCompile(`data-n-a-sg="([^"]+)"`)
+	tsPattern  = regexp.MustCompile(`data-n-a-ts="([^"]+)"`)
+)
+
+// params fetches the article's wrapper page for the signature and timestamp needed to decode it.
+func (r *Resolver) params(ctx context.Context, id string) (string, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://news.google.com/articles/"+id, nil)
+	if err != nil {
+		return "", "", fmt.Errorf("gnews: %w", err)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("gnews: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("gnews: article page returned %s", resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", "", fmt.Errorf("gnews: %w", err)
+	}
+	sig, ts := sigPattern.FindSubmatch(body), tsPattern.FindSubmatch(body)
+	if sig == nil || ts == nil {
+		return "", "", errors.New("gnews: article page has no decoding parameters")
+	}
+	return string(sig[1]), string(ts[1]), nil
+}
+
+// decodeOnline calls the batchexecute endpoint to decode id.
+func (r *Resolver) decodeOnline(ctx context.Context, id, sig, ts string) (string, error) {
+	inner := fmt.Sprintf(`["garturlreq",[["X","X",["X","X"],null,null,1,1,"US:en",null,1,null,null,null,null,null,0,1],"X","X",1,[1,1,1],1,1,null,0,0,null,0],%q,%s,%q]`, id, ts, sig)
+	outer, err := json.Marshal([][][]any{{{"Fbv4je", inner, nil, "generic"}}})
+	if err != nil {
+		return "", fmt.Errorf("gnews: %w", err)
+	}
+	form := url.Values{"f.req": {string(outer)}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, batchURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("gnews: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded;charset=UTF-8")
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("gnews: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("gnews: batchexecute returned %s", resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("gnews: %w", err)
+	}
+	return parseBatchResponse(body)
+}
+
+// parseBatchResponse extracts the URL from a batchexecute reply: a ")]}'" guard line, then
+// a JSON array whose first entry carries the result as a JSON-encoded string.
+func parseBatchResponse(body []byte) (string, error) {
+	_, payload, ok := strings.Cut(string(body), "\n\n")
+	if !ok {
+		return "", errors.New("gnews: malformed batchexecute response")
+	}
+	var envelope [][]any
+	if err := json.NewDecoder(strings.NewReader(payload)).Decode(&envelope); err != nil {
+		return "", fmt.Errorf("gnews: %w", err)
+	}
+	if len(envelope) == 0 || len(envelope[0]) < 3 {
+		return "", errors.New("gnews: empty batchexecute response")
+	}
+	encoded, _ := envelope[0][2].(string)
+	var result []any
+	if err := json.Unmarshal([]byte(encoded), &result); err != nil {
+		return "", fmt.Errorf("gnews: %w", err)
+	}
+	if len(result) < 2 {
+		return "", errors.New("gnews: batchexecute did not return a URL")
+	}
+	u, _ := result[1].(string)
+	if u == "" {
+		return "", errors.New("gnews: batchexecute did not return a URL")
+	}
+	return u, nil
+}
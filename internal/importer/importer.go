@@ -0,0 +1,199 @@
+// Package importer reads reading lists exported from other tools (browser bookmarks,
+// Pocket, CSV files) and turns them into a normalized, de-duplicated list of URLs.
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"html"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// hrefPattern matches the HREF attribute used by the Netscape bookmark file format,
+// which both browser bookmark exports and the classic Pocket export use. The value is
+// HTML-escaped, so "&" in a query string appears as "&amp;".
+var hrefPattern = regexp.MustCompile(`(?i)<a\s[^>]*href="([^"]+)"`)
+
+// trackingParams are query parameters that identify a referral rather than a page.
+var trackingParams = []string{"utm_", "fbclid", "gclid", "mc_cid", "mc_eid", "ocid", "cmpid"}
+
+// ReadFile extracts URLs from a bookmarks HTML export, a Pocket export, a CSV file, or a
+// plain list with one URL per line. The format is detected from the file contents.
+func ReadFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(bytes.ToUpper(trimmed), []byte("<!DOCTYPE NETSCAPE-BOOKMARK-FILE")),
+		bytes.Contains(bytes.ToLower(trimmed[:min(len(trimmed), 512)]), []byte("<html")):
+		return readBookmarks(data), nil
+	case bytes.ContainsRune(firstLine(trimmed), ','):
+		urls, err := readCSV(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return urls, nil
+	default:
+		return readLines(bytes.NewReader(data)), nil
+	}
+}
+
+// readBookmarks collects every link in a Netscape-format bookmark file.
+func readBookmarks(data []byte) []string {
+	var urls []string
+	for _, m := range hrefPattern.FindAllSubmatch(data, -1) {
+		urls = append(urls, html.UnescapeString(string(m[1])))
+	}
+	return urls
+}
+
+// readCSV reads the "url" column of a CSV file with a header row (as in the Pocket CSV
+// export), or the first column that holds a URL if there is no such header.
+func readCSV(r io.Reader) ([]string, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	col := -1
+	for i, h := range records[0] {
+		if strings.EqualFold(strings.TrimSpace(h), "url") {
+			col = i
+			break
+		}
+	}
+
+	var urls []string
+	for _, rec := range records {
+		if col >= 0 {
+			if col < len(rec) {
+				urls = append(urls, rec[col])
+			}
+			continue
+		}
+		for _, field := range rec {
+			if isHTTPURL(field) {
+				urls = append(urls, field)
+				break
+			}
+		}
+	}
+	return urls, nil
+}
+
+// readLines reads one URL per line, ignoring blank lines and "#" comments.
+func readLines(r io.Reader) []string {
+	var urls []string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			urls = append(urls, line)
+		}
+	}
+	return urls
+}
+
+// Normalize canonicalizes a URL so trivially different spellings of the same page compare
+// equal: the scheme and host are lowercased, fragments and tracking parameters are dropped,
+// and a trailing slash is removed. It returns false for anything that is not an http(s) URL.
+func Normalize(raw string) (string, bool) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", false
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	q := u.Query()
+	for key := range q {
+		for _, p := range trackingParams {
+			if strings.HasPrefix(strings.ToLower(key), p) {
+				q.Del(key)
+			}
+		}
+	}
+	// Encode sorts the keys, so parameter order no longer matters either.
+	u.RawQuery = q.Encode()
+
+	if len(u.Path) > 1 {
+		u.Path = strings.TrimSuffix(u.Path, "/")
+		u.RawPath = ""
+	}
+	return u.String(), true
+}
+
+// Dedupe returns the urls whose normalized form is not already in seen, in first-seen
+// order, and adds that form to seen. The URLs are returned as given, since some sites
+// redirect or 404 on the normalized form; it only decides what counts as the same page.
+func Dedupe(urls []string, seen map[string]bool) []string {
+	var out []string
+	for _, raw := range urls {
+		n, ok := Normalize(raw)
+		if !ok || seen[n] {
+			continue
+		}
+		seen[n] = true
+		out = append(out, strings.TrimSpace(raw))
+	}
+	return out
+}
+
+// LoadSeen reads a list of previously imported URLs, one per line.
+// A missing file is treated as an empty list.
+func LoadSeen(path string) (map[string]bool, error) {
+	seen := make(map[string]bool)
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return seen, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	for _, u := range readLines(f) {
+		seen[u] = true
+	}
+	return seen, nil
+}
+
+// SaveSeen writes the seen list back to path, sorted so the file diffs cleanly.
+func SaveSeen(path string, seen map[string]bool) error {
+	urls := make([]string, 0, len(seen))
+	for u := range seen {
+		urls = append(urls, u)
+	}
+	sort.Strings(urls)
+	return os.WriteFile(path, []byte(strings.Join(urls, "\n")+"\n"), 0o644)
+}
+
+// isHTTPURL reports whether s looks like an absolute http(s) URL.
+func isHTTPURL(s string) bool {
+	s = strings.TrimSpace(s)
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+// firstLine returns data up to the first newline.
+func firstLine(data []byte) []byte {
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		return data[:i]
+	}
+	return data
+}
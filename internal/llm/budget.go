@@ -0,0 +1,135 @@
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrBudget is returned by Extract when a call could take usage past the run or day budget.
+var ErrBudget = errors.New("llm: budget exhausted")
+
+// Usage is what model calls have consumed.
+type Usage struct {
+	Calls        int     `json:"calls"`
+	Cached       int     `json:"cached"` // Cached counts replies served from the cache, which cost nothing.
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	Cost         float64 `json:"cost"`
+}
+
+// Tokens returns the input and output tokens together.
+func (u Usage) Tokens() int { return u.InputTokens + u.OutputTokens }
+
+// add returns the sum of u and v.
+func (u Usage) add(v Usage) Usage {
+	return Usage{
+		Calls:        u.Calls + v.Calls,
+		Cached:       u.Cached + v.Cached,
+		InputTokens:  u.InputTokens + v.InputTokens,
+		OutputTokens: u.OutputTokens + v.OutputTokens,
+		Cost:         u.Cost + v.Cost,
+	}
+}
+
+// String summarizes u for logs.
+func (u Usage) String() string {
+	return fmt.Sprintf("%d calls (%d cached), %d input and %d output tokens, $%.4f",
+		u.Calls, u.Cached, u.InputTokens, u.OutputTokens, u.Cost)
+}
+
+// Prices are what the provider charges, in dollars per million tokens.
+type Prices struct {
+	Input, Output float64
+}
+
+// cost returns the price of a call with the given token counts.
+func (p Prices) cost(input, output int) float64 {
+	return (float64(input)*p.Input + float64(output)*p.Output) / 1e6
+}
+
+// Budget caps usage. Zero fields mean no cap.
+type Budget struct {
+	Tokens int
+	Cost   float64
+}
+
+// allows reports whether usage u plus a call estimated at tokens and cost stays within b.
+func (b Budget) allows(u Usage, tokens int, cost float64) bool {
+	if b.Tokens > 0 && u.Tokens()+tokens > b.Tokens {
+		return false
+	}
+	if b.Cost > 0 && u.Cost+cost > b.Cost {
+		return false
+	}
+	return true
+}
+
+// Ledger records usage per calendar day (UTC) in a state file, so the day budget holds
+// across runs. It is safe for concurrent use.
+type Ledger struct {
+	mu   sync.Mutex
+	path string
+	days map[string]Usage
+}
+
+// OpenLedger loads the ledger stored at path, starting empty if the file does not exist yet.
+func OpenLedger(path string) (*Ledger, error) {
+	l := &Ledger{path: path, days: make(map[string]Usage)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("llm: %w", err)
+	}
+	if err := json.Unmarshal(data, &l.days); err != nil {
+		return nil, fmt.Errorf("llm: %s: %w", path, err)
+	}
+	return l, nil
+}
+
+// day is the ledger key for t.
+func day(t time.Time) string { return t.UTC().Format(time.DateOnly) }
+
+// Day returns the usage recorded on t's day.
+func (l *Ledger) Day(t time.Time) Usage {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.days[day(t)]
+}
+
+// add records u on t's day and saves the ledger.
+func (l *Ledger) add(t time.Time, u Usage) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.days[day(t)] = l.days[day(t)].add(u)
+
+	data, err := json.MarshalIndent(l.days, "", "  ")
+	if err != nil {
+		return fmt.Errorf("llm: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(l.path), ".llm-usage-*.json")
+	if err != nil {
+		return fmt.Errorf("llm: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("llm: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("llm: %w", err)
+	}
+	return os.Rename(tmp.Name(), l.path)
+}
+
+// estimateTokens guesses the tokens in text before it is sent, at the usual four
+// characters a token for English.
+func estimateTokens(text string) int {
+	return len([]rune(text))/4 + 1
+}
@@ -0,0 +1,406 @@
+// Package llm recovers article fields with a language model when rule-based extraction
+// comes back empty or doubtful. The page's visible text is sent to an OpenAI-compatible
+// chat completions endpoint with a JSON schema the reply must follow. Calls are capped,
+// spaced out, budgeted per run and per day, and cached, so a bad batch cannot run up an
+// unbounded bill.
+package llm
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// ErrLimit is returned by Extract once the client has made its maximum number of calls.
+var ErrLimit = errors.New("llm: call limit reached")
+
+// Result holds the fields the model recovered. Fields it could not find are empty.
+type Result struct {
+	Title     string `json:"title"`
+	Author    string `json:"author"`
+	Published string `json:"published"` // Published is the publication date as the page gives it.
+	Body      string `json:"body"`
+}
+
+// Limits bound what a client may spend.
+type Limits struct {
+	// MaxCalls is the most requests the client makes in its lifetime. Zero means no limit.
+	MaxCalls int
+	// MinInterval is the least time between the starts of two requests.
+	MinInterval time.Duration
+	// MaxInputChars truncates the page text sent with each request, which bounds the
+	// input tokens paid for per page. Zero means no truncation.
+	MaxInputChars int
+	// Run and Day cap the tokens and cost of the client's lifetime and of each UTC day.
+	// They are checked before every call against an estimate of its input, so the last
+	// call of a run or day can overshoot them by its output. Day needs a Ledger.
+	Run, Day Budget
+}
+
+// Config configures a Client.
+type Config struct {
+	// Endpoint is a chat completions URL such as "https://api.openai.com/v1/chat/completions".
+	Endpoint string
+	Model    string
+	// APIKey is sent as a bearer token unless it is empty, as local servers usually need none.
+	APIKey string
+	Limits Limits
+	// Prices turn token counts into the costs budgets and reports are given in.
+	Prices Prices
+	// Ledger, if set, records usage per day across runs.
+	Ledger *Ledger
+	// CacheDir, if set, keeps every reply, so a page seen again costs nothing.
+	CacheDir string
+}
+
+// Presets are the chat completions URLs of local model servers at their default ports, so
+// an endpoint can be given by name.
+var Presets = map[string]string{
+	"ollama":    "http://localhost:11434/v1/chat/completions",
+	"llama.cpp": "http://localhost:8080/v1/chat/completions",
+}
+
+// ResolveEndpoint turns an endpoint setting into a chat completions URL: a Presets name
+// becomes its URL, and a base URL ending in /v1, as servers usually document theirs,
+// gets the chat completions path appended. Anything else is returned unchanged.
+func ResolveEndpoint(endpoint string) string {
+	if u, ok := Presets[endpoint]; ok {
+		return u
+	}
+	if trimmed := strings.TrimSuffix(endpoint, "/"); strings.HasSuffix(trimmed, "/v1") {
+		return trimmed + "/chat/completions"
+	}
+	return endpoint
+}
+
+// IsLocal reports whether endpoint is served from this machine, so page text sent to it
+// does not leave it: its host must be "localhost" or a loopback address.
+func IsLocal(endpoint string) (bool, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return false, fmt.Errorf("llm: %w", err)
+	}
+	host := u.Hostname()
+	if host == "localhost" {
+		return true, nil
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback(), nil
+}
+
+// Client sends extraction requests to one model. It is safe for concurrent use.
+type Client struct {
+	client *http.Client
+	cfg    Config
+
+	mu       sync.Mutex
+	usage    Usage
+	requests int // requests counts calls started, including failed ones, for MaxCalls.
+	last     time.Time
+}
+
+// NewClient creates a client whose requests go through transport (the default transport
+// if nil).
+func NewClient(transport http.RoundTripper, cfg Config) *Client {
+	return &Client{client: &http.Client{Transport: transport}, cfg: cfg}
+}
+
+// Usage returns what the client has consumed so far.
+func (c *Client) Usage() Usage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.usage
+}
+
+// Model returns the name of the model the client uses, for recording where fields came from.
+func (c *Client) Model() string { return c.cfg.Model }
+
+// prompt tells the model what to return. The schema enforces the shape; the prompt asks
+// for copying rather than writing, because a summarized body is worse than none.
+const prompt = `You extract news articles from web page text. Return the article's headline, its author names as written in the byline, its publication date as written on the page, and its full body text copied verbatim, one paragraph per line. Leave out navigation, advertisements, related links, comments, and newsletter prompts. Use an empty string for anything the page does not contain; never guess.`
+
+// resultSchema is the JSON schema the reply must follow.
+var resultSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"title":     map[string]string{"type": "string"},
+		"author":    map[string]string{"type": "string"},
+		"published": map[string]string{"type": "string"},
+		"body":      map[string]string{"type": "string"},
+	},
+	"required":             []string{"title", "author", "published", "body"},
+	"additionalProperties": false,
+}
+
+// chatResponse holds the parts of a chat completions response that are used.
+type chatResponse struct {
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+// Extract asks the model for the article in text, the visible text of the page at url.
+func (c *Client) Extract(ctx context.Context, url, text string) (Result, error) {
+	if n := c.cfg.Limits.MaxInputChars; n > 0 && len([]rune(text)) > n {
+		text = string([]rune(text)[:n])
+	}
+	key := c.cacheKey(url, text)
+	if r, ok := c.cached(key); ok {
+		c.record(Usage{Cached: 1})
+		return r, nil
+	}
+	if err := c.wait(ctx, estimateTokens(prompt)+estimateTokens(text)); err != nil {
+		return Result{}, err
+	}
+	r, err := c.call(ctx, url, text)
+	if err != nil {
+		return Result{}, err
+	}
+	c.store(key, url, r)
+	return r, nil
+}
+
+// call sends one request and records its usage.
+func (c *Client) call(ctx context.Context, url, text string) (Result, error) {
+	body, err := json.Marshal(map[string]any{
+		"model": c.cfg.Model,
+		"messages": []map[string]string{
+			{"role": "system", "content": prompt},
+			{"role": "user", "content": "URL: " + url + "\n\n" + text},
+		},
+		"temperature": 0,
+		"response_format": map[string]any{
+			"type":        "json_schema",
+			"json_schema": map[string]any{"name": "article", "strict": true, "schema": resultSchema},
+		},
+	})
+	if err != nil {
+		return Result{}, fmt.Errorf("llm: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return Result{}, fmt.Errorf("llm: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.APIKey)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("llm: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Result{}, fmt.Errorf("llm: %s returned %s", c.cfg.Endpoint, resp.Status)
+	}
+	var chat chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&chat); err != nil {
+		return Result{}, fmt.Errorf("llm: decoding response: %w", err)
+	}
+	in, out := chat.Usage.PromptTokens, chat.Usage.CompletionTokens
+	c.record(Usage{Calls: 1, InputTokens: in, OutputTokens: out, Cost: c.cfg.Prices.cost(in, out)})
+	if len(chat.Choices) == 0 {
+		return Result{}, fmt.Errorf("llm: response has no choices")
+	}
+	var r Result
+	if err := json.Unmarshal([]byte(chat.Choices[0].Message.Content), &r); err != nil {
+		return Result{}, fmt.Errorf("llm: reply is not the requested JSON: %w", err)
+	}
+	// A model that ignores the prompt can still invent fields; keep only what the page
+	// actually says.
+	if !grounded(r.Title, text) {
+		r.Title = ""
+	}
+	if !grounded(r.Author, text) {
+		r.Author = ""
+	}
+	if !grounded(r.Published, text) {
+		r.Published = ""
+	}
+	if !grounded(firstLine(r.Body), text) {
+		r.Body = ""
+	}
+	return r, nil
+}
+
+// wait enforces the call limit, the budgets, and the interval between calls before a
+// call whose input is estimated at tokens.
+func (c *Client) wait(ctx context.Context, tokens int) error {
+	now := time.Now()
+	var today Usage
+	if c.cfg.Ledger != nil {
+		today = c.cfg.Ledger.Day(now)
+	}
+	cost := c.cfg.Prices.cost(tokens, 0)
+
+	c.mu.Lock()
+	if n := c.cfg.Limits.MaxCalls; n > 0 && c.requests >= n {
+		c.mu.Unlock()
+		return ErrLimit
+	}
+	if !c.cfg.Limits.Run.allows(c.usage, tokens, cost) || !c.cfg.Limits.Day.allows(today, tokens, cost) {
+		c.mu.Unlock()
+		return ErrBudget
+	}
+	c.requests++
+	start := now
+	if next := c.last.Add(c.cfg.Limits.MinInterval); next.After(start) {
+		start = next
+	}
+	c.last = start
+	c.mu.Unlock()
+
+	select {
+	case <-time.After(time.Until(start)):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// record adds u to the client's usage and to the ledger.
+func (c *Client) record(u Usage) {
+	c.mu.Lock()
+	c.usage = c.usage.add(u)
+	c.mu.Unlock()
+	if c.cfg.Ledger != nil {
+		if err := c.cfg.Ledger.add(time.Now(), u); err != nil {
+			// Losing a ledger update only loosens the day budget; the reply is still good.
+			log.Printf("Error recording LLM usage: %v", err)
+		}
+	}
+}
+
+// cacheKey identifies a request by everything that shapes its reply.
+func (c *Client) cacheKey(url, text string) string {
+	sum := sha256.Sum256([]byte(c.cfg.Model + "\x00" + prompt + "\x00" + url + "\x00" + text))
+	return hex.EncodeToString(sum[:16])
+}
+
+// cached returns the reply cached under key, if there is one.
+func (c *Client) cached(key string) (Result, bool) {
+	if c.cfg.CacheDir == "" {
+		return Result{}, false
+	}
+	data, err := os.ReadFile(filepath.Join(c.cfg.CacheDir, key+".json"))
+	if err != nil {
+		return Result{}, false
+	}
+	var r Result
+	if json.Unmarshal(data, &r) != nil {
+		return Result{}, false
+	}
+	return r, true
+}
+
+// cacheEntry is a cached reply, with the URL of the page it is for.
+type cacheEntry struct {
+	// URL is empty in replies cached before URLs were kept with them.
+	URL string `json:"url,omitempty"`
+	Result
+}
+
+// store caches r, the reply for url, under key. Failures only cost a repeated call later,
+// so they are logged.
+func (c *Client) store(key, url string, r Result) {
+	if c.cfg.CacheDir == "" {
+		return
+	}
+	data, err := json.Marshal(cacheEntry{URL: url, Result: r})
+	if err == nil {
+		err = os.MkdirAll(c.cfg.CacheDir, 0o755)
+	}
+	if err == nil {
+		err = os.WriteFile(filepath.Join(c.cfg.CacheDir, key+".json"), data, 0o644)
+	}
+	if err != nil {
+		log.Printf("Error caching LLM reply: %v", err)
+	}
+}
+
+// ForgetCached deletes the replies cached in dir, a Config.CacheDir, for every URL that
+// match accepts, and returns how many it deleted. Replies cached without their URL are
+// deleted too, as there is no telling which page they are for.
+func ForgetCached(dir string, match func(url string) bool) (int, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return 0, fmt.Errorf("llm: %w", err)
+	}
+	var n int
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return n, fmt.Errorf("llm: %w", err)
+		}
+		var e cacheEntry
+		if json.Unmarshal(data, &e) == nil && e.URL != "" && !match(e.URL) {
+			continue
+		}
+		if err := os.Remove(f); err != nil {
+			return n, fmt.Errorf("llm: %w", err)
+		}
+		n++
+	}
+	return n, nil
+}
+
+// grounded reports whether value appears in text, ignoring case and spacing. Empty
+// values are trivially grounded.
+func grounded(value, text string) bool {
+	value = strings.ToLower(strings.Join(strings.Fields(value), " "))
+	return strings.Contains(strings.ToLower(strings.Join(strings.Fields(text), " ")), value)
+}
+
+// firstLine returns the first line of s.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
+
+// PageText returns the visible text of an HTML page, without scripts, styles, and
+// navigation, one block per line. It is what Extract should be given: far smaller than
+// the markup, so cheaper to send, and without the markup's distractions.
+func PageText(html string) (string, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return "", fmt.Errorf("llm: %w", err)
+	}
+	doc.Find("script, style, noscript, template, svg, iframe, nav, form").Remove()
+	var lines []string
+	doc.Find("title, h1, h2, h3, h4, h5, h6, p, li, blockquote, figcaption, time, address, td").Each(func(_ int, s *goquery.Selection) {
+		// Nested blocks would otherwise be sent twice.
+		if s.ParentsFiltered("p, li, blockquote, figcaption, td").Length() > 0 {
+			return
+		}
+		if line := strings.Join(strings.Fields(s.Text()), " "); line != "" {
+			lines = append(lines, line)
+		}
+	})
+	doc.Find("meta[name=author], meta[property='article:published_time']").Each(func(_ int, s *goquery.Selection) {
+		if v := s.AttrOr("content", ""); v != "" {
+			lines = append(lines, v)
+		}
+	})
+	return strings.Join(lines, "\n"), nil
+}
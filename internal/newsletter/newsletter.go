@@ -0,0 +1,182 @@
+// Package newsletter lists every back issue of a newsletter from its public archive, so a
+// newsletter's history can be backfilled in one run. Substack, Mailchimp, and Buttondown
+// archives are recognized; other archive pages are crawled by following their pagination.
+package newsletter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// substackPageSize is how many posts are requested per Substack archive API call.
+const substackPageSize = 50
+
+// maxPages stops a runaway crawl of an archive whose pagination never ends.
+const maxPages = 500
+
+// Crawler lists newsletter issues.
+type Crawler struct {
+	client *http.Client
+}
+
+// NewCrawler creates a crawler that fetches archive pages through transport (the default
+// transport if nil).
+func NewCrawler(transport http.RoundTripper) *Crawler {
+	return &Crawler{client: &http.Client{Transport: transport}}
+}
+
+// Issues returns the URLs of every issue in the archive at archiveURL, newest first where
+// the platform orders them that way, without duplicates.
+func (c *Crawler) Issues(ctx context.Context, archiveURL string) ([]string, error) {
+	u, err := url.Parse(archiveURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("newsletter: %q is not an absolute URL", archiveURL)
+	}
+	host := strings.ToLower(u.Hostname())
+	switch {
+	case strings.HasSuffix(host, ".substack.com"):
+		return c.substack(ctx, u)
+	case strings.HasSuffix(host, "campaign-archive.com") || strings.HasSuffix(host, "list-manage.com"):
+		// Mailchimp lists every campaign on one page.
+		return c.crawl(ctx, u, func(link *url.URL) bool {
+			return strings.HasSuffix(link.Hostname(), "campaign-archive.com") && link.Query().Get("id") != "" &&
+				!strings.HasPrefix(link.Path, "/home")
+		})
+	case host == "buttondown.com" || host == "buttondown.email":
+		// Issues live under /<newsletter>/archive/<slug>/.
+		prefix := strings.TrimSuffix(u.Path, "/") + "/"
+		return c.crawl(ctx, u, func(link *url.URL) bool {
+			return link.Host == u.Host && strings.HasPrefix(link.Path, prefix) && strings.Trim(link.Path[len(prefix):], "/") != ""
+		})
+	}
+
+	// Substack newsletters on custom domains still answer the archive API.
+	if issues, err := c.substack(ctx, u); err == nil && len(issues) > 0 {
+		return issues, nil
+	}
+	// Anything else: links on the same host below the archive's path.
+	prefix := strings.TrimSuffix(u.Path, "/") + "/"
+	return c.crawl(ctx, u, func(link *url.URL) bool {
+		return link.Host == u.Host && strings.HasPrefix(link.Path, prefix) && len(link.Path) > len(prefix)
+	})
+}
+
+// substackPost holds the archive API fields that are used.
+type substackPost struct {
+	CanonicalURL string `json:"canonical_url"`
+}
+
+// substack pages through the Substack archive API, which the (JavaScript-rendered)
+// /archive page itself uses.
+func (c *Crawler) substack(ctx context.Context, u *url.URL) ([]string, error) {
+	var issues []string
+	for offset := 0; offset < maxPages*substackPageSize; offset += substackPageSize {
+		api := fmt.Sprintf("%s://%s/api/v1/archive?sort=new&offset=%d&limit=%d", u.Scheme, u.Host, offset, substackPageSize)
+		body, err := c.get(ctx, api)
+		if err != nil {
+			return nil, err
+		}
+		var posts []substackPost
+		if err := json.Unmarshal(body, &posts); err != nil {
+			return nil, fmt.Errorf("newsletter: decoding Substack archive: %w", err)
+		}
+		if len(posts) == 0 {
+			break
+		}
+		for _, p := range posts {
+			if p.CanonicalURL != "" {
+				issues = append(issues, p.CanonicalURL)
+			}
+		}
+	}
+	return issues, nil
+}
+
+// crawl collects the links accepted by isIssue from start and every page reached through
+// its "next page" links.
+func (c *Crawler) crawl(ctx context.Context, start *url.URL, isIssue func(*url.URL) bool) ([]string, error) {
+	var issues []string
+	seen := make(map[string]bool)
+	visited := make(map[string]bool)
+	for page, pages := start, 0; page != nil && !visited[page.String()] && pages < maxPages; pages++ {
+		visited[page.String()] = true
+		body, err := c.get(ctx, page.String())
+		if err != nil {
+			return nil, err
+		}
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(string(body)))
+		if err != nil {
+			return nil, fmt.Errorf("newsletter: %w", err)
+		}
+		doc.Find("a[href]").Each(func(_ int, a *goquery.Selection) {
+			link, err := page.Parse(a.AttrOr("href", ""))
+			if err != nil || a.Is(`[rel~="next"], [rel~="prev"]`) {
+				return
+			}
+			link.Fragment = ""
+			if s := link.String(); isIssue(link) && !seen[s] && !isPagination(link) {
+				seen[s] = true
+				issues = append(issues, s)
+			}
+		})
+		page = nextPage(doc, page)
+	}
+	return issues, nil
+}
+
+// nextPage finds the link to the archive's next page, if any.
+func nextPage(doc *goquery.Document, page *url.URL) *url.URL {
+	href, ok := doc.Find(`link[rel~="next"], a[rel~="next"]`).First().Attr("href")
+	if !ok {
+		// Buttondown and many templates mark the link by class or text instead.
+		doc.Find("a[href]").EachWithBreak(func(_ int, a *goquery.Selection) bool {
+			text := strings.ToLower(strings.TrimSpace(a.Text()))
+			if a.HasClass("next") || strings.HasPrefix(text, "next") || strings.HasPrefix(text, "older") {
+				href, ok = a.Attr("href")
+				return false
+			}
+			return true
+		})
+	}
+	if !ok {
+		return nil
+	}
+	next, err := page.Parse(href)
+	if err != nil {
+		return nil
+	}
+	return next
+}
+
+// isPagination reports whether link is another archive page rather than an issue.
+func isPagination(link *url.URL) bool {
+	return link.Query().Get("page") != "" || strings.Contains(link.Path, "/page/")
+}
+
+// get fetches rawURL and returns its body.
+func (c *Crawler) get(ctx context.Context, rawURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("newsletter: %w", err)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("newsletter: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("newsletter: %s returned %s", rawURL, resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("newsletter: %w", err)
+	}
+	return body, nil
+}
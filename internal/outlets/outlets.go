@@ -0,0 +1,146 @@
+// Package outlets joins user-maintained metadata about news sources, such as bias and
+// reliability ratings, country, and ownership, onto the articles scraped from them.
+//
+// The metadata file is JSON or CSV, chosen by its extension. JSON is an array of
+// objects with the Outlet field names:
+//
+//	[{"domain": "apnews.com", "name": "Associated Press", "reliability": "high", "country": "US"}]
+//
+// CSV has a header row naming the same fields, in any order; columns with other names
+// are kept as extras:
+//
+//	domain,name,bias,reliability,country,ownership
+//	apnews.com,Associated Press,center,high,US,nonprofit cooperative
+package outlets
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Outlet is the metadata recorded for one source.
+type Outlet struct {
+	// Domain is the site the metadata applies to, subdomains included, e.g. "apnews.com".
+	Domain      string `json:"domain" avro:"domain"`
+	Name        string `json:"name,omitempty" avro:"name"`
+	Bias        string `json:"bias,omitempty" avro:"bias"`
+	Reliability string `json:"reliability,omitempty" avro:"reliability"`
+	Country     string `json:"country,omitempty" avro:"country"`
+	Ownership   string `json:"ownership,omitempty" avro:"ownership"`
+	// Extra holds any other fields the file gives, for ratings this struct does not name.
+	Extra map[string]string `json:"extra,omitempty" avro:"extra"`
+}
+
+// Directory looks outlets up by the URLs of their articles.
+type Directory struct {
+	byDomain map[string]Outlet // byDomain is keyed by lowercase domain without "www.".
+}
+
+// Load reads a metadata file: CSV if its name ends in .csv, otherwise JSON.
+func Load(path string) (*Directory, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("outlets: %w", err)
+	}
+	defer f.Close()
+	var list []Outlet
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		list, err = readCSV(f)
+	} else {
+		err = json.NewDecoder(f).Decode(&list)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("outlets: reading %s: %w", path, err)
+	}
+
+	d := &Directory{byDomain: make(map[string]Outlet, len(list))}
+	for i, o := range list {
+		domain := normalizeDomain(o.Domain)
+		if domain == "" {
+			return nil, fmt.Errorf("outlets: entry %d of %s has no domain", i+1, path)
+		}
+		if _, dup := d.byDomain[domain]; dup {
+			return nil, fmt.Errorf("outlets: %s lists %s twice", path, domain)
+		}
+		o.Domain = domain
+		d.byDomain[domain] = o
+	}
+	return d, nil
+}
+
+// readCSV reads outlets from CSV with a header row.
+func readCSV(r io.Reader) ([]Outlet, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	header := rows[0]
+	for i, h := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(h))
+	}
+	var list []Outlet
+	for _, row := range rows[1:] {
+		var o Outlet
+		for i, value := range row {
+			value = strings.TrimSpace(value)
+			switch header[i] {
+			case "domain":
+				o.Domain = value
+			case "name":
+				o.Name = value
+			case "bias":
+				o.Bias = value
+			case "reliability":
+				o.Reliability = value
+			case "country":
+				o.Country = value
+			case "ownership":
+				o.Ownership = value
+			default:
+				if value == "" {
+					continue
+				}
+				if o.Extra == nil {
+					o.Extra = make(map[string]string)
+				}
+				o.Extra[header[i]] = value
+			}
+		}
+		list = append(list, o)
+	}
+	return list, nil
+}
+
+// Lookup returns the metadata of the outlet rawURL belongs to: its host's entry, or its
+// closest parent domain's. It reports false if no entry covers the URL.
+func (d *Directory) Lookup(rawURL string) (Outlet, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return Outlet{}, false
+	}
+	for host := normalizeDomain(u.Hostname()); host != ""; {
+		if o, ok := d.byDomain[host]; ok {
+			return o, true
+		}
+		_, parent, ok := strings.Cut(host, ".")
+		if !ok {
+			break
+		}
+		host = parent
+	}
+	return Outlet{}, false
+}
+
+// normalizeDomain lowercases a domain and drops a leading "www.".
+func normalizeDomain(domain string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "www.")
+}
@@ -0,0 +1,257 @@
+// Package paywall keeps track of which sites serve paywalled or truncated articles, so
+// that a monitor can stop spending its fetches on sources it cannot read.
+package paywall
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Actions a Policy can take on a site that is flagged as paywalled.
+const (
+	// ActionReport only records and reports the site's paywall rate.
+	ActionReport = "report"
+	// ActionDeprioritize scrapes the site's URLs after everyone else's.
+	ActionDeprioritize = "deprioritize"
+	// ActionSkip does not fetch the site's URLs at all, apart from an occasional recheck.
+	ActionSkip = "skip"
+)
+
+// recentResults is how many of a site's latest articles its paywall rate is taken over,
+// so that a site that drops its paywall, or adds one, is noticed.
+const recentResults = 20
+
+// Policy decides when a site counts as paywalled and what is done about it.
+type Policy struct {
+	Action string
+	// Threshold is the paywall rate, from 0 to 1, at or above which a site is flagged.
+	Threshold float64
+	// MinSamples is how many of a site's articles must have been seen before it can be flagged.
+	MinSamples int
+	// Recheck is how long after its last fetch a skipped site gets one URL through, to
+	// find out whether it is still paywalled. Zero skips it for good.
+	Recheck time.Duration
+}
+
+// Site is what is known about one site's articles.
+type Site struct {
+	Domain    string `json:"domain"`
+	Scraped   int    `json:"scraped"`
+	Paywalled int    `json:"paywalled"`
+	// Recent holds whether each of the latest articles was paywalled, oldest first.
+	Recent      []bool    `json:"recent"`
+	LastChecked time.Time `json:"last_checked"`
+}
+
+// Rate is the share of the site's recent articles that were paywalled.
+func (s Site) Rate() float64 {
+	if len(s.Recent) == 0 {
+		return 0
+	}
+	var n int
+	for _, p := range s.Recent {
+		if p {
+			n++
+		}
+	}
+	return float64(n) / float64(len(s.Recent))
+}
+
+// Flagged reports whether the policy treats the site as paywalled.
+func (p Policy) Flagged(s Site) bool {
+	return len(s.Recent) >= p.MinSamples && len(s.Recent) > 0 && s.Rate() >= p.Threshold
+}
+
+// Tracker records every scraped article's paywall status by site in a state file. It is
+// safe for concurrent use.
+type Tracker struct {
+	policy Policy
+
+	mu    sync.Mutex
+	path  string
+	sites map[string]*Site
+}
+
+// Open loads the tracker state stored at path, starting empty if the file does not exist yet.
+func Open(path string, policy Policy) (*Tracker, error) {
+	t := &Tracker{policy: policy, path: path, sites: make(map[string]*Site)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return t, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("paywall: %w", err)
+	}
+	if err := json.Unmarshal(data, &t.sites); err != nil {
+		return nil, fmt.Errorf("paywall: %s: %w", path, err)
+	}
+	for domain, s := range t.sites {
+		s.Domain = domain
+	}
+	return t, nil
+}
+
+// Policy returns the policy the tracker was opened with.
+func (t *Tracker) Policy() Policy {
+	return t.policy
+}
+
+// domain is the tracker key for rawURL: its host without "www.".
+func domain(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// Flagged reports whether rawURL's site is flagged as paywalled.
+func (t *Tracker) Flagged(rawURL string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.sites[domain(rawURL)]
+	return ok && t.policy.Flagged(*s)
+}
+
+// Skip reports whether rawURL should not be fetched under the skip action: its site is
+// flagged and not yet due a recheck. A URL let through for a recheck moves the site's
+// next recheck on, so that concurrent scrapes do not all go through at once.
+func (t *Tracker) Skip(rawURL string, now time.Time) bool {
+	if t.policy.Action != ActionSkip {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.sites[domain(rawURL)]
+	if !ok || !t.policy.Flagged(*s) {
+		return false
+	}
+	if t.policy.Recheck > 0 && now.Sub(s.LastChecked) >= t.policy.Recheck {
+		s.LastChecked = now
+		return false
+	}
+	return true
+}
+
+// Record notes whether the article at rawURL was paywalled and saves the state.
+func (t *Tracker) Record(rawURL string, paywalled bool, now time.Time) error {
+	d := domain(rawURL)
+	if d == "" {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.sites[d]
+	if !ok {
+		s = &Site{Domain: d}
+		t.sites[d] = s
+	}
+	s.Scraped++
+	if paywalled {
+		s.Paywalled++
+	}
+	s.Recent = append(s.Recent, paywalled)
+	if len(s.Recent) > recentResults {
+		s.Recent = s.Recent[len(s.Recent)-recentResults:]
+	}
+	s.LastChecked = now
+	return t.save()
+}
+
+// save writes the state file atomically. t.mu must be held.
+func (t *Tracker) save() error {
+	data, err := json.MarshalIndent(t.sites, "", "  ")
+	if err != nil {
+		return fmt.Errorf("paywall: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(t.path), ".paywall-*.json")
+	if err != nil {
+		return fmt.Errorf("paywall: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("paywall: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("paywall: %w", err)
+	}
+	return os.Rename(tmp.Name(), t.path)
+}
+
+// Sites returns every site seen, highest paywall rate first.
+func (t *Tracker) Sites() []Site {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	sites := make([]Site, 0, len(t.sites))
+	for _, s := range t.sites {
+		sites = append(sites, *s)
+	}
+	sort.Slice(sites, func(i, j int) bool {
+		if sites[i].Rate() != sites[j].Rate() {
+			return sites[i].Rate() > sites[j].Rate()
+		}
+		return sites[i].Domain < sites[j].Domain
+	})
+	return sites
+}
+
+// Detect reports whether an article looks paywalled: its page declares the article not
+// free to read (schema.org isAccessibleForFree), or the extracted content is shorter than
+// minLength characters, as the teaser of a locked article is.
+func Detect(html, content string, minLength int) bool {
+	if utf8.RuneCountInString(strings.TrimSpace(content)) < minLength {
+		return true
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return false
+	}
+	locked := false
+	doc.Find(`script[type="application/ld+json"]`).EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		var data any
+		if json.Unmarshal([]byte(s.Text()), &data) != nil {
+			return true
+		}
+		locked = notFree(data)
+		return !locked
+	})
+	return locked
+}
+
+// notFree reports whether a JSON-LD document marks anything as not accessible for free.
+// The property is a boolean or, on many sites, the string "False".
+func notFree(v any) bool {
+	switch v := v.(type) {
+	case []any:
+		for _, item := range v {
+			if notFree(item) {
+				return true
+			}
+		}
+	case map[string]any:
+		switch free := v["isAccessibleForFree"].(type) {
+		case bool:
+			if !free {
+				return true
+			}
+		case string:
+			if strings.EqualFold(free, "false") {
+				return true
+			}
+		}
+		return notFree(v["@graph"])
+	}
+	return false
+}
@@ -0,0 +1,383 @@
+// Package profile keeps all the tuning for a site in one place: a profiles file lists,
+// per domain, the selectors that read its articles, how politely to request its pages,
+// whether and how to render them, the headers and cookies to send, the language of its
+// bylines, the fallbacks to try when a page comes back empty, and how to sign requests
+// for APIs that want them signed. Each profile applies
+// to its domain and subdomains, the most specific domain winning.
+//
+// The file is a JSON object keyed by domain:
+//
+//	{
+//	  "example.com": {
+//	    "selectors": {"content": "div.story p", "byline": "span.author", "title": "h1", "date": "time"},
+//	    "limits": {"delay": "2s", "random_delay": "1s", "parallelism": 1},
+//	    "render": true,
+//	    "strategy": {"wait_for": "div.story", "scrolls": 3},
+//	    "headers": {"Accept-Language": "de-DE"},
+//	    "cookies": {"consent": "yes"},
+//	    "byline_locale": "de",
+//	    "fallbacks": ["print", "render"]
+//	  },
+//	  "api.partner.example": {
+//	    "signing": {"scheme": "hmac", "key_id": "partner-1", "secret": "env:PARTNER_SECRET"}
+//	  }
+//	}
+//
+// Every file is checked against the JSON Schema returned by Schema before its profiles
+// are used, so that profiles shared between users, or pulled from a community
+// repository with Fetch, cannot bring in keys the scraper would silently ignore.
+package profile
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+
+	"github.com/hail2skins/zero-scraper/internal/browser"
+	"github.com/hail2skins/zero-scraper/internal/byline"
+)
+
+// Fallbacks a profile can list.
+const (
+	// FallbackPrint tries the article's print version when extraction fails or comes back short.
+	FallbackPrint = "print"
+	// FallbackRender renders a page whose plain fetch failed or found no content.
+	FallbackRender = "render"
+)
+
+// Signing schemes a profile can use.
+const (
+	// SigningHMAC signs requests with an HMAC of a shared secret, as in the HTTP
+	// Signatures draft.
+	SigningHMAC = "hmac"
+	// SigningOAuth1 signs requests as an OAuth 1.0a client.
+	SigningOAuth1 = "oauth1"
+)
+
+// Profile is the tuning for one site.
+type Profile struct {
+	// Domain is the site the profile applies to, subdomains included, e.g. "example.com".
+	Domain string `json:"-"`
+	// Selectors read the site's articles, ahead of the configured extractors.
+	Selectors *Selectors `json:"selectors,omitempty"`
+	// Limits throttle requests to each of the site's hosts, in place of the global limits.
+	Limits *Limits `json:"limits,omitempty"`
+	// Render renders every page of the site in the browser rather than fetching it.
+	Render bool `json:"render,omitempty"`
+	// Strategy is what to wait for in rendered pages of the site.
+	Strategy *browser.Strategy `json:"strategy,omitempty"`
+	// Headers are sent with every request to the site.
+	Headers map[string]string `json:"headers,omitempty"`
+	// Cookies are set for the site before the first request, such as a consent cookie.
+	Cookies map[string]string `json:"cookies,omitempty"`
+	// BylineLocale is the language of the site's bylines and of dates written out on its
+	// pages, e.g. "de".
+	BylineLocale string `json:"byline_locale,omitempty"`
+	// Fallbacks are FallbackPrint and FallbackRender, to try for the site's pages when
+	// the configured fallbacks do not already cover them.
+	Fallbacks []string `json:"fallbacks,omitempty"`
+	// Signing signs every request to the site.
+	Signing *Signing `json:"signing,omitempty"`
+}
+
+// Signing is how requests to a site are signed. Profiles are shared, so secrets are never
+// written in one: they are given as "env:NAME", naming the environment variable that
+// holds them, which the schema enforces. The other credentials may be given either way.
+type Signing struct {
+	// Scheme is SigningHMAC or SigningOAuth1.
+	Scheme string `json:"scheme"`
+	// KeyID names the shared secret to the site, and Secret is it, for SigningHMAC.
+	KeyID  string `json:"key_id,omitempty"`
+	Secret string `json:"secret,omitempty"`
+	// ConsumerKey, ConsumerSecret, Token, and TokenSecret are the OAuth 1.0a client
+	// credentials and access token, for SigningOAuth1. The token is optional.
+	ConsumerKey    string `json:"consumer_key,omitempty"`
+	ConsumerSecret string `json:"consumer_secret,omitempty"`
+	Token          string `json:"token,omitempty"`
+	TokenSecret    string `json:"token_secret,omitempty"`
+}
+
+// Resolve returns s with the environment variables its credentials name read in.
+func (s Signing) Resolve() (Signing, error) {
+	for _, f := range []*string{&s.KeyID, &s.Secret, &s.ConsumerKey, &s.ConsumerSecret, &s.Token, &s.TokenSecret} {
+		name, ok := strings.CutPrefix(*f, "env:")
+		if !ok {
+			continue
+		}
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return Signing{}, fmt.Errorf("profile: environment variable %s is not set", name)
+		}
+		*f = value
+	}
+	return s, nil
+}
+
+// Selectors are CSS selectors for a site's articles.
+type Selectors struct {
+	// Content selects the elements whose text makes up the article.
+	Content string `json:"content,omitempty"`
+	// Byline selects the element holding the byline.
+	Byline string `json:"byline,omitempty"`
+	// BylineNames selects the author names inside the byline element.
+	BylineNames string `json:"byline_names,omitempty"`
+	// Title selects the headline element.
+	Title string `json:"title,omitempty"`
+	// Date selects the element giving the publication date.
+	Date string `json:"date,omitempty"`
+}
+
+// Limits throttle requests to a site.
+type Limits struct {
+	// Delay is the least time between the starts of two requests to a host.
+	Delay time.Duration
+	// RandomDelay is added, up to this much, to each Delay.
+	RandomDelay time.Duration
+	// Parallelism is the most requests in flight to a host at once; zero for no limit.
+	Parallelism int
+}
+
+// UnmarshalJSON reads limits with delays in Go duration syntax.
+func (l *Limits) UnmarshalJSON(data []byte) error {
+	var f struct {
+		Delay       string `json:"delay"`
+		RandomDelay string `json:"random_delay"`
+		Parallelism int    `json:"parallelism"`
+	}
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+	*l = Limits{Parallelism: f.Parallelism}
+	for _, d := range []struct {
+		value string
+		into  *time.Duration
+	}{{f.Delay, &l.Delay}, {f.RandomDelay, &l.RandomDelay}} {
+		if d.value == "" {
+			continue
+		}
+		v, err := time.ParseDuration(d.value)
+		if err != nil {
+			return err
+		}
+		*d.into = v
+	}
+	return nil
+}
+
+// MarshalJSON writes limits as UnmarshalJSON reads them.
+func (l Limits) MarshalJSON() ([]byte, error) {
+	f := struct {
+		Delay       string `json:"delay,omitempty"`
+		RandomDelay string `json:"random_delay,omitempty"`
+		Parallelism int    `json:"parallelism,omitempty"`
+	}{Parallelism: l.Parallelism}
+	if l.Delay > 0 {
+		f.Delay = l.Delay.String()
+	}
+	if l.RandomDelay > 0 {
+		f.RandomDelay = l.RandomDelay.String()
+	}
+	return json.Marshal(f)
+}
+
+// schemaJSON is the JSON Schema every profiles file must match.
+//
+//go:embed schema/profiles.v1.json
+var schemaJSON []byte
+
+// compiledSchema is schemaJSON ready for validation.
+var compiledSchema = jsonschema.MustCompileString("profiles.v1.json", string(schemaJSON))
+
+// Schema returns the JSON Schema document for profiles files.
+func Schema() []byte {
+	return schemaJSON
+}
+
+// Load reads a profiles file. Profiles are returned most specific domain first, so
+// that a subdomain's profile is met before its parent's.
+func Load(path string) ([]Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("profile: %w", err)
+	}
+	return Parse(data, path)
+}
+
+// Fetch reads a profiles file from location, which is an http or https URL, such as
+// a file in a community repository, or a local path.
+func Fetch(ctx context.Context, client *http.Client, location string) ([]Profile, error) {
+	if !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+		return Load(location)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, fmt.Errorf("profile: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("profile: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("profile: %s returned %s", location, resp.Status)
+	}
+	// Profiles files are small; anything much larger is not one.
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFetch))
+	if err != nil {
+		return nil, fmt.Errorf("profile: %w", err)
+	}
+	return Parse(data, location)
+}
+
+// maxFetch is the most of a fetched profiles file read.
+const maxFetch = 4 << 20
+
+// Parse reads profiles from data, which came from name, after checking it against the
+// schema, so an unknown key or a malformed value is reported rather than ignored.
+func Parse(data []byte, name string) ([]Profile, error) {
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("profile: reading %s: %w", name, err)
+	}
+	if err := compiledSchema.Validate(doc); err != nil {
+		return nil, fmt.Errorf("profile: %s does not match the profiles schema: %w", name, err)
+	}
+	var file map[string]Profile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("profile: reading %s: %w", name, err)
+	}
+	profiles := make([]Profile, 0, len(file))
+	seen := make(map[string]bool, len(file))
+	for domain, p := range file {
+		p.Domain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "www.")
+		if p.Domain == "" {
+			return nil, fmt.Errorf("profile: %s has a profile with no domain", name)
+		}
+		if seen[p.Domain] {
+			return nil, fmt.Errorf("profile: %s lists %s twice", name, p.Domain)
+		}
+		seen[p.Domain] = true
+		for _, f := range p.Fallbacks {
+			if f != FallbackPrint && f != FallbackRender {
+				return nil, fmt.Errorf("profile: %s: unknown fallback %q for %s (want %s or %s)", name, f, p.Domain, FallbackPrint, FallbackRender)
+			}
+		}
+		if p.BylineLocale != "" && !knownLanguage(p.BylineLocale) {
+			return nil, fmt.Errorf("profile: %s: no byline rules for language %q of %s", name, p.BylineLocale, p.Domain)
+		}
+		if sg := p.Signing; sg != nil && !(sg.Scheme == SigningHMAC && sg.Secret != "") && !(sg.Scheme == SigningOAuth1 && sg.ConsumerKey != "" && sg.ConsumerSecret != "") {
+			return nil, fmt.Errorf("profile: %s: signing of %s lacks the credentials of scheme %q", name, p.Domain, sg.Scheme)
+		}
+		if p.Limits != nil && (p.Limits.Delay < 0 || p.Limits.RandomDelay < 0 || p.Limits.Parallelism < 0) {
+			return nil, fmt.Errorf("profile: %s: negative limits for %s", name, p.Domain)
+		}
+		profiles = append(profiles, p)
+	}
+	Sort(profiles)
+	return profiles, nil
+}
+
+// Sort orders profiles most specific domain first, then by name.
+func Sort(profiles []Profile) {
+	sort.Slice(profiles, func(i, j int) bool {
+		ni, nj := strings.Count(profiles[i].Domain, "."), strings.Count(profiles[j].Domain, ".")
+		if ni != nj {
+			return ni > nj
+		}
+		return profiles[i].Domain < profiles[j].Domain
+	})
+}
+
+// Merge adds imported to profiles, returning the result and the domains of imported
+// that were already profiled. Those keep their existing profile unless replace is set.
+func Merge(profiles, imported []Profile, replace bool) ([]Profile, []string) {
+	merged := make([]Profile, 0, len(profiles)+len(imported))
+	index := make(map[string]int, len(profiles))
+	for _, p := range profiles {
+		index[p.Domain] = len(merged)
+		merged = append(merged, p)
+	}
+	var conflicts []string
+	for _, p := range imported {
+		i, ok := index[p.Domain]
+		if !ok {
+			index[p.Domain] = len(merged)
+			merged = append(merged, p)
+			continue
+		}
+		conflicts = append(conflicts, p.Domain)
+		if replace {
+			merged[i] = p
+		}
+	}
+	Sort(merged)
+	return merged, conflicts
+}
+
+// Marshal encodes profiles as a profiles file, keyed by domain in name order.
+func Marshal(profiles []Profile) ([]byte, error) {
+	file := make(map[string]Profile, len(profiles))
+	for _, p := range profiles {
+		file[p.Domain] = p
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("profile: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// Save writes profiles to path, replacing it atomically so that a run reading the file
+// never sees half of it.
+func Save(path string, profiles []Profile) error {
+	data, err := Marshal(profiles)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".profiles-*")
+	if err != nil {
+		return fmt.Errorf("profile: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("profile: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("profile: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("profile: %w", err)
+	}
+	return nil
+}
+
+// knownLanguage reports whether there are byline rules for lang.
+func knownLanguage(lang string) bool {
+	for _, l := range byline.Languages() {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
+
+// HasFallback reports whether p lists fallback.
+func (p Profile) HasFallback(fallback string) bool {
+	for _, f := range p.Fallbacks {
+		if f == fallback {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+// Package provenance signs scraped records with a local Ed25519 key so that an archive's
+// integrity and capture time can be proven later: anyone holding the public key can check
+// that a record's content, URL, and fetch time are exactly what was originally captured.
+package provenance
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// messagePrefix versions the signed message layout so it can change without ambiguity.
+const messagePrefix = "zero-scraper-provenance-v1"
+
+// Signature is the provenance attached to a signed record.
+type Signature struct {
+	// ContentHash is "sha256:" followed by the hex digest of the article content.
+	ContentHash string `json:"content_hash" avro:"content_hash"`
+	// FetchedAt is when the page was captured, in RFC 3339 format (UTC).
+	FetchedAt string `json:"fetched_at" avro:"fetched_at"`
+	// KeyID identifies the signing key: the first 16 hex digits of the public key's SHA-256.
+	KeyID string `json:"key_id" avro:"key_id"`
+	// Value is the base64-encoded Ed25519 signature.
+	Value string `json:"signature" avro:"signature"`
+}
+
+// Signer signs records with a private key.
+type Signer struct {
+	key   ed25519.PrivateKey
+	keyID string
+}
+
+// LoadSigner reads a PEM-encoded PKCS #8 Ed25519 private key, as written by GenerateKey.
+func LoadSigner(path string) (*Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("provenance: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("provenance: %s is not PEM encoded", path)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("provenance: %w", err)
+	}
+	key, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("provenance: %s is not an Ed25519 key", path)
+	}
+	return &Signer{key: key, keyID: KeyID(key.Public().(ed25519.PublicKey))}, nil
+}
+
+// Sign produces the provenance signature for content captured from url at fetched.
+func (s *Signer) Sign(url, content string, fetched time.Time) Signature {
+	sig := Signature{
+		ContentHash: HashContent(content),
+		FetchedAt:   fetched.UTC().Format(time.RFC3339),
+		KeyID:       s.keyID,
+	}
+	sig.Value = base64.StdEncoding.EncodeToString(ed25519.Sign(s.key, message(url, sig)))
+	return sig
+}
+
+// Verify checks that sig was made by pub over this url and content.
+func Verify(pub ed25519.PublicKey, url, content string, sig Signature) error {
+	if HashContent(content) != sig.ContentHash {
+		return errors.New("provenance: content does not match the signed hash")
+	}
+	raw, err := base64.StdEncoding.DecodeString(sig.Value)
+	if err != nil {
+		return fmt.Errorf("provenance: malformed signature: %w", err)
+	}
+	if !ed25519.Verify(pub, message(url, sig), raw) {
+		return errors.New("provenance: signature does not verify")
+	}
+	return nil
+}
+
+// HashContent returns the "sha256:<hex>" digest of content.
+func HashContent(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// KeyID derives a short identifier for a public key.
+func KeyID(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:8])
+}
+
+// message is the exact byte string that is signed.
+func message(url string, sig Signature) []byte {
+	return []byte(messagePrefix + "\n" + url + "\n" + sig.FetchedAt + "\n" + sig.ContentHash)
+}
+
+// GenerateKey creates a new key pair, writing the private key to privPath (mode 0600)
+// and the public key to pubPath, both PEM encoded.
+func GenerateKey(privPath, pubPath string) error {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return fmt.Errorf("provenance: %w", err)
+	}
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return fmt.Errorf("provenance: %w", err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return fmt.Errorf("provenance: %w", err)
+	}
+	if err := os.WriteFile(privPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}), 0o600); err != nil {
+		return fmt.Errorf("provenance: %w", err)
+	}
+	return os.WriteFile(pubPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0o644)
+}
+
+// LoadPublicKey reads a PEM-encoded Ed25519 public key, as written by GenerateKey.
+func LoadPublicKey(path string) (ed25519.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("provenance: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("provenance: %s is not PEM encoded", path)
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("provenance: %w", err)
+	}
+	pub, ok := parsed.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("provenance: %s is not an Ed25519 key", path)
+	}
+	return pub, nil
+}
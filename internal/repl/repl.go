@@ -0,0 +1,261 @@
+// Package repl implements an interactive session for developing site support: a page is
+// fetched once and cached, and selectors can then be tried against it repeatedly.
+package repl
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"github.com/hail2skins/zero-scraper/pkg/scraper"
+)
+
+// maxMatches limits how many matching elements a query prints.
+const maxMatches = 20
+
+// helpText lists the commands understood by the REPL.
+const helpText = `Commands:
+  fetch URL              fetch a page and cache it for this session
+  load FILE [URL]        load a saved HTML file into the session instead of fetching
+  select CSS             print the text of elements matching a CSS selector
+  attr CSS NAME          print attribute NAME of elements matching a CSS selector
+  count CSS              print how many elements match a CSS selector
+  meta                   print the page title and all <meta> tags
+  extract                run the scraper's built-in extraction over the cached page
+  set content|author CSS remember a selector for the site being worked on
+  save FILE              write the remembered selectors to FILE as JSON
+  html                   print the cached page's raw HTML
+  help                   show this help
+  quit                   leave the REPL`
+
+// SiteSelectors are the selectors a user has settled on for one domain during a session.
+type SiteSelectors struct {
+	Domain          string `json:"domain"`
+	ContentSelector string `json:"content_selector,omitempty"`
+	AuthorSelector  string `json:"author_selector,omitempty"`
+}
+
+// session is the state carried between commands.
+type session struct {
+	out       io.Writer
+	scraper   *scraper.Scraper
+	url       string
+	html      string
+	doc       *goquery.Document
+	selectors SiteSelectors
+}
+
+// Run reads commands from in and writes results to out until "quit" or end of input.
+func Run(in io.Reader, out io.Writer) error {
+	s := &session{out: out, scraper: scraper.New()}
+	sc := bufio.NewScanner(in)
+	// Allow long lines such as complex selectors.
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+
+	fmt.Fprintln(out, `zero-scraper REPL. Type "help" for commands.`)
+	for {
+		fmt.Fprint(out, "> ")
+		if !sc.Scan() {
+			fmt.Fprintln(out)
+			return sc.Err()
+		}
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		cmd, arg, _ := strings.Cut(line, " ")
+		arg = strings.TrimSpace(arg)
+		if cmd == "quit" || cmd == "exit" {
+			return nil
+		}
+		if err := s.dispatch(cmd, arg); err != nil {
+			fmt.Fprintln(out, "error:", err)
+		}
+	}
+}
+
+// dispatch runs a single command.
+func (s *session) dispatch(cmd, arg string) error {
+	switch cmd {
+	case "help":
+		fmt.Fprintln(s.out, helpText)
+		return nil
+	case "fetch":
+		return s.fetch(arg)
+	case "load":
+		return s.load(arg)
+	case "save":
+		return s.save(arg)
+	case "set":
+		return s.set(arg)
+	}
+
+	// Every remaining command needs a cached page.
+	if s.doc == nil {
+		return fmt.Errorf("no page loaded; use fetch URL first")
+	}
+	switch cmd {
+	case "select":
+		return s.each(arg, func(i int, sel *goquery.Selection) {
+			fmt.Fprintf(s.out, "[%d] %s\n", i, strings.Join(strings.Fields(sel.Text()), " "))
+		})
+	case "attr":
+		css, name, ok := cutLast(arg)
+		if !ok {
+			return fmt.Errorf("usage: attr CSS NAME")
+		}
+		return s.each(css, func(i int, sel *goquery.Selection) {
+			v, _ := sel.Attr(name)
+			fmt.Fprintf(s.out, "[%d] %s\n", i, v)
+		})
+	case "count":
+		if arg == "" {
+			return fmt.Errorf("usage: count CSS")
+		}
+		fmt.Fprintln(s.out, s.doc.Find(arg).Length())
+		return nil
+	case "meta":
+		s.meta()
+		return nil
+	case "extract":
+		a, err := s.scraper.ScrapeHTML(s.url, s.html)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(s.out, "Byline: %s\nAuthors: %s\nRobots: %s\nContent (%d chars):\n%s\n",
+			a.Byline, strings.Join(a.Authors, "; "), strings.Join(a.Robots, ", "), len(a.Content), a.Content)
+		return nil
+	case "html":
+		fmt.Fprintln(s.out, s.html)
+		return nil
+	}
+	return fmt.Errorf("unknown command %q; type help", cmd)
+}
+
+// fetch downloads a page and makes it the session's current document.
+func (s *session) fetch(rawURL string) error {
+	if rawURL == "" {
+		return fmt.Errorf("usage: fetch URL")
+	}
+	html, err := s.scraper.FetchHTML(rawURL)
+	if err != nil {
+		return err
+	}
+	return s.setPage(rawURL, html)
+}
+
+// load reads a saved HTML file as the current document, optionally under a given URL.
+func (s *session) load(arg string) error {
+	file, rawURL, _ := strings.Cut(arg, " ")
+	if file == "" {
+		return fmt.Errorf("usage: load FILE [URL]")
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	if rawURL = strings.TrimSpace(rawURL); rawURL == "" {
+		rawURL = "http://localhost/" + file
+	}
+	return s.setPage(rawURL, string(data))
+}
+
+// setPage parses html and caches it as the page at rawURL.
+func (s *session) setPage(rawURL, html string) error {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return err
+	}
+	s.url, s.html, s.doc = rawURL, html, doc
+	if u, err := url.Parse(rawURL); err == nil && u.Host != s.selectors.Domain {
+		// Starting on a new site: forget selectors chosen for the previous one.
+		s.selectors = SiteSelectors{Domain: u.Host}
+	}
+	fmt.Fprintf(s.out, "Cached %s (%d bytes)\n", rawURL, len(html))
+	return nil
+}
+
+// set remembers a selector for the current site.
+func (s *session) set(arg string) error {
+	field, css, _ := strings.Cut(arg, " ")
+	css = strings.TrimSpace(css)
+	if css == "" {
+		return fmt.Errorf("usage: set content|author CSS")
+	}
+	switch field {
+	case "content":
+		s.selectors.ContentSelector = css
+	case "author":
+		s.selectors.AuthorSelector = css
+	default:
+		return fmt.Errorf("unknown field %q; want content or author", field)
+	}
+	if s.doc != nil {
+		fmt.Fprintf(s.out, "%s selector matches %d elements\n", field, s.doc.Find(css).Length())
+	}
+	return nil
+}
+
+// save writes the remembered selectors to a JSON file.
+func (s *session) save(file string) error {
+	if file == "" {
+		return fmt.Errorf("usage: save FILE")
+	}
+	data, err := json.MarshalIndent(s.selectors, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	fmt.Fprintf(s.out, "Saved selectors for %s to %s\n", s.selectors.Domain, file)
+	return nil
+}
+
+// meta prints the page title and every meta tag's name (or property) and content.
+func (s *session) meta() {
+	fmt.Fprintf(s.out, "title: %s\n", strings.TrimSpace(s.doc.Find("title").First().Text()))
+	s.doc.Find("meta").Each(func(_ int, sel *goquery.Selection) {
+		key, ok := sel.Attr("property")
+		if !ok {
+			key, ok = sel.Attr("name")
+		}
+		if !ok {
+			return
+		}
+		content, _ := sel.Attr("content")
+		fmt.Fprintf(s.out, "%s: %s\n", key, content)
+	})
+}
+
+// each calls fn for up to maxMatches elements matching css and reports the total.
+func (s *session) each(css string, fn func(int, *goquery.Selection)) error {
+	if css == "" {
+		return fmt.Errorf("a CSS selector is required")
+	}
+	matches := s.doc.Find(css)
+	matches.EachWithBreak(func(i int, sel *goquery.Selection) bool {
+		if i >= maxMatches {
+			return false
+		}
+		fn(i, sel)
+		return true
+	})
+	fmt.Fprintf(s.out, "(%d matches)\n", matches.Length())
+	return nil
+}
+
+// cutLast splits s around its last space, for commands whose final argument follows a selector.
+func cutLast(s string) (string, string, bool) {
+	i := strings.LastIndex(s, " ")
+	if i < 0 {
+		return "", "", false
+	}
+	return strings.TrimSpace(s[:i]), s[i+1:], true
+}
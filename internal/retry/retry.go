@@ -0,0 +1,292 @@
+// Package retry keeps a persistent, on-disk queue of URLs that failed to scrape.
+// Failed URLs are retried with exponential backoff; after too many failures they move to
+// a dead-letter file together with the class of their final error, for manual review.
+package retry
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// queueFile holds the pending retries as a JSON array.
+	queueFile = "retry.json"
+	// deadFile is the append-only dead-letter log, one JSON entry per line.
+	deadFile = "dead.jsonl"
+)
+
+// Entry is a URL that has failed at least once.
+type Entry struct {
+	URL string `json:"url"`
+	// Tenant is the serve-mode tenant the URL was scraped for; empty without tenants.
+	Tenant      string    `json:"tenant,omitempty"`
+	Attempts    int       `json:"attempts"`
+	LastError   string    `json:"last_error"`
+	ErrorClass  string    `json:"error_class"`
+	FirstFailed time.Time `json:"first_failed"`
+	NextAttempt time.Time `json:"next_attempt,omitempty"`
+}
+
+// Queue is the persistent retry queue stored in a directory.
+// It is safe for concurrent use.
+type Queue struct {
+	mu          sync.Mutex
+	dir         string
+	maxAttempts int
+	baseDelay   time.Duration
+	pending     map[string]*Entry // pending is keyed by pendingKey.
+}
+
+// pendingKey keys a tenant's URL in Queue.pending, so that tenants' failures of the same
+// URL are retried apart.
+func pendingKey(tenant, url string) string {
+	return tenant + "\x00" + url
+}
+
+// Open loads (or creates) the retry queue in dir. A URL is dead-lettered once it has
+// failed maxAttempts times; the wait before retry n is baseDelay × 2^(n-1).
+func Open(dir string, maxAttempts int, baseDelay time.Duration) (*Queue, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("retry: %w", err)
+	}
+	q := &Queue{dir: dir, maxAttempts: maxAttempts, baseDelay: baseDelay, pending: make(map[string]*Entry)}
+
+	data, err := os.ReadFile(filepath.Join(dir, queueFile))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("retry: %w", err)
+	}
+	if len(data) > 0 {
+		var entries []*Entry
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, fmt.Errorf("retry: reading %s: %w", queueFile, err)
+		}
+		for _, e := range entries {
+			q.pending[pendingKey(e.Tenant, e.URL)] = e
+		}
+	}
+	return q, nil
+}
+
+// Fail records a failed attempt for url, scraped for tenant. It either schedules another
+// attempt or, once the URL has used up its attempts, moves it to the dead-letter file.
+func (q *Queue) Fail(tenant, url string, cause error) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now().UTC()
+	k := pendingKey(tenant, url)
+	e, ok := q.pending[k]
+	if !ok {
+		e = &Entry{URL: url, Tenant: tenant, FirstFailed: now}
+		q.pending[k] = e
+	}
+	e.Attempts++
+	e.LastError = cause.Error()
+	e.ErrorClass = Classify(cause)
+
+	if e.Attempts >= q.maxAttempts {
+		delete(q.pending, k)
+		e.NextAttempt = time.Time{}
+		if err := q.appendDead(e); err != nil {
+			return err
+		}
+		return q.save()
+	}
+	e.NextAttempt = now.Add(q.baseDelay << (e.Attempts - 1))
+	return q.save()
+}
+
+// Succeed removes tenant's url from the queue, if it was waiting for a retry.
+func (q *Queue) Succeed(tenant, url string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	k := pendingKey(tenant, url)
+	if _, ok := q.pending[k]; !ok {
+		return nil
+	}
+	delete(q.pending, k)
+	return q.save()
+}
+
+// Forget drops every URL that match accepts, from the queue and the dead-letter file
+// alike, and returns how many entries it dropped.
+func (q *Queue) Forget(match func(url string) bool) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var dropped int
+	for k, e := range q.pending {
+		if match(e.URL) {
+			delete(q.pending, k)
+			dropped++
+		}
+	}
+	if dropped > 0 {
+		if err := q.save(); err != nil {
+			return 0, err
+		}
+	}
+
+	dead, err := q.Dead()
+	if err != nil {
+		return dropped, err
+	}
+	var kept []byte
+	deadDropped := 0
+	for _, e := range dead {
+		if match(e.URL) {
+			deadDropped++
+			continue
+		}
+		line, err := json.Marshal(e)
+		if err != nil {
+			return dropped, fmt.Errorf("retry: %w", err)
+		}
+		kept = append(append(kept, line...), '\n')
+	}
+	if deadDropped == 0 {
+		return dropped, nil
+	}
+	path := filepath.Join(q.dir, deadFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, kept, 0o644); err != nil {
+		return dropped, fmt.Errorf("retry: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return dropped, fmt.Errorf("retry: %w", err)
+	}
+	return dropped + deadDropped, nil
+}
+
+// Due returns a copy of every entry whose next attempt time has passed, oldest first.
+func (q *Queue) Due(now time.Time) []Entry {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var due []*Entry
+	for _, e := range q.pending {
+		if !e.NextAttempt.After(now) {
+			due = append(due, e)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextAttempt.Before(due[j].NextAttempt) })
+	entries := make([]Entry, len(due))
+	for i, e := range due {
+		entries[i] = *e
+	}
+	return entries
+}
+
+// Pending returns a copy of every entry still waiting for a retry, soonest first.
+func (q *Queue) Pending() []Entry {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	entries := make([]Entry, 0, len(q.pending))
+	for _, e := range q.pending {
+		entries = append(entries, *e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].NextAttempt.Before(entries[j].NextAttempt) })
+	return entries
+}
+
+// Dead reads every dead-lettered entry in the order it was recorded.
+func (q *Queue) Dead() ([]Entry, error) {
+	f, err := os.Open(filepath.Join(q.dir, deadFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("retry: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var e Entry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("retry: reading %s: %w", deadFile, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, sc.Err()
+}
+
+// save rewrites the pending queue atomically. The caller must hold q.mu.
+func (q *Queue) save() error {
+	entries := make([]*Entry, 0, len(q.pending))
+	for _, e := range q.pending {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].URL != entries[j].URL {
+			return entries[i].URL < entries[j].URL
+		}
+		return entries[i].Tenant < entries[j].Tenant
+	})
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("retry: %w", err)
+	}
+
+	path := filepath.Join(q.dir, queueFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("retry: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// appendDead adds an entry to the dead-letter file. The caller must hold q.mu.
+func (q *Queue) appendDead(e *Entry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("retry: %w", err)
+	}
+	f, err := os.OpenFile(filepath.Join(q.dir, deadFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("retry: %w", err)
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// Classify buckets an error into a coarse class: "timeout", "dns", "connection",
+// "http_4xx", "http_5xx", or "other".
+func Classify(err error) string {
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	var opErr *net.OpError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.As(err, &dnsErr):
+		return "dns"
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case errors.As(err, &opErr):
+		return "connection"
+	}
+
+	// Colly reports HTTP failures with the status text as the error message.
+	msg := err.Error()
+	for code := 400; code < 600; code++ {
+		if text := http.StatusText(code); text != "" && strings.EqualFold(msg, text) {
+			if code < 500 {
+				return "http_4xx"
+			}
+			return "http_5xx"
+		}
+	}
+	return "other"
+}
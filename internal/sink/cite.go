@@ -0,0 +1,204 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hail2skins/zero-scraper/internal/byline"
+)
+
+// bibtexEscaper escapes characters that are special in BibTeX field values.
+var bibtexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`, "{", `\{`, "}", `\}`, "%", `\%`, "&", `\&`, "$", `\$`, "#", `\#`, "_", `\_`,
+)
+
+// cslName is a person in CSL-JSON.
+type cslName struct {
+	Family  string `json:"family,omitempty"`
+	Given   string `json:"given,omitempty"`
+	Literal string `json:"literal,omitempty"`
+}
+
+// cslDate is a date in CSL-JSON's date-parts form.
+type cslDate struct {
+	DateParts [][]int `json:"date-parts"`
+}
+
+// cslItem is one citation in CSL-JSON, the format read by Zotero, Pandoc, and citeproc.
+type cslItem struct {
+	ID             string    `json:"id"`
+	Type           string    `json:"type"`
+	Title          string    `json:"title"`
+	Author         []cslName `json:"author,omitempty"`
+	ContainerTitle string    `json:"container-title,omitempty"`
+	URL            string    `json:"URL"`
+	Issued         *cslDate  `json:"issued,omitempty"`
+	Accessed       cslDate   `json:"accessed"`
+}
+
+// CiteSink writes a citation record for every article, so academic users can cite the
+// scraped corpus directly. BibTeX entries are appended to the file; CSL-JSON is a single
+// array, so the file is rewritten with every article.
+type CiteSink struct {
+	mu     sync.Mutex
+	path   string
+	format string
+	items  []cslItem // items holds every CSL-JSON entry written so far.
+	keys   map[string]int
+}
+
+// NewCiteSink creates a sink writing citations in format "bibtex" or "csl-json" to path.
+func NewCiteSink(path, format string) (*CiteSink, error) {
+	if format != "bibtex" && format != "csl-json" {
+		return nil, fmt.Errorf("cite: unsupported format %q (want bibtex or csl-json)", format)
+	}
+	return &CiteSink{path: path, format: format, keys: make(map[string]int)}, nil
+}
+
+// Publish writes the citation for one article.
+func (s *CiteSink) Publish(_ context.Context, a Article) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	accessed := time.Now().UTC()
+	if a.Provenance != nil {
+		// Prefer the signed capture time when there is one, so both records agree.
+		if t, err := time.Parse(time.RFC3339, a.Provenance.FetchedAt); err == nil {
+			accessed = t
+		}
+	}
+	key := s.citeKey(a.URL, accessed)
+	title := a.Title
+	if title == "" {
+		title = titleFromSlug(slugFromURL(a.URL))
+	}
+	publication := a.Publisher
+	if u, err := url.Parse(a.URL); err == nil && publication == "" {
+		publication = strings.TrimPrefix(u.Hostname(), "www.")
+	}
+	authors := byline.ParseURL(a.URL, a.Byline)
+	published, _ := time.Parse(time.RFC3339, a.Published)
+
+	if s.format == "bibtex" {
+		return s.appendBibTeX(key, title, publication, a.URL, authors, published, accessed)
+	}
+
+	item := cslItem{
+		ID:             key,
+		Type:           "article-newspaper",
+		Title:          title,
+		ContainerTitle: publication,
+		URL:            a.URL,
+		Accessed:       cslDate{DateParts: [][]int{{accessed.Year(), int(accessed.Month()), accessed.Day()}}},
+	}
+	if !published.IsZero() {
+		item.Issued = &cslDate{DateParts: [][]int{{published.Year(), int(published.Month()), published.Day()}}}
+	}
+	for _, n := range authors {
+		item.Author = append(item.Author, cslPerson(n))
+	}
+	s.items = append(s.items, item)
+	return s.writeCSL()
+}
+
+// appendBibTeX appends a @misc entry (understood by both BibTeX and biblatex). The
+// publication date is left out when published is zero.
+func (s *CiteSink) appendBibTeX(key, title, publication, link string, authors []string, published, accessed time.Time) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "@misc{%s,\n", key)
+	if len(authors) > 0 {
+		names := make([]string, len(authors))
+		for i, n := range authors {
+			p := cslPerson(n)
+			if p.Literal != "" {
+				// Braces keep organizational authors from being split into first/last names.
+				names[i] = "{" + bibtexEscaper.Replace(p.Literal) + "}"
+			} else {
+				names[i] = bibtexEscaper.Replace(p.Family + ", " + p.Given)
+			}
+		}
+		fmt.Fprintf(&b, "  author = {%s},\n", strings.Join(names, " and "))
+	}
+	fmt.Fprintf(&b, "  title = {%s},\n", bibtexEscaper.Replace(title))
+	if publication != "" {
+		fmt.Fprintf(&b, "  howpublished = {%s},\n", bibtexEscaper.Replace(publication))
+	}
+	if !published.IsZero() {
+		fmt.Fprintf(&b, "  date = {%s},\n", published.Format("2006-01-02"))
+		fmt.Fprintf(&b, "  year = {%d},\n", published.Year())
+	}
+	fmt.Fprintf(&b, "  url = {%s},\n", link)
+	fmt.Fprintf(&b, "  urldate = {%s},\n", accessed.Format("2006-01-02"))
+	fmt.Fprintf(&b, "  note = {Accessed %s}\n}\n\n", accessed.Format("2006-01-02"))
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("cite: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(b.String()); err != nil {
+		return fmt.Errorf("cite: %w", err)
+	}
+	return nil
+}
+
+// writeCSL rewrites the CSL-JSON array atomically.
+func (s *CiteSink) writeCSL() error {
+	data, err := json.MarshalIndent(s.items, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cite: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".cite-*.json")
+	if err != nil {
+		return fmt.Errorf("cite: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("cite: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("cite: %w", err)
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// citeKey builds a unique key such as "apnews2024storyslug", adding a suffix on collisions.
+func (s *CiteSink) citeKey(rawURL string, accessed time.Time) string {
+	host := "web"
+	if u, err := url.Parse(rawURL); err == nil {
+		host, _, _ = strings.Cut(strings.TrimPrefix(u.Hostname(), "www."), ".")
+	}
+	slug := strings.ReplaceAll(slugFromURL(rawURL), "-", "")
+	if len(slug) > 24 {
+		slug = slug[:24]
+	}
+	key := fmt.Sprintf("%s%d%s", nonSlugChars.ReplaceAllString(strings.ToLower(host), ""), accessed.Year(), slug)
+	s.keys[key]++
+	if n := s.keys[key]; n > 1 {
+		key = fmt.Sprintf("%s-%d", key, n)
+	}
+	return key
+}
+
+// Close does nothing; citations are written as soon as each article is published.
+func (s *CiteSink) Close() error {
+	return nil
+}
+
+// cslPerson splits a name into given and family parts on the last space.
+// Single-word names (often organizations such as "Reuters") are kept literal.
+func cslPerson(name string) cslName {
+	i := strings.LastIndex(name, " ")
+	if i < 0 {
+		return cslName{Literal: name}
+	}
+	return cslName{Given: name[:i], Family: name[i+1:]}
+}
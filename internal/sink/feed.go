@@ -0,0 +1,192 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// excerptLength is the maximum length, in runes, of the summary included with each feed entry.
+const excerptLength = 280
+
+// atomFeed is the root element of an Atom 1.0 document.
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+// atomEntry is a single scraped article in the feed.
+type atomEntry struct {
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Link    atomLink    `xml:"link"`
+	Updated string      `xml:"updated"`
+	Author  *atomAuthor `xml:"author,omitempty"`
+	Summary string      `xml:"summary"`
+}
+
+// atomLink points an entry at the original article.
+type atomLink struct {
+	Href string `xml:"href,attr"`
+}
+
+// atomAuthor holds the article byline.
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+// FeedSink keeps the most recently scraped articles and renders them as an Atom feed.
+// The feed can be written to a file after every article, served over HTTP, or both.
+type FeedSink struct {
+	mu    sync.Mutex
+	items []atomEntry // items is ordered newest first.
+	size  int
+	path  string
+}
+
+// NewFeedSink creates a feed holding up to size articles.
+// If path is non-empty, the feed file is rewritten each time an article is published,
+// and the entries an earlier run left in it are kept, up to size.
+func NewFeedSink(path string, size int) (*FeedSink, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("feed: size must be positive")
+	}
+	s := &FeedSink{size: size, path: path}
+	if path == "" {
+		return s, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("feed: %w", err)
+	}
+	var feed atomFeed
+	if err := xml.Unmarshal(data, &feed); err != nil {
+		return nil, fmt.Errorf("feed: %s: %w", path, err)
+	}
+	s.items = feed.Entries
+	if len(s.items) > size {
+		s.items = s.items[:size]
+	}
+	return s, nil
+}
+
+// Publish adds the article to the top of the feed, dropping the oldest entry if the feed is full.
+func (s *FeedSink) Publish(_ context.Context, a Article) error {
+	entry := atomEntry{
+		Title:   a.Title,
+		ID:      a.URL,
+		Link:    atomLink{Href: a.URL},
+		Updated: time.Now().UTC().Format(time.RFC3339),
+		Summary: excerpt(a.Content),
+	}
+	if entry.Title == "" {
+		entry.Title = a.URL
+	}
+	// Pages that asked not to be quoted in snippets get no excerpt.
+	if r := a.Robots; r != nil && r.Decision == RobotsNoSnippet {
+		entry.Summary = ""
+	}
+	if a.Byline != "" {
+		entry.Author = &atomAuthor{Name: a.Byline}
+	}
+
+	s.mu.Lock()
+	s.items = append([]atomEntry{entry}, s.items...)
+	if len(s.items) > s.size {
+		s.items = s.items[:s.size]
+	}
+	s.mu.Unlock()
+
+	if s.path == "" {
+		return nil
+	}
+	return s.writeFile()
+}
+
+// writeFile renders the feed to a temporary file and renames it over the configured path,
+// so feed readers never see a half-written document.
+func (s *FeedSink) writeFile() error {
+	data, err := s.render()
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".feed-*.xml")
+	if err != nil {
+		return fmt.Errorf("feed: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("feed: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("feed: %w", err)
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// render encodes the current feed as an Atom XML document.
+func (s *FeedSink) render() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	feed := atomFeed{
+		Title:   "zero-scraper: recently scraped articles",
+		ID:      "urn:zero-scraper:feed",
+		Updated: time.Now().UTC().Format(time.RFC3339),
+	}
+	feed.Entries = s.items
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(feed); err != nil {
+		return nil, fmt.Errorf("feed: encoding: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ServeHTTP serves the current feed, so the sink can be mounted at /feed in serve mode.
+func (s *FeedSink) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	data, err := s.render()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	w.Write(data)
+}
+
+// Close writes nothing further; the feed file is already up to date after every publish.
+func (s *FeedSink) Close() error {
+	return nil
+}
+
+// excerpt collapses the article's white space and truncates it on a word boundary.
+func excerpt(content string) string {
+	text := strings.Join(strings.Fields(content), " ")
+	runes := []rune(text)
+	if len(runes) <= excerptLength {
+		return text
+	}
+	cut := string(runes[:excerptLength])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return cut + "…"
+}
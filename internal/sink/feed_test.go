@@ -0,0 +1,47 @@
+package sink
+
+import (
+	"context"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestFeedSinkKeepsEarlierRuns checks that a feed file keeps the entries of an earlier
+// run, newest first and up to the feed's size.
+func TestFeedSinkKeepsEarlierRuns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "feed.xml")
+	publish := func(urls ...string) {
+		t.Helper()
+		s, err := NewFeedSink(path, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, u := range urls {
+			if err := s.Publish(context.Background(), Article{URL: u, Title: u, Content: "Something happened."}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	publish("https://example.com/1", "https://example.com/2")
+	publish("https://example.com/3", "https://example.com/4")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var feed atomFeed
+	if err := xml.Unmarshal(data, &feed); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"https://example.com/4", "https://example.com/3", "https://example.com/2"}
+	if len(feed.Entries) != len(want) {
+		t.Fatalf("feed has %d entries, want %d", len(feed.Entries), len(want))
+	}
+	for i, e := range feed.Entries {
+		if e.ID != want[i] || e.Summary != "Something happened." {
+			t.Errorf("entry %d = %s %q, want %s", i, e.ID, e.Summary, want[i])
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ipfsManifestEntry is one line of the IPFS manifest file, mapping an article to its CID.
+type ipfsManifestEntry struct {
+	URL     string `json:"url"`
+	CID     string `json:"cid"`
+	AddedAt string `json:"added_at"`
+}
+
+// IPFSSink adds each article as a JSON document to IPFS through a local node's HTTP API
+// and records the resulting content identifier (CID). Because a CID is derived from the
+// content, a later edit or removal of the story cannot alter what was archived.
+type IPFSSink struct {
+	api      string
+	client   *http.Client
+	mu       sync.Mutex
+	manifest *os.File // manifest is nil when CIDs are only logged.
+}
+
+// NewIPFSSink creates a sink for the node API at api (e.g. http://127.0.0.1:5001).
+// If manifestPath is non-empty, a JSON line with the URL and CID of every added article
+// is appended to that file.
+func NewIPFSSink(api, manifestPath string) (*IPFSSink, error) {
+	s := &IPFSSink{
+		api:    strings.TrimSuffix(api, "/"),
+		client: &http.Client{Timeout: 60 * time.Second},
+	}
+	if manifestPath != "" {
+		f, err := os.OpenFile(manifestPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("ipfs: opening manifest: %w", err)
+		}
+		s.manifest = f
+	}
+	return s, nil
+}
+
+// Publish adds and pins the article JSON, then records its CID.
+func (s *IPFSSink) Publish(ctx context.Context, a Article) error {
+	payload, err := json.Marshal(a)
+	if err != nil {
+		return fmt.Errorf("ipfs: encoding article: %w", err)
+	}
+
+	cid, err := s.add(ctx, payload)
+	if err != nil {
+		return err
+	}
+	log.Printf("Added %s to IPFS as %s", a.URL, cid)
+
+	if s.manifest == nil {
+		return nil
+	}
+	line, err := json.Marshal(ipfsManifestEntry{URL: a.URL, CID: cid, AddedAt: time.Now().UTC().Format(time.RFC3339)})
+	if err != nil {
+		return fmt.Errorf("ipfs: encoding manifest entry: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.manifest.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("ipfs: writing manifest: %w", err)
+	}
+	return nil
+}
+
+// add posts data to the node's /api/v0/add endpoint and returns the CID it reports.
+func (s *IPFSSink) add(ctx context.Context, data []byte) (string, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("file", "article.json")
+	if err != nil {
+		return "", fmt.Errorf("ipfs: %w", err)
+	}
+	part.Write(data)
+	if err := mw.Close(); err != nil {
+		return "", fmt.Errorf("ipfs: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.api+"/api/v0/add?pin=true&cid-version=1", &body)
+	if err != nil {
+		return "", fmt.Errorf("ipfs: %w", err)
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("ipfs: adding article: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("ipfs: node returned %s", resp.Status)
+	}
+
+	var out struct {
+		Hash string `json:"Hash"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("ipfs: decoding response: %w", err)
+	}
+	return out.Hash, nil
+}
+
+// Close closes the manifest file, if one is open.
+func (s *IPFSSink) Close() error {
+	if s.manifest == nil {
+		return nil
+	}
+	return s.manifest.Close()
+}
@@ -0,0 +1,171 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hamba/avro/v2"
+	"github.com/segmentio/kafka-go"
+)
+
+// articleSchema is the Avro schema used when a Kafka sink is configured with the "avro" format.
+const articleSchema = `{
+	"type": "record",
+	"name": "Article",
+	"namespace": "zeroscraper",
+	"fields": [
+		{"name": "schema_version", "type": "string"},
+		{"name": "url", "type": "string"},
+		{"name": "final_url", "type": "string", "default": ""},
+		{"name": "canonical_url", "type": "string", "default": ""},
+		{"name": "title", "type": "string", "default": ""},
+		{"name": "published", "type": "string", "default": ""},
+		{"name": "publisher", "type": "string", "default": ""},
+		{"name": "content", "type": "string"},
+		{"name": "byline", "type": "string"},
+		{"name": "source", "type": ["null", {
+			"type": "record",
+			"name": "Source",
+			"fields": [
+				{"name": "domain", "type": "string"},
+				{"name": "name", "type": "string", "default": ""},
+				{"name": "bias", "type": "string", "default": ""},
+				{"name": "reliability", "type": "string", "default": ""},
+				{"name": "country", "type": "string", "default": ""},
+				{"name": "ownership", "type": "string", "default": ""},
+				{"name": "extra", "type": {"type": "map", "values": "string"}, "default": {}}
+			]
+		}], "default": null},
+		{"name": "robots", "type": ["null", {
+			"type": "record",
+			"name": "Robots",
+			"fields": [
+				{"name": "directives", "type": {"type": "array", "items": "string"}},
+				{"name": "decision", "type": "string"}
+			]
+		}], "default": null},
+		{"name": "provenance", "type": ["null", {
+			"type": "record",
+			"name": "Provenance",
+			"fields": [
+				{"name": "content_hash", "type": "string"},
+				{"name": "fetched_at", "type": "string"},
+				{"name": "key_id", "type": "string"},
+				{"name": "signature", "type": "string"}
+			]
+		}], "default": null},
+		{"name": "confidence", "type": {"type": "map", "values": {
+			"type": "record",
+			"name": "Confidence",
+			"fields": [
+				{"name": "score", "type": "double"},
+				{"name": "source", "type": "string"}
+			]
+		}}, "default": {}},
+		{"name": "field_errors", "type": {"type": "map", "values": "string"}, "default": {}},
+		{"name": "section", "type": "string", "default": ""},
+		{"name": "tags", "type": {"type": "array", "items": "string"}, "default": []},
+		{"name": "keywords", "type": {"type": "array", "items": "string"}, "default": []},
+		{"name": "images", "type": {"type": "array", "items": {
+			"type": "record",
+			"name": "Image",
+			"fields": [
+				{"name": "url", "type": "string"},
+				{"name": "alt", "type": "string", "default": ""},
+				{"name": "caption", "type": "string", "default": ""},
+				{"name": "lead", "type": "boolean", "default": false}
+			]
+		}}, "default": []},
+		{"name": "embeds", "type": {"type": "array", "items": {
+			"type": "record",
+			"name": "Embed",
+			"fields": [
+				{"name": "provider", "type": "string"},
+				{"name": "url", "type": "string"},
+				{"name": "type", "type": "string", "default": ""},
+				{"name": "title", "type": "string", "default": ""},
+				{"name": "author_name", "type": "string", "default": ""},
+				{"name": "author_url", "type": "string", "default": ""},
+				{"name": "thumbnail_url", "type": "string", "default": ""}
+			]
+		}}, "default": []},
+		{"name": "html", "type": "string", "default": ""}
+	]
+}`
+
+// KafkaSink publishes each article as a message on a Kafka topic, keyed by the article's
+// canonical URL.
+type KafkaSink struct {
+	writer *kafka.Writer
+	schema avro.Schema // schema is nil when the payload format is JSON.
+}
+
+// NewKafkaSink creates a sink that writes to the given topic on the given brokers.
+// The format must be either "json" or "avro".
+func NewKafkaSink(brokers []string, topic, format string) (*KafkaSink, error) {
+	if len(brokers) == 0 {
+		return nil, fmt.Errorf("kafka: at least one broker is required")
+	}
+	if topic == "" {
+		return nil, fmt.Errorf("kafka: a topic is required")
+	}
+
+	s := &KafkaSink{
+		writer: &kafka.Writer{
+			Addr:     kafka.TCP(brokers...),
+			Topic:    topic,
+			Balancer: &kafka.Hash{}, // Hash on the key so every version of an article lands on the same partition.
+		},
+	}
+
+	switch format {
+	case "", "json":
+		// JSON needs no extra setup.
+	case "avro":
+		schema, err := avro.Parse(articleSchema)
+		if err != nil {
+			return nil, fmt.Errorf("kafka: parsing avro schema: %w", err)
+		}
+		s.schema = schema
+	default:
+		return nil, fmt.Errorf("kafka: unsupported payload format %q (want json or avro)", format)
+	}
+
+	return s, nil
+}
+
+// Publish encodes the article and writes it to the configured topic.
+func (s *KafkaSink) Publish(ctx context.Context, a Article) error {
+	msg, err := s.message(a)
+	if err != nil {
+		return err
+	}
+	return s.writer.WriteMessages(ctx, msg)
+}
+
+// message encodes the article as a message keyed by its canonical URL, or by its URL if
+// the page declares none, so that tracking-parameter variants and AMP copies of one
+// article share a partition and a compacted key.
+func (s *KafkaSink) message(a Article) (kafka.Message, error) {
+	var payload []byte
+	var err error
+	if s.schema != nil {
+		payload, err = avro.Marshal(s.schema, a)
+	} else {
+		payload, err = json.Marshal(a)
+	}
+	if err != nil {
+		return kafka.Message{}, fmt.Errorf("kafka: encoding article: %w", err)
+	}
+	key := a.CanonicalURL
+	if key == "" {
+		key = a.URL
+	}
+	return kafka.Message{Key: []byte(key), Value: payload}, nil
+}
+
+// Close flushes pending messages and closes the underlying writer.
+func (s *KafkaSink) Close() error {
+	return s.writer.Close()
+}
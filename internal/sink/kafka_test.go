@@ -0,0 +1,31 @@
+package sink
+
+import "testing"
+
+// TestKafkaMessageKey checks that messages are keyed by the canonical URL when the page
+// declares one, and by the scraped URL otherwise.
+func TestKafkaMessageKey(t *testing.T) {
+	for _, format := range []string{"json", "avro"} {
+		s, err := NewKafkaSink([]string{"localhost:9092"}, "articles", format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tc := range []struct {
+			a    Article
+			want string
+		}{
+			{Article{SchemaVersion: SchemaVersion, URL: "https://example.com/story?utm_source=x", CanonicalURL: "https://example.com/story"}, "https://example.com/story"},
+			{Article{SchemaVersion: SchemaVersion, URL: "https://example.com/amp/story", CanonicalURL: "https://example.com/story"}, "https://example.com/story"},
+			{Article{SchemaVersion: SchemaVersion, URL: "https://example.com/other"}, "https://example.com/other"},
+		} {
+			msg, err := s.message(tc.a)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(msg.Key) != tc.want {
+				t.Errorf("%s: key of %s = %s, want %s", format, tc.a.URL, msg.Key, tc.want)
+			}
+		}
+		s.Close()
+	}
+}
@@ -0,0 +1,77 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// NATSSink publishes article JSON on a NATS subject.
+// When a JetStream stream name is configured, messages are persisted in that stream
+// so consumers that are offline at publish time can still read them.
+type NATSSink struct {
+	conn    *nats.Conn
+	js      jetstream.JetStream // js is nil for core (fire-and-forget) NATS publishing.
+	subject string
+}
+
+// NewNATSSink connects to the NATS server at url and publishes to subject.
+// If stream is non-empty, a JetStream stream with that name capturing subject is
+// created (or updated) and every publish waits for the server's acknowledgement.
+func NewNATSSink(url, subject, stream string) (*NATSSink, error) {
+	if subject == "" {
+		return nil, fmt.Errorf("nats: a results subject is required")
+	}
+
+	conn, err := nats.Connect(url)
+	if err != nil {
+		return nil, fmt.Errorf("nats: connecting: %w", err)
+	}
+	s := &NATSSink{conn: conn, subject: subject}
+
+	if stream != "" {
+		js, err := jetstream.New(conn)
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("nats: enabling jetstream: %w", err)
+		}
+		_, err = js.CreateOrUpdateStream(context.Background(), jetstream.StreamConfig{
+			Name:     stream,
+			Subjects: []string{subject},
+		})
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("nats: configuring stream %q: %w", stream, err)
+		}
+		s.js = js
+	}
+
+	return s, nil
+}
+
+// Publish sends the article JSON to the configured subject.
+func (s *NATSSink) Publish(ctx context.Context, a Article) error {
+	payload, err := json.Marshal(a)
+	if err != nil {
+		return fmt.Errorf("nats: encoding article: %w", err)
+	}
+
+	if s.js != nil {
+		if _, err := s.js.Publish(ctx, s.subject, payload); err != nil {
+			return fmt.Errorf("nats: publishing to stream: %w", err)
+		}
+		return nil
+	}
+	if err := s.conn.Publish(s.subject, payload); err != nil {
+		return fmt.Errorf("nats: publishing to %q: %w", s.subject, err)
+	}
+	return nil
+}
+
+// Close flushes buffered messages and closes the connection.
+func (s *NATSSink) Close() error {
+	return s.conn.Drain()
+}
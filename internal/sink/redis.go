@@ -0,0 +1,60 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisSink publishes article JSON to a Redis pub/sub channel, a Redis stream, or both.
+// Pub/sub delivers only to currently connected subscribers; streams keep a history
+// that late consumers can read from.
+type RedisSink struct {
+	client  *redis.Client
+	channel string
+	stream  string
+}
+
+// NewRedisSink creates a sink for the Redis server at addr.
+// At least one of channel or stream must be set.
+func NewRedisSink(addr, channel, stream string) (*RedisSink, error) {
+	if channel == "" && stream == "" {
+		return nil, fmt.Errorf("redis: a channel or a stream is required")
+	}
+	return &RedisSink{
+		client:  redis.NewClient(&redis.Options{Addr: addr}),
+		channel: channel,
+		stream:  stream,
+	}, nil
+}
+
+// Publish sends the article JSON to the configured channel and/or stream.
+func (s *RedisSink) Publish(ctx context.Context, a Article) error {
+	payload, err := json.Marshal(a)
+	if err != nil {
+		return fmt.Errorf("redis: encoding article: %w", err)
+	}
+
+	if s.channel != "" {
+		if err := s.client.Publish(ctx, s.channel, payload).Err(); err != nil {
+			return fmt.Errorf("redis: publishing to %q: %w", s.channel, err)
+		}
+	}
+	if s.stream != "" {
+		err := s.client.XAdd(ctx, &redis.XAddArgs{
+			Stream: s.stream,
+			Values: map[string]any{"url": a.URL, "article": payload},
+		}).Err()
+		if err != nil {
+			return fmt.Errorf("redis: adding to stream %q: %w", s.stream, err)
+		}
+	}
+	return nil
+}
+
+// Close closes the connection pool to the Redis server.
+func (s *RedisSink) Close() error {
+	return s.client.Close()
+}
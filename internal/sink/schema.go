@@ -0,0 +1,43 @@
+package sink
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// SchemaVersion is the version of the JSON Schema that every emitted Article conforms to.
+// Bump it, and add a new schema file, whenever a change would break existing consumers.
+const SchemaVersion = "1"
+
+// schemaJSON is the JSON Schema for the current SchemaVersion.
+//
+//go:embed schema/article.v1.json
+var schemaJSON []byte
+
+// compiledSchema is schemaJSON ready for validation.
+var compiledSchema = jsonschema.MustCompileString("article.v1.json", string(schemaJSON))
+
+// Schema returns the JSON Schema document for the current SchemaVersion.
+func Schema() []byte {
+	return schemaJSON
+}
+
+// Validate checks the article's JSON encoding against the schema, so a record that would
+// surprise downstream consumers is caught before it is published anywhere.
+func Validate(a Article) error {
+	data, err := json.Marshal(a)
+	if err != nil {
+		return fmt.Errorf("schema: encoding article: %w", err)
+	}
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("schema: %w", err)
+	}
+	if err := compiledSchema.Validate(v); err != nil {
+		return fmt.Errorf("schema: article does not match v%s: %w", SchemaVersion, err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+// Package sink provides destinations that scraped articles can be published to,
+// such as message brokers or streaming pipelines.
+package sink
+
+import (
+	"context"
+
+	"github.com/hail2skins/zero-scraper/internal/embed"
+	"github.com/hail2skins/zero-scraper/internal/outlets"
+	"github.com/hail2skins/zero-scraper/internal/provenance"
+)
+
+// Article is the record published to a sink for every scraped article.
+type Article struct {
+	// SchemaVersion is the version of the output schema this record conforms to.
+	SchemaVersion string `json:"schema_version" avro:"schema_version"`
+	// URL is the address the article was scraped from. It is the Kafka message key of a page
+	// that declares no canonical URL.
+	URL string `json:"url" avro:"url"`
+	// FinalURL is the address the page was served from after redirects.
+	FinalURL string `json:"final_url,omitempty" avro:"final_url"`
+	// CanonicalURL is the page's declared canonical address; empty if it declares none.
+	// It is the Kafka message key of a page that declares one.
+	CanonicalURL string `json:"canonical_url,omitempty" avro:"canonical_url"`
+	// Title is the article's headline; empty if none was found.
+	Title string `json:"title,omitempty" avro:"title"`
+	// Published is when the article was published, in RFC 3339 form in UTC; empty if
+	// the page does not say.
+	Published string `json:"published,omitempty" avro:"published"`
+	// Publisher names the outlet; empty if no extractor found it.
+	Publisher string `json:"publisher,omitempty" avro:"publisher"`
+	// Content is the extracted article text.
+	Content string `json:"content" avro:"content"`
+	// Byline is the extracted author information.
+	Byline string `json:"byline" avro:"byline"`
+	// Source is the user-maintained metadata of the outlet the article came from. It is
+	// nil unless a source metadata file is configured and lists the outlet.
+	Source *outlets.Outlet `json:"source,omitempty" avro:"source"`
+	// Robots records the page's robots directives and how the robots policy treated them.
+	// It is nil when the policy is "ignore".
+	Robots *Robots `json:"robots,omitempty" avro:"robots"`
+	// Provenance is a signature over the content hash, URL, and fetch time.
+	// It is nil unless a signing key is configured.
+	Provenance *provenance.Signature `json:"provenance,omitempty" avro:"provenance"`
+	// Confidence rates each extracted field ("title", "content", "byline", "published", "publisher") and says how it was derived.
+	Confidence map[string]Confidence `json:"confidence,omitempty" avro:"confidence"`
+	// FieldErrors says, per extracted field that came back empty, why. A record published
+	// with required fields missing, as -partial allows, lists them here.
+	FieldErrors map[string]string `json:"field_errors,omitempty" avro:"field_errors"`
+	// Section is the desk the page files the article under; empty if it does not say.
+	Section string `json:"section,omitempty" avro:"section"`
+	// Tags are the topics the page tags the article with.
+	Tags []string `json:"tags,omitempty" avro:"tags"`
+	// Keywords are the keywords the page declares in its metadata.
+	Keywords []string `json:"keywords,omitempty" avro:"keywords"`
+	// Images are the article's lead image and the pictures in its body.
+	Images []Image `json:"images,omitempty" avro:"images"`
+	// Embeds are the third-party embeds found in the page, resolved through oEmbed.
+	// It is empty unless embed resolution is enabled.
+	Embeds []embed.Embed `json:"embeds,omitempty" avro:"embeds"`
+	// HTML is the raw page the article was extracted from. It is empty unless raw HTML
+	// was requested, since it is usually far larger than the rest of the record.
+	HTML string `json:"html,omitempty" avro:"html"`
+}
+
+// Robots is the robots-meta metadata attached to an article.
+type Robots struct {
+	// Directives are the page's lowercase robots directives, e.g. "noarchive".
+	Directives []string `json:"directives" avro:"directives"`
+	// Decision is what the policy did with the page: "published", "skipped" (a
+	// noarchive page under the "respect" policy), or "no-snippet" (published without
+	// an excerpt because the page is nosnippet).
+	Decision string `json:"decision" avro:"decision"`
+}
+
+// Image is a picture that belongs to the article.
+type Image struct {
+	URL string `json:"url" avro:"url"`
+	// Alt is the image's alternative text.
+	Alt string `json:"alt,omitempty" avro:"alt"`
+	// Caption is the caption of the figure holding the image.
+	Caption string `json:"caption,omitempty" avro:"caption"`
+	// Lead marks the hero image declared with og:image.
+	Lead bool `json:"lead,omitempty" avro:"lead"`
+}
+
+// Confidence is how far an extracted field can be trusted.
+type Confidence struct {
+	// Score runs from 0 (a guess) to 1 (certain).
+	Score float64 `json:"score" avro:"score"`
+	// Source says how the value was derived, e.g. "selector:div.Page-authors".
+	Source string `json:"source" avro:"source"`
+}
+
+// Robots decisions recorded in article metadata.
+const (
+	RobotsPublished = "published"
+	RobotsSkipped   = "skipped"
+	RobotsNoSnippet = "no-snippet"
+)
+
+// Sink publishes scraped articles to an external system.
+type Sink interface {
+	// Publish sends a single article to the sink.
+	Publish(ctx context.Context, a Article) error
+	// Close flushes any pending messages and releases the sink's resources.
+	Close() error
+}
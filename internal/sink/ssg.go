@@ -0,0 +1,208 @@
+package sink
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hail2skins/zero-scraper/internal/byline"
+	"github.com/hail2skins/zero-scraper/internal/textdir"
+)
+
+// nonSlugChars matches runs of characters that are not allowed in a slug.
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// SSGSink writes each article as a Markdown file with front matter into the content
+// directory of a Hugo or Jekyll site, so a press-clippings site can be built with
+// existing static site generator tooling.
+type SSGSink struct {
+	dir     string
+	format  string
+	section string
+}
+
+// NewSSGSink creates a sink writing into the site rooted at dir.
+// The format must be "hugo" (content/<section>/<slug>.md) or "jekyll" (_posts/<date>-<slug>.md).
+func NewSSGSink(dir, format, section string) (*SSGSink, error) {
+	if format != "hugo" && format != "jekyll" {
+		return nil, fmt.Errorf("ssg: unsupported format %q (want hugo or jekyll)", format)
+	}
+	return &SSGSink{dir: dir, format: format, section: section}, nil
+}
+
+// Publish writes the article file, overwriting any earlier export of the same URL. The
+// file is named after the URL's last path segment and a short hash of the whole URL, so
+// that articles sharing a last segment, such as two sites' index.html, do not collide.
+// Jekyll posts are dated by the article's publication date, or, for an article without
+// one, by the date it was first exported.
+func (s *SSGSink) Publish(_ context.Context, a Article) error {
+	date := time.Now().UTC()
+	if t, err := time.Parse(time.RFC3339, a.Published); err == nil {
+		date = t.UTC()
+	}
+	name := slugFromURL(a.URL)
+	slug := name + "-" + urlHash(a.URL)
+
+	var file string
+	if s.format == "hugo" {
+		file = filepath.Join(s.dir, "content", s.section, slug+".md")
+	} else {
+		file = filepath.Join(s.dir, "_posts", date.Format("2006-01-02")+"-"+slug+".md")
+		// An earlier export may be under another date: keep its name if the article has
+		// no date of its own, and otherwise replace it.
+		earlier, _ := filepath.Glob(filepath.Join(s.dir, "_posts", "[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9]-"+slug+".md"))
+		for _, e := range earlier {
+			if a.Published == "" {
+				file = e
+				if t, err := time.Parse("2006-01-02", filepath.Base(e)[:10]); err == nil {
+					date = t
+				}
+				break
+			}
+			if e != file {
+				if err := os.Remove(e); err != nil {
+					return fmt.Errorf("ssg: %w", err)
+				}
+			}
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return fmt.Errorf("ssg: %w", err)
+	}
+
+	var b strings.Builder
+	b.WriteString("---\n")
+	title := a.Title
+	if title == "" {
+		title = titleFromSlug(name)
+	}
+	frontMatter(&b, "title", title)
+	frontMatter(&b, "date", date.Format(time.RFC3339))
+	frontMatter(&b, "source_url", a.URL)
+	// Themes can use this for the page's dir attribute so right-to-left articles render correctly.
+	if textdir.Of(a.Content) == textdir.RTL {
+		frontMatter(&b, "dir", "rtl")
+	}
+	if s.format == "jekyll" {
+		frontMatter(&b, "layout", "post")
+		frontMatter(&b, "author", a.Byline)
+	}
+	// Both generators treat a list of authors as a taxonomy (Hugo) or a collection key
+	// (Jekyll), and tags and categories as taxonomies.
+	frontMatterList(&b, "authors", byline.ParseURL(a.URL, a.Byline))
+	frontMatterList(&b, "categories", categories(a))
+	frontMatterList(&b, "tags", distinct(append(a.Tags[:len(a.Tags):len(a.Tags)], a.Keywords...)))
+	b.WriteString("---\n\n")
+	// Paragraphs are newline-separated; Markdown needs a blank line between them.
+	for _, p := range strings.Split(a.Content, "\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			b.WriteString(p + "\n\n")
+		}
+	}
+
+	if err := os.WriteFile(file, []byte(b.String()), 0o644); err != nil {
+		return fmt.Errorf("ssg: %w", err)
+	}
+	return nil
+}
+
+// Close does nothing; every article is written as soon as it is published.
+func (s *SSGSink) Close() error {
+	return nil
+}
+
+// frontMatter writes a single "key: value" line with a safely quoted value.
+func frontMatter(b *strings.Builder, key, value string) {
+	b.WriteString(key + ": " + yamlString(value) + "\n")
+}
+
+// frontMatterList writes key as a list of values, or nothing if there are none.
+func frontMatterList(b *strings.Builder, key string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	b.WriteString(key + ":\n")
+	for _, v := range values {
+		b.WriteString("  - " + yamlString(v) + "\n")
+	}
+}
+
+// categories returns the article's section followed by its publisher: the one its page
+// names, else the outlet's name from the source metadata, else the site's host.
+func categories(a Article) []string {
+	publisher := a.Publisher
+	if publisher == "" && a.Source != nil {
+		publisher = a.Source.Name
+	}
+	if publisher == "" {
+		if u, err := url.Parse(a.URL); err == nil {
+			publisher = strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+		}
+	}
+	return distinct([]string{a.Section, publisher})
+}
+
+// distinct returns the non-empty values, trimmed, without case-insensitive repeats.
+func distinct(values []string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" || seen[strings.ToLower(v)] {
+			continue
+		}
+		seen[strings.ToLower(v)] = true
+		out = append(out, v)
+	}
+	return out
+}
+
+// yamlString quotes s as a YAML double-quoted scalar. JSON strings are valid YAML.
+func yamlString(s string) string {
+	q, _ := json.Marshal(s)
+	return string(q)
+}
+
+// slugFromURL derives a file-name-safe slug from the last segment of the URL path, or
+// the one before it when the last is an index page.
+func slugFromURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "article"
+	}
+	p := strings.TrimSuffix(u.Path, "/")
+	base := strings.TrimSuffix(path.Base(p), path.Ext(p))
+	if base == "index" || base == "default" {
+		p = path.Dir(p)
+		base = strings.TrimSuffix(path.Base(p), path.Ext(p))
+	}
+	slug := strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(base), "-"), "-")
+	if slug == "" {
+		slug = strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(u.Host), "-"), "-")
+	}
+	if slug == "" {
+		return "article"
+	}
+	return slug
+}
+
+// urlHash returns a short hash of the URL, telling apart articles whose names are alike.
+func urlHash(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:4])
+}
+
+// titleFromSlug turns "some-article-slug" into "Some article slug" for use as a title.
+func titleFromSlug(slug string) string {
+	t := strings.ReplaceAll(slug, "-", " ")
+	return strings.ToUpper(t[:1]) + t[1:]
+}
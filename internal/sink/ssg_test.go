@@ -0,0 +1,53 @@
+package sink
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestSSGSinkDistinctFiles checks that articles whose URLs end alike get files of their
+// own, and that the front matter carries the taxonomies.
+func TestSSGSinkDistinctFiles(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewSSGSink(dir, "hugo", "clips")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range []Article{
+		{URL: "https://a.example/2024/index.html", Content: "First.", Section: "Politics", Tags: []string{"Elections"}, Keywords: []string{"elections", "Vote"}},
+		{URL: "https://b.example/news/index.html", Content: "Second.", Publisher: "B News"},
+		{URL: "https://c.example/news/index.html", Content: "Third."},
+	} {
+		if err := s.Publish(context.Background(), a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "content", "clips", "*.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("wrote %d files, want 3: %v", len(files), files)
+	}
+
+	var all strings.Builder
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		all.Write(data)
+	}
+	for _, want := range []string{
+		"categories:\n  - \"Politics\"\n  - \"a.example\"\n",
+		"tags:\n  - \"Elections\"\n  - \"Vote\"\n",
+		"categories:\n  - \"B News\"\n",
+	} {
+		if !strings.Contains(all.String(), want) {
+			t.Errorf("front matter lacks %q:\n%s", want, all.String())
+		}
+	}
+}
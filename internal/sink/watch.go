@@ -0,0 +1,426 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hail2skins/zero-scraper/internal/provenance"
+)
+
+// MinWatchInterval is the shortest interval at which a watched URL is scraped again.
+const MinWatchInterval = time.Minute
+
+// Watch is a client's interest in one article: its webhook is told whenever the hash of
+// the article's content changes from one scrape to the next.
+type Watch struct {
+	URL     string `json:"url"`
+	Webhook string `json:"webhook"`
+	// Interval, if set, is how often serve mode scrapes the URL again to look for
+	// changes. Without it, the article is compared whenever it is scraped anyway. It is
+	// kept in Go duration syntax, e.g. "1h".
+	Interval time.Duration `json:"-"`
+	// ContentHash is the hash of the content last seen, as provenance.HashContent gives
+	// it; empty until the article is first scraped.
+	ContentHash string `json:"content_hash,omitempty"`
+	// Checked is when the article was last scraped, or queued for it.
+	Checked time.Time `json:"checked,omitzero"`
+	// Changed is when the webhook was last told of a change.
+	Changed time.Time `json:"changed,omitzero"`
+	// ETag and LastModified are the validators the article was last served with, which
+	// interval scrapes send so that an unchanged page is not fetched again.
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// MarshalJSON writes the interval in Go duration syntax, as clients give it.
+func (w Watch) MarshalJSON() ([]byte, error) {
+	type plain Watch
+	f := struct {
+		plain
+		Interval string `json:"interval,omitempty"`
+	}{plain: plain(w)}
+	if w.Interval > 0 {
+		f.Interval = w.Interval.String()
+	}
+	return json.Marshal(f)
+}
+
+// UnmarshalJSON reads a watch as MarshalJSON writes it.
+func (w *Watch) UnmarshalJSON(data []byte) error {
+	type plain Watch
+	var f struct {
+		plain
+		Interval string `json:"interval"`
+	}
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+	*w = Watch(f.plain)
+	if f.Interval != "" {
+		d, err := time.ParseDuration(f.Interval)
+		if err != nil {
+			return err
+		}
+		w.Interval = d
+	}
+	return nil
+}
+
+// watchEvent is the body POSTed to a webhook.
+type watchEvent struct {
+	URL          string  `json:"url"`
+	PreviousHash string  `json:"previous_hash"`
+	ContentHash  string  `json:"content_hash"`
+	Article      Article `json:"article"`
+}
+
+// WatchSink keeps the watches of serve mode's clients in a file and calls their webhooks
+// when a scraped article's content has changed. Unlike the other sinks, it is not
+// published to: Observe is called for every article scraped, before duplicates are
+// dropped, since a changed copy of an article is what a duplicate rule would drop. The
+// first scrape of a watched article only records its hash. Webhooks are called in the
+// background, so a slow one does not hold up scraping; one that fails is called again
+// after the next scrape, as the change has not been delivered. It is safe for concurrent
+// use.
+type WatchSink struct {
+	mu       sync.Mutex
+	path     string
+	watches  []Watch         // watches are ordered by URL, then webhook.
+	inFlight map[string]bool // inFlight holds the watches whose change is being delivered, by watchKey.
+	client   *http.Client
+
+	deliveries chan delivery
+	ctx        context.Context // ctx is cancelled by Close, abandoning the deliveries left.
+	cancel     context.CancelFunc
+	done       chan struct{} // done is closed once the deliveries have stopped.
+}
+
+// delivery is a change waiting to be delivered to a watch's webhook.
+type delivery struct {
+	watch Watch
+	hash  string
+	a     Article
+}
+
+// maxDeliveries is how many changes may wait to be delivered. Changes beyond it are
+// delivered after the article's next scrape.
+const maxDeliveries = 256
+
+// watchKey identifies webhook's watch of url.
+func watchKey(url, webhook string) string {
+	return url + "\x00" + webhook
+}
+
+// NewWatchSink loads the watches kept at path, starting with none if the file does not
+// exist yet, and starts delivering changes to their webhooks.
+func NewWatchSink(path string) (*WatchSink, error) {
+	s := &WatchSink{
+		path:       path,
+		inFlight:   make(map[string]bool),
+		client:     &http.Client{Timeout: 30 * time.Second},
+		deliveries: make(chan delivery, maxDeliveries),
+		done:       make(chan struct{}),
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("watch: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &s.watches); err != nil {
+			return nil, fmt.Errorf("watch: %s: %w", path, err)
+		}
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	go s.deliver()
+	return s, nil
+}
+
+// Observe compares a scraped article, served with the validators etag and lastModified,
+// with what its watches last saw, and queues the change for their webhooks if its
+// content changed.
+func (s *WatchSink) Observe(a Article, etag, lastModified string) error {
+	hash := provenance.HashContent(a.Content)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var watched bool
+	for i := range s.watches {
+		w := &s.watches[i]
+		if w.URL != a.URL {
+			continue
+		}
+		watched = true
+		w.Checked = time.Now().UTC()
+		w.ETag, w.LastModified = etag, lastModified
+		if w.ContentHash == "" {
+			w.ContentHash = hash
+			continue
+		}
+		key := watchKey(w.URL, w.Webhook)
+		if w.ContentHash == hash || s.inFlight[key] {
+			continue
+		}
+		select {
+		case s.deliveries <- delivery{watch: *w, hash: hash, a: a}:
+			s.inFlight[key] = true
+		default:
+			log.Printf("Too many watch changes waiting; %s will be told of %s after its next scrape", w.Webhook, w.URL)
+		}
+	}
+	if !watched {
+		return nil
+	}
+	return s.save()
+}
+
+// deliver calls the webhooks of queued changes, one at a time, until Close.
+func (s *WatchSink) deliver() {
+	defer close(s.done)
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case d := <-s.deliveries:
+			err := s.notify(s.ctx, d.watch, d.hash, d.a)
+			s.mu.Lock()
+			delete(s.inFlight, watchKey(d.watch.URL, d.watch.Webhook))
+			if err == nil {
+				if i, ok := s.find(d.watch.URL, d.watch.Webhook); ok && s.watches[i].ContentHash == d.watch.ContentHash {
+					s.watches[i].ContentHash, s.watches[i].Changed = d.hash, time.Now().UTC()
+				}
+				err = s.save()
+			} else {
+				err = fmt.Errorf("watch: calling %s: %w", d.watch.Webhook, err)
+			}
+			s.mu.Unlock()
+			if err != nil {
+				log.Printf("Error delivering change of %s: %v", d.watch.URL, err)
+			}
+		}
+	}
+}
+
+// notify POSTs the change of w's article to its webhook.
+func (s *WatchSink) notify(ctx context.Context, w Watch, hash string, a Article) error {
+	payload, err := json.Marshal(watchEvent{URL: w.URL, PreviousHash: w.ContentHash, ContentHash: hash, Article: a})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.Webhook, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// Due returns a watch of each watched URL whose interval has passed since it was last
+// checked, and marks them checked, so that each is returned once per interval. The
+// validators of the watch returned are empty if any watch of its URL has not seen the
+// article yet, as that watch needs the page whether it changed or not.
+func (s *WatchSink) Due(now time.Time) ([]Watch, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var due []Watch
+	seen := make(map[string]int)
+	for _, w := range s.watches {
+		if w.Interval <= 0 || now.Sub(w.Checked) < w.Interval {
+			continue
+		}
+		i, ok := seen[w.URL]
+		if !ok {
+			seen[w.URL] = len(due)
+			due = append(due, w)
+			i = len(due) - 1
+		}
+		if w.ContentHash == "" {
+			due[i].ETag, due[i].LastModified = "", ""
+		}
+	}
+	for i := range s.watches {
+		if _, ok := seen[s.watches[i].URL]; ok {
+			s.watches[i].Checked = now.UTC()
+		}
+	}
+	if len(due) == 0 {
+		return nil, nil
+	}
+	return due, s.save()
+}
+
+// watchRequest is the JSON body accepted by POST and DELETE /watches.
+type watchRequest struct {
+	URLs    []string `json:"urls"`
+	Webhook string   `json:"webhook"`
+	// Interval is in Go duration syntax, e.g. "1h"; POST only.
+	Interval string `json:"interval,omitempty"`
+}
+
+// ServeHTTP lists the watches on GET, adds them on POST, and removes them on DELETE, the
+// latter two taking a JSON body such as
+//
+//	{"urls": ["https://example.com/story"], "webhook": "https://client.example/changed", "interval": "1h"}
+func (s *WatchSink) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		s.mu.Lock()
+		data, err := json.Marshal(map[string][]Watch{"watches": s.watches})
+		s.mu.Unlock()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(append(data, '\n'))
+		return
+	}
+
+	var req watchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	var urls []string
+	for _, u := range req.URLs {
+		if u = strings.TrimSpace(u); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	if len(urls) == 0 {
+		http.Error(w, "no URLs provided", http.StatusBadRequest)
+		return
+	}
+	if !strings.HasPrefix(req.Webhook, "http://") && !strings.HasPrefix(req.Webhook, "https://") {
+		http.Error(w, "webhook must be an http or https URL", http.StatusBadRequest)
+		return
+	}
+
+	var n int
+	var err error
+	switch r.Method {
+	case http.MethodPost:
+		var interval time.Duration
+		if req.Interval != "" {
+			if interval, err = time.ParseDuration(req.Interval); err != nil || interval < MinWatchInterval {
+				http.Error(w, fmt.Sprintf("interval must be a duration of at least %s", MinWatchInterval), http.StatusBadRequest)
+				return
+			}
+		}
+		n, err = s.add(urls, req.Webhook, interval)
+	case http.MethodDelete:
+		n, err = s.remove(urls, req.Webhook)
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	key := "added"
+	if r.Method == http.MethodDelete {
+		key = "removed"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{key: n})
+}
+
+// add watches urls for webhook, or changes the interval of the watches it has, and returns
+// how many watches are new.
+func (s *WatchSink) add(urls []string, webhook string, interval time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var n int
+	for _, u := range urls {
+		if i, ok := s.find(u, webhook); ok {
+			s.watches[i].Interval = interval
+			continue
+		}
+		s.watches = append(s.watches, Watch{URL: u, Webhook: webhook, Interval: interval})
+		n++
+	}
+	sort.Slice(s.watches, func(i, j int) bool {
+		if s.watches[i].URL != s.watches[j].URL {
+			return s.watches[i].URL < s.watches[j].URL
+		}
+		return s.watches[i].Webhook < s.watches[j].Webhook
+	})
+	return n, s.save()
+}
+
+// remove stops webhook watching urls and returns how many watches it had.
+func (s *WatchSink) remove(urls []string, webhook string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	drop := make(map[string]bool, len(urls))
+	for _, u := range urls {
+		drop[u] = true
+	}
+	kept := s.watches[:0]
+	for _, w := range s.watches {
+		if !drop[w.URL] || w.Webhook != webhook {
+			kept = append(kept, w)
+		}
+	}
+	n := len(s.watches) - len(kept)
+	s.watches = kept
+	return n, s.save()
+}
+
+// find returns the index of webhook's watch of url. The caller must hold s.mu.
+func (s *WatchSink) find(url, webhook string) (int, bool) {
+	for i, w := range s.watches {
+		if w.URL == url && w.Webhook == webhook {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// save writes the watches to a temporary file and renames it into place. The caller must
+// hold s.mu.
+func (s *WatchSink) save() error {
+	data, err := json.MarshalIndent(s.watches, "", "  ")
+	if err != nil {
+		return fmt.Errorf("watch: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".watches-*.json")
+	if err != nil {
+		return fmt.Errorf("watch: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("watch: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("watch: %w", err)
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// Close stops delivering changes. Those not delivered yet are delivered after their
+// articles' next scrape. The watches are saved as they change, so nothing else is left.
+func (s *WatchSink) Close() error {
+	s.cancel()
+	<-s.done
+	return nil
+}
@@ -0,0 +1,127 @@
+// Package siteconfig loads selector rules for news sites from a directory of YAML
+// files, so that support for a new outlet can be added by dropping a file in place
+// rather than changing the scraper. Each file describes one outlet:
+//
+//	# sites.d/example.yaml
+//	domains: [example.com, example.co.uk]
+//	content: div.story-body p
+//	byline: span.byline
+//	byline_names: a
+//	title: h1.headline
+//	date: time.published
+//
+// A rule applies to its domains and their subdomains. Every selector is optional; the
+// fields a file leaves out are found by the scraper's other extractors. A selector
+// starting with "xpath:" is an XPath expression, for text CSS cannot select:
+//
+//	byline: "xpath://span[@class='by']/following-sibling::text()[1]"
+package siteconfig
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/hail2skins/zero-scraper/pkg/scraper"
+)
+
+// Site is the selector rule of one outlet.
+type Site struct {
+	// Domains are the sites the rule applies to, subdomains included.
+	Domains []string `yaml:"domains"`
+	// Domain is shorthand for a single entry in Domains.
+	Domain      string `yaml:"domain"`
+	Content     string `yaml:"content"`
+	Byline      string `yaml:"byline"`
+	BylineNames string `yaml:"byline_names"`
+	Title       string `yaml:"title"`
+	Date        string `yaml:"date"`
+	// File is the file the rule was read from, for error messages.
+	File string `yaml:"-"`
+}
+
+// Extractor returns the extractor the rule describes.
+func (s Site) Extractor() scraper.SelectorExtractor {
+	return scraper.SelectorExtractor{Content: s.Content, Byline: s.Byline, BylineNames: s.BylineNames, Title: s.Title, Published: s.Date}
+}
+
+// Load reads every .yaml and .yml file in dir, in name order. A domain claimed by two
+// files, a file with no domain or no selector, and a selector that does not parse are
+// errors, so a mistake is caught at startup rather than as empty articles.
+func Load(dir string) ([]Site, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("siteconfig: %w", err)
+	}
+	var names []string
+	for _, e := range entries {
+		if ext := strings.ToLower(filepath.Ext(e.Name())); !e.IsDir() && (ext == ".yaml" || ext == ".yml") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+
+	var sites []Site
+	claimed := make(map[string]string) // claimed maps each domain to the file that gave it.
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		s, err := read(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range s.Domains {
+			if other, dup := claimed[d]; dup {
+				return nil, fmt.Errorf("siteconfig: %s and %s both configure %s", other, path, d)
+			}
+			claimed[d] = path
+		}
+		sites = append(sites, s)
+	}
+	return sites, nil
+}
+
+// read reads and checks one site file.
+func read(path string) (Site, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Site{}, fmt.Errorf("siteconfig: %w", err)
+	}
+	var s Site
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&s); err != nil {
+		return Site{}, fmt.Errorf("siteconfig: reading %s: %w", path, err)
+	}
+	s.File = path
+	if s.Domain != "" {
+		s.Domains = append(s.Domains, s.Domain)
+		s.Domain = ""
+	}
+	for i, d := range s.Domains {
+		s.Domains[i] = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(d)), "www.")
+	}
+	if len(s.Domains) == 0 {
+		return Site{}, fmt.Errorf("siteconfig: %s names no domain", path)
+	}
+	selectors := map[string]string{"content": s.Content, "byline": s.Byline, "byline_names": s.BylineNames, "title": s.Title, "date": s.Date}
+	found := false
+	for field, sel := range selectors {
+		if sel == "" {
+			continue
+		}
+		found = true
+		// goquery treats a selector it cannot parse as matching nothing, so check here.
+		if err := scraper.ValidateSelector(sel); err != nil {
+			return Site{}, fmt.Errorf("siteconfig: %s: %s selector %q: %w", path, field, sel, err)
+		}
+	}
+	if !found {
+		return Site{}, fmt.Errorf("siteconfig: %s gives no selectors", path)
+	}
+	return s, nil
+}
@@ -0,0 +1,87 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// AMQPSource consumes URLs from an AMQP queue (e.g. RabbitMQ), one URL per message body.
+// Messages are acknowledged only after the handler succeeds; failed messages are
+// rejected without requeueing so a dead-letter exchange configured on the queue can collect them.
+type AMQPSource struct {
+	conn  *amqp.Connection
+	ch    *amqp.Channel
+	queue string
+}
+
+// NewAMQPSource connects to the broker at addr and prepares to consume from queue.
+// The queue is declared durable if it does not already exist.
+func NewAMQPSource(addr, queue string) (*AMQPSource, error) {
+	if queue == "" {
+		return nil, fmt.Errorf("amqp: a queue name is required")
+	}
+
+	conn, err := amqp.Dial(addr)
+	if err != nil {
+		return nil, fmt.Errorf("amqp: connecting: %w", err)
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("amqp: opening channel: %w", err)
+	}
+
+	// Only take one unacknowledged message at a time so work is spread across workers.
+	if err := ch.Qos(1, 0, false); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("amqp: setting prefetch: %w", err)
+	}
+	if _, err := ch.QueueDeclare(queue, true, false, false, false, nil); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("amqp: declaring queue %q: %w", queue, err)
+	}
+
+	return &AMQPSource{conn: conn, ch: ch, queue: queue}, nil
+}
+
+// Run consumes messages until ctx is cancelled or the broker closes the delivery channel.
+func (s *AMQPSource) Run(ctx context.Context, h Handler) error {
+	deliveries, err := s.ch.ConsumeWithContext(ctx, s.queue, "", false, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("amqp: consuming from %q: %w", s.queue, err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case d, ok := <-deliveries:
+			if !ok {
+				return fmt.Errorf("amqp: delivery channel closed")
+			}
+
+			url := strings.TrimSpace(string(d.Body))
+			if _, err := h(ctx, Request{URL: url}); err != nil {
+				log.Printf("Error handling %s: %v", url, err)
+				// Reject without requeueing so a poison URL cannot loop forever.
+				if err := d.Nack(false, false); err != nil {
+					return fmt.Errorf("amqp: nack: %w", err)
+				}
+				continue
+			}
+			if err := d.Ack(false); err != nil {
+				return fmt.Errorf("amqp: ack: %w", err)
+			}
+		}
+	}
+}
+
+// Close closes the channel and the connection to the broker.
+func (s *AMQPSource) Close() error {
+	s.ch.Close()
+	return s.conn.Close()
+}
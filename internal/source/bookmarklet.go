@@ -0,0 +1,163 @@
+package source
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// maxBookmarkBytes caps the size of a page captured by the bookmarklet.
+const maxBookmarkBytes = 16 << 20
+
+// bookmarkletPage is served at GET /bookmarklet. Dragging either link to the bookmarks bar
+// installs a bookmarklet that sends the current page to this scraper.
+var bookmarkletPage = template.Must(template.New("bookmarklet").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>zero-scraper bookmarklet</title></head>
+<body>
+<h1>zero-scraper bookmarklet</h1>
+<p>Drag a link to your bookmarks bar, then click it on any article.</p>
+<ul>
+<li><a href="{{.URLOnly}}">Scrape this page</a> &mdash; sends only the URL; the scraper fetches the page itself.</li>
+<li><a href="{{.WithDOM}}">Scrape this page (with DOM)</a> &mdash; also sends the page as rendered in your browser, for logged-in or paywalled pages.</li>
+</ul>
+</body>
+</html>
+`))
+
+// LoadBookmarkToken reads the bookmarklet token kept at path, creating the file with a
+// new random token if it does not exist yet. The token is generated once per install so
+// that installed bookmarklets keep working across restarts.
+func LoadBookmarkToken(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		if token := strings.TrimSpace(string(data)); token != "" {
+			return token, nil
+		}
+		return "", fmt.Errorf("bookmarklet: %s is empty", path)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("bookmarklet: %w", err)
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("bookmarklet: %w", err)
+	}
+	token := hex.EncodeToString(b)
+	if err := os.WriteFile(path, []byte(token+"\n"), 0o600); err != nil {
+		return "", fmt.Errorf("bookmarklet: %w", err)
+	}
+	return token, nil
+}
+
+// SetBookmarkToken enables the bookmarklet, whose requests must carry token. Any page
+// open in the user's browser can post to a local address, so the token is what tells
+// the bookmarklet's requests apart. It must be called before Run.
+func (s *HTTPSource) SetBookmarkToken(token string) {
+	s.bookmarkToken = token
+}
+
+// bookmarkletJS builds the javascript: URL for a bookmarklet posting to endpoint with
+// token. The body is sent as a form so the browser does not need a CORS preflight.
+func bookmarkletJS(endpoint, token string, withDOM bool) template.URL {
+	js := "javascript:(function(){var d=new URLSearchParams();d.append('token','" + token + "');d.append('url',location.href);"
+	if withDOM {
+		js += "d.append('html',document.documentElement.outerHTML);"
+	}
+	js += "fetch('" + endpoint + "',{method:'POST',mode:'no-cors',body:d})" +
+		".then(function(){alert('Sent to zero-scraper')},function(e){alert('zero-scraper: '+e)});})();"
+	return template.URL(js)
+}
+
+// handleBookmarkletPage serves a page with the generated bookmarklets. It holds the
+// token, so it is only served to local clients that asked for a loopback host: a page
+// whose own host name resolves to this machine is still another site.
+func (s *HTTPSource) handleBookmarkletPage(w http.ResponseWriter, r *http.Request) {
+	if s.bookmarkToken == "" {
+		http.Error(w, "the bookmarklet is not enabled", http.StatusNotFound)
+		return
+	}
+	if !isLoopback(r.RemoteAddr) || !isLoopbackHost(r.Host) {
+		http.Error(w, "the bookmarklet page is only served to local requests", http.StatusForbidden)
+		return
+	}
+	endpoint := "http://" + r.Host + "/bookmark"
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	bookmarkletPage.Execute(w, map[string]template.URL{
+		"URLOnly": bookmarkletJS(endpoint, s.bookmarkToken, false),
+		"WithDOM": bookmarkletJS(endpoint, s.bookmarkToken, true),
+	})
+}
+
+// handleBookmark accepts a page sent by the bookmarklet and queues it for extraction.
+// Only loopback clients sending the bookmarklet's token are accepted, since captured DOMs
+// can contain session data and any site the user visits can post to a local address.
+func (s *HTTPSource) handleBookmark(w http.ResponseWriter, r *http.Request) {
+	if s.bookmarkToken == "" {
+		http.Error(w, "the bookmarklet is not enabled", http.StatusNotFound)
+		return
+	}
+	if !isLoopback(r.RemoteAddr) {
+		http.Error(w, "the bookmark endpoint only accepts local requests", http.StatusForbidden)
+		return
+	}
+	// The bookmarklet cannot send an API key, so no tenant could be charged for the page.
+	if s.tenants != nil {
+		http.Error(w, "the bookmark endpoint is not available to tenants", http.StatusForbidden)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxBookmarkBytes)
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(r.PostForm.Get("token")), []byte(s.bookmarkToken)) != 1 {
+		http.Error(w, "missing or wrong bookmarklet token; reinstall the bookmarklet", http.StatusForbidden)
+		return
+	}
+	url := strings.TrimSpace(r.PostForm.Get("url"))
+	if url == "" {
+		http.Error(w, "no URL provided", http.StatusBadRequest)
+		return
+	}
+
+	// Someone is waiting in the browser, so jump ahead of queued batches.
+	if !s.queue.push(PriorityHigh, job{req: Request{URL: url, HTML: r.PostForm.Get("html")}}) {
+		http.Error(w, "queue is full, try again later", http.StatusServiceUnavailable)
+		return
+	}
+	// The bookmarklet posts in no-cors mode and never reads the response, so no page is
+	// allowed to read it either.
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// isLoopback reports whether a request's remote address is on the local machine.
+func isLoopback(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// isLoopbackHost reports whether a request's Host header names the local machine.
+func isLoopbackHost(hostport string) bool {
+	host := hostport
+	if h, _, err := net.SplitHostPort(hostport); err == nil {
+		host = h
+	}
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(strings.Trim(host, "[]"))
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,369 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"log"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hail2skins/zero-scraper/internal/access"
+	"github.com/hail2skins/zero-scraper/internal/sink"
+)
+
+// queueSize is the number of URLs the HTTP source will hold before rejecting new batches.
+const queueSize = 1024
+
+// intakeRequest is the JSON body accepted by POST /urls.
+type intakeRequest struct {
+	// URLs are the article addresses to scrape.
+	URLs []string `json:"urls"`
+	// Callback, if set, receives a POST with the results once every URL in the batch is done.
+	// It must be an http or https URL. The server POSTs to it from its own network, so
+	// anyone allowed to submit can make it call internal addresses: give submit keys only
+	// to trusted clients, or firewall the server's outbound requests.
+	Callback string `json:"callback,omitempty"`
+	// Priority is "low", "normal" (the default), or "high".
+	Priority string `json:"priority,omitempty"`
+}
+
+// intakeResult is the per-URL entry in the callback payload.
+type intakeResult struct {
+	sink.Article
+	// Error is set when the URL could not be scraped.
+	Error string `json:"error,omitempty"`
+}
+
+// batch tracks the URLs submitted in one intake request so the callback fires once.
+type batch struct {
+	mu       sync.Mutex
+	callback string
+	pending  int
+	results  []intakeResult
+}
+
+// job is a single queued request and the batch it belongs to.
+// batch is nil for requests that were not part of an intake batch.
+type job struct {
+	req   Request
+	batch *batch
+}
+
+// HTTPSource runs the scraper in serve mode: external systems push batches of URLs
+// to an HTTP endpoint, the URLs are queued and scraped in the background, and the
+// results are optionally POSTed back to a callback URL.
+type HTTPSource struct {
+	server *http.Server
+	mux    *http.ServeMux
+	queue  *jobQueue
+	client *http.Client
+	// tenants, if set, admits every intake request, for a process shared by several tenants.
+	tenants Tenants
+	// keys, if set, give the role of every request's API key, which must be enough for
+	// the endpoint requested.
+	keys access.Keys
+	// watches, if set, are the articles clients watch for changes, scraped again as their
+	// intervals come due.
+	watches *sink.WatchSink
+	// workers is how many queued jobs are processed at a time.
+	workers int
+	// bookmarkToken, if set, enables the bookmarklet and is the secret its requests carry.
+	bookmarkToken string
+}
+
+// watchCheckInterval is how often the watches are looked through for URLs due to be
+// scraped again.
+const watchCheckInterval = 30 * time.Second
+
+// NewHTTPSource creates a source that listens on addr (e.g. ":8080").
+func NewHTTPSource(addr string) *HTTPSource {
+	s := &HTTPSource{
+		queue:   newJobQueue(queueSize),
+		client:  &http.Client{Timeout: 30 * time.Second},
+		workers: 1,
+	}
+	mux := http.NewServeMux()
+	mux.Handle("POST /urls", s.require(access.Submit, http.HandlerFunc(s.handleIntake)))
+	// The bookmarklet cannot send a key; its endpoint takes only local requests carrying
+	// its token instead.
+	mux.HandleFunc("GET /bookmarklet", s.handleBookmarkletPage)
+	mux.HandleFunc("POST /bookmark", s.handleBookmark)
+	mux.Handle("GET /debug/vars", s.require(access.Admin, expvar.Handler()))
+	s.mux = mux
+	s.server = &http.Server{Addr: addr, Handler: mux}
+	return s
+}
+
+// Handle mounts an additional handler (such as the results feed) on the server, open
+// to every key with the read role. It must be called before Run.
+func (s *HTTPSource) Handle(pattern string, h http.Handler) {
+	s.mux.Handle(pattern, s.require(access.Read, h))
+}
+
+// SetKeys makes every endpoint but the bookmarklet's require an API key, sent as
+// "Authorization: Bearer key" or, for feed readers, a key query parameter, whose role
+// in keys is enough for it. It must be called before Run.
+func (s *HTTPSource) SetKeys(keys access.Keys) {
+	s.keys = keys
+}
+
+// require wraps h so that, with keys set, it only serves requests whose key has role.
+func (s *HTTPSource) require(role access.Role, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.keys != nil {
+			got := s.keys.Role(apiKey(r))
+			if got == access.None {
+				http.Error(w, ErrUnauthorized.Error(), http.StatusUnauthorized)
+				return
+			}
+			if got < role {
+				http.Error(w, fmt.Sprintf("a %s key may not do this; it takes %s", got, role), http.StatusForbidden)
+				return
+			}
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// apiKey returns the API key a request was sent with, if any.
+func apiKey(r *http.Request) string {
+	if key, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(key)
+	}
+	return r.URL.Query().Get("key")
+}
+
+// SetWatches serves watches at /watches, where keys with the submit role add and remove
+// them and keys with the read role list them, and queues the watched URLs at low
+// priority as their intervals come due. It must be called before Run.
+func (s *HTTPSource) SetWatches(watches *sink.WatchSink) {
+	s.watches = watches
+	s.mux.Handle("GET /watches", s.require(access.Read, watches))
+	s.mux.Handle("POST /watches", s.require(access.Submit, watches))
+	s.mux.Handle("DELETE /watches", s.require(access.Submit, watches))
+}
+
+// SetWorkers sets how many queued jobs are processed at a time, one by default. Each
+// worker takes the highest-priority job waiting. It must be called before Run.
+func (s *HTTPSource) SetWorkers(n int) {
+	s.workers = max(n, 1)
+}
+
+// SetTenants makes every intake request name its tenant with an API key, sent as for
+// SetKeys, which t admits or refuses. It must be called before Run.
+func (s *HTTPSource) SetTenants(t Tenants) {
+	s.tenants = t
+}
+
+// handleIntake accepts a batch of URLs, either as JSON ({"urls": [...], "callback": "..."})
+// or as newline-delimited plain text, and enqueues them.
+func (s *HTTPSource) handleIntake(w http.ResponseWriter, r *http.Request) {
+	var req intakeRequest
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid JSON body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	} else {
+		var buf bytes.Buffer
+		if _, err := buf.ReadFrom(r.Body); err != nil {
+			http.Error(w, "reading body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.URLs = strings.Split(buf.String(), "\n")
+		req.Callback = r.URL.Query().Get("callback")
+		req.Priority = r.URL.Query().Get("priority")
+	}
+	priority, err := ParsePriority(req.Priority)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Drop blank lines and surrounding white space.
+	var urls []string
+	for _, u := range req.URLs {
+		if u = strings.TrimSpace(u); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	if len(urls) == 0 {
+		http.Error(w, "no URLs provided", http.StatusBadRequest)
+		return
+	}
+	if req.Callback != "" {
+		if u, err := neturl.Parse(req.Callback); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			http.Error(w, "callback must be an http or https URL", http.StatusBadRequest)
+			return
+		}
+	}
+	var tenant string
+	if s.tenants != nil {
+		if tenant, err = s.tenants.Admit(apiKey(r), urls); err != nil {
+			status := http.StatusForbidden
+			switch {
+			case errors.Is(err, ErrUnauthorized):
+				status = http.StatusUnauthorized
+			case errors.Is(err, ErrOverBudget):
+				status = http.StatusTooManyRequests
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+	}
+	b := &batch{callback: req.Callback, pending: len(urls)}
+	jobs := make([]job, len(urls))
+	for i, u := range urls {
+		jobs[i] = job{req: Request{URL: u, Tenant: tenant}, batch: b}
+	}
+	// The queue refuses the whole batch rather than accepting part of it.
+	if !s.queue.push(priority, jobs...) {
+		http.Error(w, "queue is full, try again later", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]int{"queued": len(urls)})
+}
+
+// Run serves HTTP requests and processes queued URLs until ctx is cancelled.
+func (s *HTTPSource) Run(ctx context.Context, h Handler) error {
+	errs := make(chan error, 1)
+	go func() {
+		if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errs <- err
+		}
+	}()
+	log.Printf("Serving on %s", s.server.Addr)
+
+	// The workers stop when Run returns, whether or not ctx was cancelled, and Run waits
+	// for the jobs under way. Jobs still queued are left alone rather than failed with a
+	// cancelled context.
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+	for range s.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.work(ctx, h)
+		}()
+	}
+	var watchTicks <-chan time.Time
+	if s.watches != nil {
+		ticker := time.NewTicker(watchCheckInterval)
+		defer ticker.Stop()
+		watchTicks = ticker.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			// Give in-flight requests a moment to finish before exiting.
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			return s.server.Shutdown(shutdownCtx)
+		case err := <-errs:
+			return fmt.Errorf("http: serving: %w", err)
+		case now := <-watchTicks:
+			s.queueWatches(now)
+		}
+	}
+}
+
+// work processes queued jobs, highest priority first, until ctx is cancelled.
+func (s *HTTPSource) work(ctx context.Context, h Handler) {
+	for ctx.Err() == nil {
+		if j, p, ok := s.queue.pop(); ok {
+			s.process(ctx, h, j, p)
+			continue
+		}
+		select {
+		case <-ctx.Done():
+		case <-s.queue.ready:
+		}
+	}
+}
+
+// queueWatches queues the watched URLs due to be scraped again.
+func (s *HTTPSource) queueWatches(now time.Time) {
+	due, err := s.watches.Due(now)
+	if err != nil {
+		log.Printf("Error saving watches: %v", err)
+	}
+	if len(due) == 0 {
+		return
+	}
+	// The page is only fetched again if the site says it has changed.
+	jobs := make([]job, len(due))
+	for i, w := range due {
+		jobs[i] = job{req: Request{URL: w.URL, ETag: w.ETag, LastModified: w.LastModified}}
+	}
+	if !s.queue.push(PriorityLow, jobs...) {
+		log.Printf("Queue is full; %d watched URLs wait for their next interval", len(due))
+	}
+}
+
+// process scrapes one queued request and fires the batch callback if it was the last one.
+func (s *HTTPSource) process(ctx context.Context, h Handler, j job, p Priority) {
+	article, err := h(ctx, j.req)
+	result := intakeResult{Article: article}
+	queueMetrics.Add(p.String()+".done", 1)
+	if err != nil {
+		queueMetrics.Add(p.String()+".failed", 1)
+		log.Printf("Error handling %s: %v", j.req.URL, err)
+		result = intakeResult{Article: sink.Article{URL: j.req.URL}, Error: err.Error()}
+	}
+
+	b := j.batch
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	b.results = append(b.results, result)
+	b.pending--
+	done := b.pending == 0
+	b.mu.Unlock()
+
+	if done && b.callback != "" {
+		if err := s.sendCallback(ctx, b.callback, b.results); err != nil {
+			log.Printf("Error calling back %s: %v", b.callback, err)
+		}
+	}
+}
+
+// sendCallback POSTs the batch results as JSON to the callback URL.
+func (s *HTTPSource) sendCallback(ctx context.Context, url string, results []intakeResult) error {
+	payload, err := json.Marshal(map[string]any{"results": results})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("callback returned %s", resp.Status)
+	}
+	return nil
+}
+
+// Close stops the HTTP server immediately.
+func (s *HTTPSource) Close() error {
+	return s.server.Close()
+}
@@ -0,0 +1,85 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/hail2skins/zero-scraper/internal/sink"
+)
+
+// natsReply is the payload sent back to a NATS requester.
+type natsReply struct {
+	sink.Article
+	// Error is set when the URL could not be scraped.
+	Error string `json:"error,omitempty"`
+}
+
+// NATSSource receives scrape requests on a NATS subject, one URL per message.
+// Requests sent with a reply subject (request/reply) get the scraped article JSON back.
+// Subscribers join a queue group so several workers can share the load.
+type NATSSource struct {
+	conn    *nats.Conn
+	subject string
+	queue   string
+}
+
+// NewNATSSource connects to the NATS server at url and prepares to serve subject
+// as a member of the given queue group.
+func NewNATSSource(url, subject, queue string) (*NATSSource, error) {
+	if subject == "" {
+		return nil, fmt.Errorf("nats: a request subject is required")
+	}
+	conn, err := nats.Connect(url)
+	if err != nil {
+		return nil, fmt.Errorf("nats: connecting: %w", err)
+	}
+	return &NATSSource{conn: conn, subject: subject, queue: queue}, nil
+}
+
+// Run handles requests until ctx is cancelled.
+// Messages are processed one at a time in the order they arrive.
+func (s *NATSSource) Run(ctx context.Context, h Handler) error {
+	msgs := make(chan *nats.Msg, 64)
+	sub, err := s.conn.ChanQueueSubscribe(s.subject, s.queue, msgs)
+	if err != nil {
+		return fmt.Errorf("nats: subscribing to %q: %w", s.subject, err)
+	}
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg := <-msgs:
+			url := strings.TrimSpace(string(msg.Data))
+			article, err := h(ctx, Request{URL: url})
+
+			reply := natsReply{Article: article}
+			if err != nil {
+				log.Printf("Error handling %s: %v", url, err)
+				reply = natsReply{Article: sink.Article{URL: url}, Error: err.Error()}
+			}
+			// Fire-and-forget requests have no reply subject.
+			if msg.Reply == "" {
+				continue
+			}
+			payload, err := json.Marshal(reply)
+			if err != nil {
+				return fmt.Errorf("nats: encoding reply: %w", err)
+			}
+			if err := msg.Respond(payload); err != nil {
+				log.Printf("Error replying to request for %s: %v", url, err)
+			}
+		}
+	}
+}
+
+// Close drains the connection to the NATS server.
+func (s *NATSSource) Close() error {
+	return s.conn.Drain()
+}
@@ -0,0 +1,136 @@
+package source
+
+import (
+	"container/heap"
+	"expvar"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Priority orders queued scrape jobs. Higher priorities are always taken first, so
+// time-sensitive work such as breaking-news URLs is not starved by large backfills.
+type Priority int
+
+const (
+	// PriorityLow is for backfills and other work that can wait.
+	PriorityLow Priority = iota
+	// PriorityNormal is the default.
+	PriorityNormal
+	// PriorityHigh is for time-sensitive URLs and interactive requests.
+	PriorityHigh
+)
+
+// String returns the priority's name as accepted by ParsePriority.
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityHigh:
+		return "high"
+	default:
+		return "normal"
+	}
+}
+
+// ParsePriority converts "low", "normal", or "high" to a Priority. An empty string is normal.
+func ParsePriority(s string) (Priority, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "low":
+		return PriorityLow, nil
+	case "", "normal":
+		return PriorityNormal, nil
+	case "high":
+		return PriorityHigh, nil
+	}
+	return PriorityNormal, fmt.Errorf("unknown priority %q (want low, normal, or high)", s)
+}
+
+// queueMetrics exposes per-priority counters at /debug/vars in serve mode, e.g.
+// "high.queued", "high.done", "high.failed", and the current "high.depth".
+var queueMetrics = expvar.NewMap("scrape_queue")
+
+// queuedJob is a job with the ordering information the heap needs.
+type queuedJob struct {
+	job
+	priority Priority
+	seq      uint64 // seq keeps jobs of equal priority in arrival order.
+}
+
+// jobHeap implements heap.Interface, ordering by priority and then arrival.
+type jobHeap []queuedJob
+
+func (h jobHeap) Len() int { return len(h) }
+func (h jobHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+func (h jobHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *jobHeap) Push(x any)   { *h = append(*h, x.(queuedJob)) }
+func (h *jobHeap) Pop() any {
+	old := *h
+	it := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return it
+}
+
+// jobQueue is a bounded, concurrency-safe priority queue of scrape jobs.
+type jobQueue struct {
+	mu    sync.Mutex
+	items jobHeap
+	seq   uint64
+	limit int
+	// ready receives a value whenever jobs are added, waking a waiting consumer, and
+	// again whenever a consumer takes a job and others remain.
+	ready chan struct{}
+}
+
+// newJobQueue creates a queue that holds at most limit jobs.
+func newJobQueue(limit int) *jobQueue {
+	return &jobQueue{limit: limit, ready: make(chan struct{}, 1)}
+}
+
+// push adds all jobs at priority p, or none of them if they would not fit.
+func (q *jobQueue) push(p Priority, jobs ...job) bool {
+	q.mu.Lock()
+	if len(q.items)+len(jobs) > q.limit {
+		q.mu.Unlock()
+		return false
+	}
+	for _, j := range jobs {
+		q.seq++
+		heap.Push(&q.items, queuedJob{job: j, priority: p, seq: q.seq})
+	}
+	q.mu.Unlock()
+
+	queueMetrics.Add(p.String()+".queued", int64(len(jobs)))
+	queueMetrics.Add(p.String()+".depth", int64(len(jobs)))
+	q.wake()
+	return true
+}
+
+// wake signals ready unless a wake-up is already pending.
+func (q *jobQueue) wake() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// pop removes the highest-priority job, reporting false if the queue is empty.
+func (q *jobQueue) pop() (job, Priority, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.items) == 0 {
+		return job{}, 0, false
+	}
+	it := heap.Pop(&q.items).(queuedJob)
+	queueMetrics.Add(it.priority.String()+".depth", -1)
+	if len(q.items) > 0 {
+		// Pass the wake-up on, so that another waiting consumer takes the next job.
+		q.wake()
+	}
+	return it.job, it.priority, true
+}
@@ -0,0 +1,52 @@
+// Package source provides inputs that feed URLs to the scraper, such as message queues,
+// so zero-scraper can run as a worker inside a larger system.
+package source
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hail2skins/zero-scraper/internal/sink"
+)
+
+// Request is a single unit of work taken from a source.
+type Request struct {
+	// URL is the address of the article to scrape.
+	URL string
+	// HTML, if set, is a copy of the page already captured by the sender (e.g. a logged-in
+	// browser session). It is extracted directly instead of fetching URL.
+	HTML string
+	// Tenant is the serve-mode tenant that queued the request, or empty without tenants.
+	Tenant string
+	// ETag and LastModified, if set, are the validators of the copy of the page already
+	// seen; the page is then only fetched and extracted if it has changed since.
+	ETag         string
+	LastModified string
+}
+
+// Tenants admit the intake requests of serve mode's tenants.
+type Tenants interface {
+	// Admit returns the name of the tenant whose API key is key if it may queue urls,
+	// and otherwise an error: ErrUnauthorized, ErrOverBudget, or one saying why not.
+	Admit(key string, urls []string) (string, error)
+}
+
+// Errors that Tenants.Admit wraps.
+var (
+	// ErrUnauthorized is returned for a missing or unknown API key.
+	ErrUnauthorized = errors.New("missing or unknown API key")
+	// ErrOverBudget is returned when a tenant has used up its budget.
+	ErrOverBudget = errors.New("budget used up")
+)
+
+// Handler processes a single request taken from a source and returns the scraped article.
+// Returning an error tells the source the request was not handled successfully.
+type Handler func(ctx context.Context, req Request) (sink.Article, error)
+
+// Source delivers URLs to a Handler until its context is cancelled.
+type Source interface {
+	// Run blocks, passing each received URL to h, until ctx is cancelled or the source fails.
+	Run(ctx context.Context, h Handler) error
+	// Close releases the source's connections.
+	Close() error
+}
@@ -0,0 +1,133 @@
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// A dump is a portable copy of a store, for moving an archive to another machine or
+// backend: a directory holding pages.jsonl, one JSON record per page, and the bodies
+// the records name under bodies/, plain whatever retention or encryption did to them in
+// the store. A record without a body is a page whose body retention deleted.
+//
+//	{"url": "https://example.com/story", "fetched_at": "2026-10-15T09:30:00Z", "body": "bodies/4f1c....html"}
+
+// dumpRecord is a line of pages.jsonl.
+type dumpRecord struct {
+	Meta
+	// Body is the path of the page's body relative to the dump; empty if it has none.
+	Body string `json:"body,omitempty"`
+}
+
+// Export writes every page in the store to a dump in dir, which must not hold one
+// already, and returns how many pages it wrote.
+func (s *Store) Export(dir string) (int, error) {
+	if err := os.MkdirAll(filepath.Join(dir, "bodies"), 0o755); err != nil {
+		return 0, fmt.Errorf("store: %w", err)
+	}
+	f, err := os.OpenFile(filepath.Join(dir, "pages.jsonl"), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return 0, fmt.Errorf("store: %w", err)
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	var n int
+	err = s.Each(func(p Page) error {
+		rec := dumpRecord{Meta: p.Meta}
+		// A deleted body and an empty one read alike, and neither is worth keeping.
+		if p.HTML != "" {
+			rec.Body = "bodies/" + key(p.URL) + ".html"
+			if err := os.WriteFile(filepath.Join(dir, rec.Body), []byte(p.HTML), 0o644); err != nil {
+				return fmt.Errorf("store: %w", err)
+			}
+		}
+		if err := enc.Encode(rec); err != nil {
+			return fmt.Errorf("store: %w", err)
+		}
+		n++
+		return nil
+	})
+	if err != nil {
+		f.Close()
+		return n, err
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return n, fmt.Errorf("store: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return n, fmt.Errorf("store: %w", err)
+	}
+	return n, nil
+}
+
+// Import adds the pages of the dump in dir to the store, and returns how many it added
+// and how many it skipped because the store's copy was fetched no earlier.
+func (s *Store) Import(dir string) (imported, skipped int, err error) {
+	f, err := os.Open(filepath.Join(dir, "pages.jsonl"))
+	if err != nil {
+		return 0, 0, fmt.Errorf("store: %w", err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		var rec dumpRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return imported, skipped, fmt.Errorf("store: pages.jsonl line %d: %w", line, err)
+		}
+		if rec.URL == "" {
+			return imported, skipped, fmt.Errorf("store: pages.jsonl line %d: no url", line)
+		}
+		base := filepath.Join(s.dir, key(rec.URL))
+		if have, err := readMeta(base); err == nil && !have.FetchedAt.Before(rec.FetchedAt) {
+			skipped++
+			continue
+		} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return imported, skipped, err
+		}
+		if rec.Body == "" {
+			if _, err := removeBody(base); err != nil {
+				return imported, skipped, err
+			}
+			if err := writeMeta(base, rec.URL, rec.FetchedAt); err != nil {
+				return imported, skipped, err
+			}
+			imported++
+			continue
+		}
+		// A dump names its own files; anything outside it is not the dump's to give.
+		if !filepath.IsLocal(rec.Body) {
+			return imported, skipped, fmt.Errorf("store: pages.jsonl line %d: body %q is outside the dump", line, rec.Body)
+		}
+		html, err := os.ReadFile(filepath.Join(dir, rec.Body))
+		if err != nil {
+			return imported, skipped, fmt.Errorf("store: %w", err)
+		}
+		if err := s.Put(rec.URL, string(html), rec.FetchedAt); err != nil {
+			return imported, skipped, err
+		}
+		imported++
+	}
+	if err := scanner.Err(); err != nil {
+		return imported, skipped, fmt.Errorf("store: %w", err)
+	}
+	return imported, skipped, nil
+}
+
+// writeMeta writes the metadata of the page at base.
+func writeMeta(base, url string, fetched time.Time) error {
+	meta, err := json.Marshal(Meta{URL: url, FetchedAt: fetched.UTC()})
+	if err != nil {
+		return fmt.Errorf("store: %w", err)
+	}
+	if err := os.WriteFile(base+".json", append(meta, '\n'), 0o644); err != nil {
+		return fmt.Errorf("store: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,436 @@
+// Package store keeps the raw HTML of every fetched page on disk, so extraction can be
+// re-run over a whole crawl after the extraction rules improve, without fetching again.
+//
+// Each page is stored as two files named after a hash of its URL: <hash>.html.zst holds
+// the body as fetched, zstd-compressed, and <hash>.json its metadata. A Retention may
+// later delete the body, while the metadata is kept for good. A store given a key
+// encrypts the bodies it writes, adding .enc to their names.
+//
+// Bodies written by earlier versions, uncompressed as <hash>.html or gzipped as
+// <hash>.html.gz, and those written before the store had a key, are read as they are
+// until Compact rewrites them.
+package store
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Meta describes a stored page.
+type Meta struct {
+	URL       string    `json:"url"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// Page is a stored page together with its metadata.
+type Page struct {
+	Meta
+	// HTML is the body, or empty once retention has deleted it.
+	HTML string
+}
+
+// Store is a directory of raw pages.
+type Store struct {
+	dir  string
+	aead cipher.AEAD // aead, if set, encrypts the bodies written.
+}
+
+// Open opens the store in dir, creating the directory if needed.
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// SetKey makes the store encrypt the bodies it writes from now on with AES-256-GCM
+// under key, which must be 32 bytes, and decrypt those it reads. Metadata is not
+// encrypted.
+func (s *Store) SetKey(key []byte) error {
+	block, err := aes.NewCipher(key)
+	if err == nil && len(key) != 32 {
+		err = fmt.Errorf("key is %d bytes, not 32", len(key))
+	}
+	if err != nil {
+		return fmt.Errorf("store: %w", err)
+	}
+	// NewGCM only fails for block sizes other than AES's.
+	s.aead, _ = cipher.NewGCM(block)
+	return nil
+}
+
+// Put saves the HTML fetched from url, replacing any earlier copy of the same URL.
+func (s *Store) Put(url, html string, fetched time.Time) error {
+	base := filepath.Join(s.dir, key(url))
+	// Write the body first: a page only counts as stored once its metadata exists.
+	if err := s.writeBody(base, []byte(html)); err != nil {
+		return err
+	}
+	return writeMeta(base, url, fetched)
+}
+
+// Get returns the stored copy of url, or an error wrapping os.ErrNotExist if there is none.
+func (s *Store) Get(url string) (Page, error) {
+	return s.load(filepath.Join(s.dir, key(url)))
+}
+
+// Each calls fn for every stored page, oldest fetch first, stopping at the first error.
+func (s *Store) Each(fn func(Page) error) error {
+	bases, err := s.bases()
+	if err != nil {
+		return err
+	}
+	// Only the metadata is read up front to order the pages; each body is loaded as its
+	// turn comes, so a large crawl is never held in memory at once.
+	fetched := make(map[string]time.Time, len(bases))
+	for _, base := range bases {
+		meta, err := readMeta(base)
+		if err != nil {
+			return err
+		}
+		fetched[base] = meta.FetchedAt
+	}
+	sort.SliceStable(bases, func(i, j int) bool { return fetched[bases[i]].Before(fetched[bases[j]]) })
+	for _, base := range bases {
+		p, err := s.load(base)
+		if err != nil {
+			return err
+		}
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Retention says how long stored pages keep their bodies. Their metadata is never
+// removed by it.
+type Retention struct {
+	// CompressAfter is the age at which a page's body, if still uncompressed, is
+	// compressed; zero for never. Only bodies written by earlier versions are.
+	CompressAfter time.Duration
+	// DeleteAfter is the age at which a page's body is deleted; zero for never.
+	DeleteAfter time.Duration
+}
+
+// Enforce applies r to every page, aging pages by their fetch time up to now, and
+// returns how many bodies it compressed and deleted.
+func (s *Store) Enforce(r Retention, now time.Time) (compressed, deleted int, err error) {
+	if r.CompressAfter <= 0 && r.DeleteAfter <= 0 {
+		return 0, 0, nil
+	}
+	bases, err := s.bases()
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, base := range bases {
+		meta, err := readMeta(base)
+		if err != nil {
+			return compressed, deleted, err
+		}
+		age := now.Sub(meta.FetchedAt)
+		switch {
+		case r.DeleteAfter > 0 && age >= r.DeleteAfter:
+			removed, err := removeBody(base)
+			if err != nil {
+				return compressed, deleted, err
+			}
+			if removed {
+				deleted++
+			}
+		case r.CompressAfter > 0 && age >= r.CompressAfter:
+			done, err := s.compressBody(base)
+			if err != nil {
+				return compressed, deleted, err
+			}
+			if done {
+				compressed++
+			}
+		}
+	}
+	return compressed, deleted, nil
+}
+
+// Purge deletes every page for which match is true, metadata and all, and returns how
+// many it deleted.
+func (s *Store) Purge(match func(Meta) bool) (int, error) {
+	bases, err := s.bases()
+	if err != nil {
+		return 0, err
+	}
+	var purged int
+	for _, base := range bases {
+		meta, err := readMeta(base)
+		if err != nil {
+			return purged, err
+		}
+		if !match(meta) {
+			continue
+		}
+		// The metadata goes last, so a page half purged is still found and purged again.
+		if _, err := removeBody(base); err != nil {
+			return purged, err
+		}
+		if err := os.Remove(base + ".json"); err != nil {
+			return purged, fmt.Errorf("store: %w", err)
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// Compact rewrites every body not stored as the store now writes them, zstd-compressed
+// and encrypted if it has a key, and returns how many it rewrote and by how many bytes
+// they shrank.
+func (s *Store) Compact() (rewritten int, saved int64, err error) {
+	bases, err := s.bases()
+	if err != nil {
+		return 0, 0, err
+	}
+	want := s.writeSuffix()
+	for _, base := range bases {
+		html, suffix, err := s.readBody(base)
+		if errors.Is(err, os.ErrNotExist) || suffix == want {
+			continue
+		}
+		if err != nil {
+			return rewritten, saved, err
+		}
+		before, err := os.Stat(base + suffix)
+		if err != nil {
+			return rewritten, saved, fmt.Errorf("store: %w", err)
+		}
+		if err := s.writeBody(base, html); err != nil {
+			return rewritten, saved, err
+		}
+		after, err := os.Stat(base + want)
+		if err != nil {
+			return rewritten, saved, fmt.Errorf("store: %w", err)
+		}
+		rewritten++
+		saved += before.Size() - after.Size()
+	}
+	return rewritten, saved, nil
+}
+
+// ParseAge parses a retention age: a number of days such as 30d, or a Go duration.
+func ParseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("store: invalid age %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("store: invalid age %q", s)
+	}
+	return d, nil
+}
+
+// bases returns the path prefix of every stored page.
+func (s *Store) bases() ([]string, error) {
+	metas, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	for i, m := range metas {
+		metas[i] = strings.TrimSuffix(m, ".json")
+	}
+	return metas, nil
+}
+
+// load reads the page whose files share the path prefix base.
+func (s *Store) load(base string) (Page, error) {
+	meta, err := readMeta(base)
+	if err != nil {
+		return Page{}, err
+	}
+	p := Page{Meta: meta}
+	html, _, err := s.readBody(base)
+	if errors.Is(err, os.ErrNotExist) {
+		// Retention deleted the body.
+		return p, nil
+	}
+	if err != nil {
+		return p, err
+	}
+	p.HTML = string(html)
+	return p, nil
+}
+
+// bodySuffixes are the names a body may be stored under, after its page's path prefix:
+// zstd-compressed, as written now, or uncompressed or gzipped, as written by earlier
+// versions, each optionally encrypted.
+var bodySuffixes = []string{".html.zst", ".html.zst.enc", ".html", ".html.enc", ".html.gz", ".html.gz.enc"}
+
+// The zstd encoder and decoder are shared: EncodeAll and DecodeAll are safe for
+// concurrent use, and neither fails without options that can.
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedBetterCompression))
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+// readBody returns the body of the page at base, uncompressed and decrypted, and the
+// suffix it was stored under. It returns an error wrapping os.ErrNotExist if the page
+// has no body.
+func (s *Store) readBody(base string) ([]byte, string, error) {
+	for _, suffix := range bodySuffixes {
+		data, err := os.ReadFile(base + suffix)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("store: %w", err)
+		}
+		if strings.HasSuffix(suffix, ".enc") {
+			if data, err = s.decrypt(base, data); err != nil {
+				return nil, "", err
+			}
+		}
+		switch strings.TrimSuffix(suffix, ".enc") {
+		case ".html.zst":
+			data, err = zstdDecoder.DecodeAll(data, nil)
+		case ".html.gz":
+			data, err = gunzip(data)
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("store: %s: %w", base+suffix, err)
+		}
+		return data, suffix, nil
+	}
+	return nil, "", fmt.Errorf("store: %s has no body: %w", base, os.ErrNotExist)
+}
+
+// writeSuffix is the suffix writeBody stores bodies under.
+func (s *Store) writeSuffix() string {
+	if s.aead != nil {
+		return ".html.zst.enc"
+	}
+	return ".html.zst"
+}
+
+// writeBody replaces the body of the page at base with html, compressed, and encrypted
+// if the store has a key.
+func (s *Store) writeBody(base string, html []byte) error {
+	suffix := s.writeSuffix()
+	data := zstdEncoder.EncodeAll(html, make([]byte, 0, len(html)/4))
+	if s.aead != nil {
+		data = s.encrypt(base, data)
+	}
+	// Write the new copy before removing the others, so the body is never lost.
+	if err := os.WriteFile(base+suffix, data, 0o644); err != nil {
+		return fmt.Errorf("store: %w", err)
+	}
+	for _, other := range bodySuffixes {
+		if other == suffix {
+			continue
+		}
+		if err := os.Remove(base + other); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("store: %w", err)
+		}
+	}
+	return nil
+}
+
+// encrypt seals data for the page at base: a random nonce followed by the ciphertext.
+// The page's file name is authenticated with it, so a body cannot be passed off as
+// another page's.
+func (s *Store) encrypt(base string, data []byte) []byte {
+	nonce := make([]byte, s.aead.NonceSize(), s.aead.NonceSize()+len(data)+s.aead.Overhead())
+	rand.Read(nonce)
+	return s.aead.Seal(nonce, nonce, data, []byte(filepath.Base(base)))
+}
+
+// decrypt opens data sealed by encrypt for the page at base.
+func (s *Store) decrypt(base string, data []byte) ([]byte, error) {
+	if s.aead == nil {
+		return nil, fmt.Errorf("store: %s is encrypted, and no key was given", base)
+	}
+	n := s.aead.NonceSize()
+	if len(data) < n {
+		return nil, fmt.Errorf("store: %s: encrypted body is truncated", base)
+	}
+	plain, err := s.aead.Open(nil, data[:n], data[n:], []byte(filepath.Base(base)))
+	if err != nil {
+		return nil, fmt.Errorf("store: %s: wrong key or damaged body", base)
+	}
+	return plain, nil
+}
+
+// readMeta reads the metadata of the page whose files share the path prefix base.
+func readMeta(base string) (Meta, error) {
+	var m Meta
+	data, err := os.ReadFile(base + ".json")
+	if err != nil {
+		return m, fmt.Errorf("store: %w", err)
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return m, fmt.Errorf("store: %s: %w", base+".json", err)
+	}
+	return m, nil
+}
+
+// gunzip returns the uncompressed contents of data.
+func gunzip(data []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(zr)
+}
+
+// compressBody compresses the uncompressed body of the page at base, reporting whether
+// there was one to compress.
+func (s *Store) compressBody(base string) (bool, error) {
+	html, suffix, err := s.readBody(base)
+	if errors.Is(err, os.ErrNotExist) || strings.TrimSuffix(suffix, ".enc") != ".html" {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := s.writeBody(base, html); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// removeBody deletes the body of the page at base, however it is stored, reporting
+// whether there was one to delete.
+func removeBody(base string) (bool, error) {
+	var removed bool
+	for _, suffix := range bodySuffixes {
+		err := os.Remove(base + suffix)
+		if err == nil {
+			removed = true
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return removed, fmt.Errorf("store: %w", err)
+		}
+	}
+	return removed, nil
+}
+
+// key names a URL's files: the first 32 hex digits of its SHA-256.
+func key(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(sum[:16])
+}
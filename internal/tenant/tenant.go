@@ -0,0 +1,173 @@
+// Package tenant lets one serve-mode process be shared by several tenants, such as the
+// members of a small team, without any of them seeing another's work. Each tenant has
+// its own API key for queueing URLs, the sources it may queue them from, the prefix its
+// stored pages are kept under, and a daily budget of URLs.
+//
+// The tenants file is a JSON object keyed by tenant name:
+//
+//	{
+//	  "newsroom": {"api_key": "3f9c...", "sources": ["apnews.com", "reuters.com"], "storage_prefix": "newsroom", "daily_urls": 500},
+//	  "research": {"api_key": "b71e..."}
+//	}
+//
+// A tenant with no sources may queue any URL, one with no storage prefix is stored
+// under its name, and one with no daily_urls has no budget. A tenant's key may also
+// be given a role other than submit, as described in package access.
+package tenant
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hail2skins/zero-scraper/internal/access"
+	"github.com/hail2skins/zero-scraper/internal/source"
+)
+
+// Tenant is the configuration of one tenant.
+type Tenant struct {
+	// Name identifies the tenant, e.g. "newsroom".
+	Name string `json:"-"`
+	// APIKey is sent by the tenant as "Authorization: Bearer key" with every request.
+	APIKey string `json:"api_key"`
+	// Sources are the sites the tenant may queue URLs from, subdomains included; any
+	// site if empty.
+	Sources []string `json:"sources,omitempty"`
+	// StoragePrefix is the directory, under the shared store, the tenant's pages are
+	// kept in. It defaults to Name.
+	StoragePrefix string `json:"storage_prefix,omitempty"`
+	// DailyURLs is the most URLs the tenant may queue in a UTC day; zero for no limit.
+	DailyURLs int `json:"daily_urls,omitempty"`
+	// Role is what the tenant's key may do. It defaults to access.Submit, and only a key
+	// with that role or above can queue URLs.
+	Role access.Role `json:"role,omitempty"`
+}
+
+// Registry admits the requests of the tenants in a tenants file. It implements
+// source.Tenants and access.Keys, and is safe for concurrent use.
+type Registry struct {
+	tenants []Tenant           // tenants are sorted by name.
+	byKey   map[string]*Tenant // byKey maps API keys to their tenants.
+
+	mu   sync.Mutex
+	day  string         // day is the UTC date the counts in used are for.
+	used map[string]int // used maps tenant names to the URLs they queued on day.
+}
+
+// Load reads a tenants file.
+func Load(path string) (*Registry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("tenant: %w", err)
+	}
+	var file map[string]Tenant
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("tenant: reading %s: %w", path, err)
+	}
+	r := &Registry{byKey: make(map[string]*Tenant, len(file)), used: make(map[string]int)}
+	prefixes := make(map[string]string, len(file)) // prefixes maps storage prefixes to their tenants.
+	for name, t := range file {
+		t.Name = name
+		if t.APIKey == "" {
+			return nil, fmt.Errorf("tenant: %s: %s has no api_key", path, name)
+		}
+		if t.Role == access.None {
+			t.Role = access.Submit
+		}
+		if t.StoragePrefix == "" {
+			t.StoragePrefix = name
+		}
+		// The prefix is a single directory, so no tenant can reach into another's.
+		if t.StoragePrefix != filepath.Base(t.StoragePrefix) || t.StoragePrefix == "." || t.StoragePrefix == ".." {
+			return nil, fmt.Errorf("tenant: %s: storage_prefix %q of %s is not a plain directory name", path, t.StoragePrefix, name)
+		}
+		if other, dup := prefixes[t.StoragePrefix]; dup {
+			return nil, fmt.Errorf("tenant: %s: %s and %s share storage_prefix %q", path, other, name, t.StoragePrefix)
+		}
+		prefixes[t.StoragePrefix] = name
+		if t.DailyURLs < 0 {
+			return nil, fmt.Errorf("tenant: %s: negative daily_urls for %s", path, name)
+		}
+		for i, s := range t.Sources {
+			t.Sources[i] = normalizeDomain(s)
+		}
+		r.tenants = append(r.tenants, t)
+	}
+	sort.Slice(r.tenants, func(i, j int) bool { return r.tenants[i].Name < r.tenants[j].Name })
+	for i := range r.tenants {
+		t := &r.tenants[i]
+		if other, dup := r.byKey[t.APIKey]; dup {
+			return nil, fmt.Errorf("tenant: %s: %s and %s share an api_key", path, other.Name, t.Name)
+		}
+		r.byKey[t.APIKey] = t
+	}
+	return r, nil
+}
+
+// Tenants returns the tenants, sorted by name.
+func (r *Registry) Tenants() []Tenant {
+	return r.tenants
+}
+
+// Role implements access.Keys.
+func (r *Registry) Role(key string) access.Role {
+	if t, ok := r.byKey[key]; ok && key != "" {
+		return t.Role
+	}
+	return access.None
+}
+
+// Admit implements source.Tenants. A batch is admitted whole or not at all, and counts
+// against the tenant's budget only when admitted.
+func (r *Registry) Admit(key string, urls []string) (string, error) {
+	t, ok := r.byKey[key]
+	if !ok || key == "" {
+		return "", source.ErrUnauthorized
+	}
+	for _, u := range urls {
+		if !t.allows(u) {
+			return "", fmt.Errorf("%s is not among the sources of tenant %s", u, t.Name)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if today := time.Now().UTC().Format(time.DateOnly); today != r.day {
+		r.day = today
+		clear(r.used)
+	}
+	if t.DailyURLs > 0 && r.used[t.Name]+len(urls) > t.DailyURLs {
+		return "", fmt.Errorf("%w: tenant %s may queue %d more URLs today", source.ErrOverBudget, t.Name, t.DailyURLs-r.used[t.Name])
+	}
+	r.used[t.Name] += len(urls)
+	return t.Name, nil
+}
+
+// allows reports whether rawURL is on one of t's sources.
+func (t *Tenant) allows(rawURL string) bool {
+	if len(t.Sources) == 0 {
+		return true
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := normalizeDomain(u.Hostname())
+	for _, s := range t.Sources {
+		if host == s || strings.HasSuffix(host, "."+s) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeDomain lowercases a domain and drops a leading "www.".
+func normalizeDomain(domain string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "www.")
+}
@@ -0,0 +1,98 @@
+// Package textdir detects the writing direction of scraped text and wraps it so that
+// right-to-left scripts (Arabic, Hebrew, Farsi) and mixed-direction bylines display
+// correctly in terminals, plain-text files, and HTML.
+package textdir
+
+import (
+	"strings"
+
+	"golang.org/x/text/unicode/bidi"
+)
+
+// Direction is the base writing direction of a piece of text.
+type Direction int
+
+const (
+	// Neutral text contains no strongly directional characters (e.g. only digits or punctuation).
+	Neutral Direction = iota
+	// LTR is left-to-right text such as Latin or Cyrillic.
+	LTR
+	// RTL is right-to-left text such as Arabic or Hebrew.
+	RTL
+)
+
+// Unicode directional formatting characters (see Unicode Standard Annex #9).
+const (
+	lri = "\u2066" // Left-to-right isolate.
+	rli = "\u2067" // Right-to-left isolate.
+	fsi = "\u2068" // First-strong isolate: direction is taken from the isolated text itself.
+	pdi = "\u2069" // Pop directional isolate: ends any of the above.
+)
+
+// Of returns the direction of the first strongly directional character in s,
+// which is how the Unicode bidi algorithm picks a paragraph's base direction.
+func Of(s string) Direction {
+	for _, r := range s {
+		p, _ := bidi.LookupRune(r)
+		switch p.Class() {
+		case bidi.L:
+			return LTR
+		case bidi.R, bidi.AL:
+			return RTL
+		}
+	}
+	return Neutral
+}
+
+// Attr returns the value for an HTML dir attribute describing s: "rtl", "ltr", or "auto".
+func Attr(s string) string {
+	switch Of(s) {
+	case RTL:
+		return "rtl"
+	case LTR:
+		return "ltr"
+	default:
+		return "auto"
+	}
+}
+
+// Isolate wraps s in a first-strong isolate, so text embedded in a line of the other
+// direction (an Arabic name after "Byline:") keeps its own order and does not pull
+// surrounding punctuation to the wrong side.
+func Isolate(s string) string {
+	if s == "" || !hasRTL(s) {
+		return s
+	}
+	return fsi + s + pdi
+}
+
+// Paragraphs wraps each newline-separated paragraph of s in an isolate matching its own
+// direction, so a right-to-left paragraph keeps its punctuation at the correct end even
+// when the terminal or viewer assumes left-to-right text. Text without any right-to-left
+// characters is returned unchanged.
+func Paragraphs(s string) string {
+	if !hasRTL(s) {
+		return s
+	}
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		switch Of(line) {
+		case RTL:
+			lines[i] = rli + line + pdi
+		case LTR:
+			lines[i] = lri + line + pdi
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// hasRTL reports whether s contains any right-to-left character.
+func hasRTL(s string) bool {
+	for _, r := range s {
+		p, _ := bidi.LookupRune(r)
+		if c := p.Class(); c == bidi.R || c == bidi.AL {
+			return true
+		}
+	}
+	return false
+}
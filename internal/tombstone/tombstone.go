@@ -0,0 +1,173 @@
+// Package tombstone records the URLs and sites that have been forgotten on request, so
+// that nothing the forget command removed is ever scraped again. A tombstone names a
+// single URL, or a site together with its subdomains.
+//
+// The tombstones file is a JSON object mapping each URL or site to when it was buried:
+//
+//	{"https://example.com/2024/story": "2026-10-15T09:30:00Z", "example.org": "2026-10-15T09:31:00Z"}
+package tombstone
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// List is the tombstones kept in a file. It is safe for concurrent use, and it picks up
+// tombstones other processes add to the file, such as a forget run while a scraper serves.
+type List struct {
+	mu     sync.Mutex
+	path   string
+	stones map[string]time.Time // stones maps URLs and lowercase sites to when they were buried.
+	// file is the tombstones file as last read or written, nil if it did not exist.
+	file os.FileInfo
+}
+
+// Open loads the tombstones stored at path, starting empty if the file does not exist yet.
+func Open(path string) (*List, error) {
+	l := &List{path: path, stones: make(map[string]time.Time)}
+	if err := l.reload(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// reload reads the file again if it has been replaced or changed since it was last read,
+// adding the tombstones found there. The caller must hold l.mu, except in Open.
+func (l *List) reload() error {
+	info, err := os.Stat(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("tombstone: %w", err)
+	}
+	if l.file != nil && os.SameFile(info, l.file) && info.ModTime().Equal(l.file.ModTime()) && info.Size() == l.file.Size() {
+		return nil
+	}
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		return fmt.Errorf("tombstone: %w", err)
+	}
+	var stones map[string]time.Time
+	if err := json.Unmarshal(data, &stones); err != nil {
+		return fmt.Errorf("tombstone: %s: %w", l.path, err)
+	}
+	for key, at := range stones {
+		if _, ok := l.stones[key]; !ok {
+			l.stones[key] = at
+		}
+	}
+	l.file = info
+	return nil
+}
+
+// Target returns the tombstone key for target, a URL or a site, and reports whether it
+// names a site.
+func Target(target string) (key string, site bool) {
+	target = strings.TrimSpace(target)
+	if strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
+		return target, false
+	}
+	return normalizeDomain(target), true
+}
+
+// Matches reports whether rawURL is target, a URL or a site, or on one of the site's
+// subdomains.
+func Matches(target, rawURL string) bool {
+	key, site := Target(target)
+	if !site {
+		return rawURL == key
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := normalizeDomain(u.Hostname())
+	return host == key || strings.HasSuffix(host, "."+key)
+}
+
+// Bury records a tombstone for target, a URL or a site, and saves the list.
+func (l *List) Bury(target string, now time.Time) error {
+	key, _ := Target(target)
+	if key == "" {
+		return errors.New("tombstone: empty target")
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// Another process may have buried something since, which saving must not drop.
+	if err := l.reload(); err != nil {
+		return err
+	}
+	if _, ok := l.stones[key]; !ok {
+		l.stones[key] = now.UTC()
+	}
+	return l.save()
+}
+
+// Buried reports whether rawURL, or the site it is on, has a tombstone, and returns
+// the tombstone's key. The file is read again first if it has changed; if it cannot be
+// read, the tombstones read before still hold.
+func (l *List) Buried(rawURL string) (string, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.reload()
+	if _, ok := l.stones[rawURL]; ok {
+		return rawURL, true
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", false
+	}
+	for host := normalizeDomain(u.Hostname()); host != ""; {
+		if _, ok := l.stones[host]; ok {
+			return host, true
+		}
+		_, parent, ok := strings.Cut(host, ".")
+		if !ok {
+			break
+		}
+		host = parent
+	}
+	return "", false
+}
+
+// save writes the list to a temporary file and renames it into place. The caller must
+// hold l.mu.
+func (l *List) save() error {
+	data, err := json.MarshalIndent(l.stones, "", "  ")
+	if err != nil {
+		return fmt.Errorf("tombstone: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(l.path), ".tombstones-*.json")
+	if err != nil {
+		return fmt.Errorf("tombstone: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("tombstone: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("tombstone: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), l.path); err != nil {
+		return fmt.Errorf("tombstone: %w", err)
+	}
+	// What was just written need not be read back.
+	if info, err := os.Stat(l.path); err == nil {
+		l.file = info
+	}
+	return nil
+}
+
+// normalizeDomain lowercases a domain and drops a leading "www.".
+func normalizeDomain(domain string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "www.")
+}
@@ -0,0 +1,48 @@
+package tombstone
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestBuriedSeesOtherLists checks that a List picks up what another List, as a forget
+// run in another process would, buries in the same file after it was opened.
+func TestBuriedSeesOtherLists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tombstones.json")
+	scraper, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, buried := scraper.Buried("https://example.com/story"); buried {
+		t.Fatal("a URL is buried before any forget")
+	}
+
+	forget, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := forget.Bury("https://example.com/story", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if key, buried := scraper.Buried("https://example.com/story"); !buried || key != "https://example.com/story" {
+		t.Errorf("Buried = %q, %v after another List buried the URL; want it buried", key, buried)
+	}
+
+	// A second forget, of a site, is seen too, and the first List's save keeps it.
+	if err := forget.Bury("www.example.org", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err := scraper.Bury("https://example.net/other", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, u := range []string{"https://example.com/story", "https://news.example.org/a", "https://example.net/other"} {
+		if _, buried := reopened.Buried(u); !buried {
+			t.Errorf("%s is not buried after both Lists saved", u)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package scraper
+
+import "time"
+
+// Article is the result of scraping one page. New fields can be added here without
+// breaking callers, and the struct marshals directly to JSON.
+type Article struct {
+	// URL is the address the article was scraped from.
+	URL string `json:"url"`
+	// FinalURL is the address the page was served from after redirects. A rendered page,
+	// or HTML handed to ScrapeHTML, reports the requested URL.
+	FinalURL string `json:"final_url,omitempty"`
+	// Canonical is the page's <link rel="canonical"> address, resolved against FinalURL;
+	// empty if the page declares none.
+	Canonical string `json:"canonical_url,omitempty"`
+	// Title is the article's headline.
+	Title string `json:"title,omitempty"`
+	// Content is the extracted article text, one paragraph per line.
+	Content string `json:"content"`
+	// Byline is the extracted author information as it appeared on the page.
+	Byline string `json:"byline"`
+	// Authors are the individual names parsed from the byline, using the byline rules
+	// for the site's language.
+	Authors []string `json:"authors,omitempty"`
+	// Published is when the article was published, in UTC, if the page says.
+	Published time.Time `json:"published,omitzero"`
+	// Publisher names the outlet, when an extractor found it.
+	Publisher string `json:"publisher,omitempty"`
+	// Section is the desk the page files the article under, e.g. "Politics".
+	Section string `json:"section,omitempty"`
+	// Tags are the topics the page tags the article with.
+	Tags []string `json:"tags,omitempty"`
+	// Keywords are the page's news_keywords, keywords, and JSON-LD keywords.
+	Keywords []string `json:"keywords,omitempty"`
+	// Images are the lead image followed by the pictures in the article body.
+	Images []Image `json:"images,omitempty"`
+	// FetchedAt is when the page was fetched, or when its HTML was handed to ScrapeHTML.
+	FetchedAt time.Time `json:"fetched_at"`
+	// ETag and LastModified are the validators the page was served with, if any, for
+	// ScrapeIfChanged to ask whether it has changed since.
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	// Confidence says, per field ("title", "content", "byline", "published", "publisher"), how the value was derived and
+	// how far it can be trusted, so consumers can filter out doubtful records.
+	Confidence map[string]Confidence `json:"confidence,omitempty"`
+	// FieldErrors says why each field named as in Confidence came back empty. The fields
+	// that were found are kept, so callers can decide whether a partial article will do.
+	FieldErrors map[string]string `json:"field_errors,omitempty"`
+	// Robots are the robots directives the page declared about itself.
+	Robots Robots `json:"robots,omitempty"`
+	// HTML is the raw page the article was extracted from. It is not marshaled, since it
+	// is usually far larger than everything else.
+	HTML string `json:"-"`
+}
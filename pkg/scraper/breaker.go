@@ -0,0 +1,134 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CircuitBreaker stops requests to a host that keeps failing, such as one that is down,
+// so that its URLs fail at once rather than each waiting out its timeouts and retries.
+// A request fails when it gets no response or a 5xx status.
+type CircuitBreaker struct {
+	// Failures is how many failed requests in a row open a host's circuit.
+	Failures int
+	// Cooldown is how long an open circuit refuses requests. Then a single request is let
+	// through to probe the host: its success closes the circuit, and its failure opens it
+	// for another cooldown.
+	Cooldown time.Duration
+}
+
+// CircuitOpenError is returned for a URL whose host's circuit is open. No request was
+// sent for it.
+type CircuitOpenError struct {
+	Host string
+	// Until is when the host will next be probed.
+	Until time.Time
+}
+
+// Error implements error.
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("scraper: %s keeps failing; not trying it again until %s", e.Host, e.Until.Format(time.TimeOnly))
+}
+
+// DefaultCircuitBreaker is used for the zero fields of the CircuitBreaker given to
+// WithCircuitBreaker.
+var DefaultCircuitBreaker = CircuitBreaker{Failures: 5, Cooldown: 5 * time.Minute}
+
+// breaker keeps the circuits of every host a Scraper has requested.
+type breaker struct {
+	opts    CircuitBreaker
+	logger  *slog.Logger
+	metrics Metrics
+
+	mu    sync.Mutex
+	hosts map[string]*circuit
+}
+
+// circuit is the state of one host's circuit.
+type circuit struct {
+	failures int       // failures counts the failed requests in a row.
+	opened   time.Time // opened is when the circuit was opened; zero while it is closed.
+	probing  bool      // probing is set while the request probing the host is under way.
+}
+
+// allow reports whether a request to host may be sent, and if not, when it may.
+func (b *breaker) allow(host string, now time.Time) (bool, time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.hosts[host]
+	if c == nil || c.opened.IsZero() {
+		return true, time.Time{}
+	}
+	until := c.opened.Add(b.opts.Cooldown)
+	if now.Before(until) || c.probing {
+		return false, until
+	}
+	c.probing = true
+	return true, time.Time{}
+}
+
+// report records whether a request to host succeeded, opening or closing its circuit.
+func (b *breaker) report(host string, ok bool, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.hosts[host]
+	if c == nil {
+		if ok {
+			return
+		}
+		c = &circuit{}
+		if b.hosts == nil {
+			b.hosts = make(map[string]*circuit)
+		}
+		b.hosts[host] = c
+	}
+	if ok {
+		if !c.opened.IsZero() {
+			b.logger.Info("Host recovered; closing its circuit", "host", host)
+		}
+		*c = circuit{}
+		return
+	}
+	c.failures++
+	switch {
+	case c.probing:
+		c.opened, c.probing = now, false
+	case c.opened.IsZero() && c.failures >= b.opts.Failures:
+		c.opened = now
+		b.logger.Warn("Host keeps failing; opening its circuit", "host", host, "failures", c.failures, "cooldown", b.opts.Cooldown)
+	}
+}
+
+// breakerTransport is an http.RoundTripper that refuses requests to hosts whose circuit
+// is open, and reports how the others went.
+type breakerTransport struct {
+	base    http.RoundTripper
+	breaker *breaker
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t breakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := strings.ToLower(req.URL.Hostname())
+	if ok, until := t.breaker.allow(host, time.Now()); !ok {
+		t.breaker.metrics.Count("circuit_open", 1)
+		return nil, &CircuitOpenError{Host: host, Until: until}
+	}
+	resp, err := t.base.RoundTrip(req)
+	// A request the caller gave up on says nothing of the host.
+	if err != nil && errors.Is(err, context.Canceled) {
+		t.breaker.mu.Lock()
+		if c := t.breaker.hosts[host]; c != nil {
+			c.probing = false
+		}
+		t.breaker.mu.Unlock()
+		return resp, err
+	}
+	t.breaker.report(host, err == nil && resp.StatusCode < 500, time.Now())
+	return resp, err
+}
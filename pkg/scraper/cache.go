@@ -0,0 +1,55 @@
+package scraper
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// The cache kept by WithCacheDir is colly's: each page is a file named by the hex SHA-1 of
+// its URL, in a directory named by the hash's first two characters. The URL itself is not
+// kept, so only exact URLs can be found in it.
+
+// cacheFile returns the file the page of url is cached in under dir.
+func cacheFile(dir, url string) string {
+	sum := sha1.Sum([]byte(url))
+	hash := hex.EncodeToString(sum[:])
+	return filepath.Join(dir, hash[:2], hash)
+}
+
+// RemoveCached deletes the page of url from dir, a directory given to WithCacheDir, and
+// reports whether it was cached.
+func RemoveCached(dir, url string) (bool, error) {
+	err := os.Remove(cacheFile(dir, url))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("scraper: %w", err)
+	}
+	return true, nil
+}
+
+// ClearCache deletes every page cached in dir, a directory given to WithCacheDir, and
+// returns how many it deleted. Files that are not cached pages are left alone.
+func ClearCache(dir string) (int, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "[0-9a-f][0-9a-f]", "[0-9a-f]*"))
+	if err != nil {
+		return 0, fmt.Errorf("scraper: %w", err)
+	}
+	var n int
+	for _, f := range files {
+		name := filepath.Base(f)
+		if len(name) != 2*sha1.Size || name[:2] != filepath.Base(filepath.Dir(f)) {
+			continue
+		}
+		if err := os.Remove(f); err != nil {
+			return n, fmt.Errorf("scraper: %w", err)
+		}
+		n++
+	}
+	return n, nil
+}
@@ -0,0 +1,77 @@
+package scraper
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Chain is an Extractor that runs extractors in order, taking each field from the first
+// extractor that finds it. Later extractors are only run while some field is still empty.
+// Each field's Confidence.Source records which extractor produced it, so a wrong value
+// can be traced to its source without reading code.
+type Chain []Extractor
+
+// Extract implements Extractor.
+func (c Chain) Extract(doc *goquery.Selection) Fields {
+	f := Fields{Confidence: make(map[string]Confidence)}
+	for _, x := range c {
+		if f.Title != "" && f.Content != "" && f.Byline != "" && !f.Published.IsZero() && f.Publisher != "" {
+			break
+		}
+		got := x.Extract(doc)
+		if f.Title == "" && got.Title != "" {
+			f.Title = got.Title
+			f.Confidence["title"] = got.Confidence["title"]
+		}
+		if f.Content == "" && got.Content != "" {
+			f.Content = got.Content
+			f.Confidence["content"] = got.Confidence["content"]
+		}
+		if f.Byline == "" && got.Byline != "" {
+			f.Byline = got.Byline
+			f.Confidence["byline"] = got.Confidence["byline"]
+		}
+		if f.Published.IsZero() && !got.Published.IsZero() {
+			f.Published = got.Published
+			f.Confidence["published"] = got.Confidence["published"]
+		}
+		if f.Publisher == "" && got.Publisher != "" {
+			f.Publisher = got.Publisher
+			f.Confidence["publisher"] = got.Confidence["publisher"]
+		}
+	}
+	return f
+}
+
+// Extractors are the built-in extractors by name, for configuring a chain with ParseChain.
+var Extractors = map[string]Extractor{
+	"selectors":   DefaultExtractor,
+	"json-ld":     JSONLDExtractor{},
+	"meta":        MetaExtractor{},
+	"readability": ReadabilityExtractor{},
+}
+
+// ParseChain builds an extractor from a comma-separated list of Extractors names, such as
+// "json-ld,meta,selectors,readability". A single name yields that extractor alone.
+func ParseChain(spec string) (Extractor, error) {
+	var chain Chain
+	for _, name := range strings.Split(spec, ",") {
+		x, ok := Extractors[strings.TrimSpace(name)]
+		if !ok {
+			names := make([]string, 0, len(Extractors))
+			for n := range Extractors {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("unknown extractor %q (known: %s)", name, strings.Join(names, ", "))
+		}
+		chain = append(chain, x)
+	}
+	if len(chain) == 1 {
+		return chain[0], nil
+	}
+	return chain, nil
+}
@@ -0,0 +1,44 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotModified is returned by ScrapeIfChanged when the site says the page has not
+// changed since the copy the validators came from.
+var ErrNotModified = errors.New("scraper: page not modified")
+
+// validatorsKey is the context key of the validators a scrape's requests are made
+// conditional on.
+type validatorsKey struct{}
+
+// ScrapeIfChanged is like ScrapeContext, but asks the site to send the page only if it
+// has changed since the copy whose ETag and Last-Modified headers are given, as
+// Article.ETag and Article.LastModified hold them. If the site answers 304 Not Modified,
+// it returns ErrNotModified and extracts nothing. With both validators empty, or for a
+// rendered page, the page is fetched as usual.
+func (s *Scraper) ScrapeIfChanged(ctx context.Context, url, etag, lastModified string) (Article, error) {
+	if etag != "" || lastModified != "" {
+		ctx = context.WithValue(ctx, validatorsKey{}, [2]string{etag, lastModified})
+	}
+	return s.ScrapeContext(ctx, url)
+}
+
+// setValidators makes header conditional on the validators held by ctx, if any.
+func setValidators(ctx context.Context, header map[string][]string) {
+	v, ok := ctx.Value(validatorsKey{}).([2]string)
+	if !ok {
+		return
+	}
+	if v[0] != "" {
+		header["If-None-Match"] = []string{v[0]}
+	}
+	if v[1] != "" {
+		// Only a date the server sent back is sent back to it.
+		if _, err := time.Parse(time.RFC1123, v[1]); err == nil {
+			header["If-Modified-Since"] = []string{v[1]}
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package scraper
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// dateLayouts are the timestamp forms publishers use in markup, most common first.
+// Layouts without a zone are read as UTC.
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05",
+	time.DateOnly,
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"2 January 2006",
+}
+
+// ParseDate reads a publication timestamp as found in markup or written on a page and
+// returns it in UTC. It reports false for text that is not a recognized timestamp.
+func ParseDate(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// monthNames are the month names and usual abbreviations, in lowercase, of the
+// languages other than English that have byline rules. No name means different months
+// in two languages, so a date read without a language hint cannot be misread.
+var monthNames = map[string]map[string]time.Month{
+	"de": {
+		"januar": 1, "jänner": 1, "jan": 1, "februar": 2, "feb": 2, "märz": 3, "mär": 3, "mrz": 3,
+		"april": 4, "apr": 4, "mai": 5, "juni": 6, "jun": 6, "juli": 7, "jul": 7, "august": 8,
+		"aug": 8, "september": 9, "sept": 9, "sep": 9, "oktober": 10, "okt": 10,
+		"november": 11, "nov": 11, "dezember": 12, "dez": 12,
+	},
+	"fr": {
+		"janvier": 1, "janv": 1, "février": 2, "fevrier": 2, "févr": 2, "mars": 3, "avril": 4,
+		"avr": 4, "mai": 5, "juin": 6, "juillet": 7, "juil": 7, "août": 8, "aout": 8,
+		"septembre": 9, "sept": 9, "octobre": 10, "oct": 10, "novembre": 11, "nov": 11,
+		"décembre": 12, "decembre": 12, "déc": 12,
+	},
+	"es": {
+		"enero": 1, "ene": 1, "febrero": 2, "feb": 2, "marzo": 3, "mar": 3, "abril": 4, "abr": 4,
+		"mayo": 5, "may": 5, "junio": 6, "jun": 6, "julio": 7, "jul": 7, "agosto": 8, "ago": 8,
+		"septiembre": 9, "setiembre": 9, "sept": 9, "sep": 9, "octubre": 10, "oct": 10,
+		"noviembre": 11, "nov": 11, "diciembre": 12, "dic": 12,
+	},
+	"it": {
+		"gennaio": 1, "gen": 1, "febbraio": 2, "feb": 2, "marzo": 3, "mar": 3, "aprile": 4,
+		"apr": 4, "maggio": 5, "mag": 5, "giugno": 6, "giu": 6, "luglio": 7, "lug": 7,
+		"agosto": 8, "ago": 8, "settembre": 9, "set": 9, "ottobre": 10, "ott": 10,
+		"novembre": 11, "nov": 11, "dicembre": 12, "dic": 12,
+	},
+	"pt": {
+		"janeiro": 1, "jan": 1, "fevereiro": 2, "fev": 2, "março": 3, "marco": 3, "mar": 3,
+		"abril": 4, "abr": 4, "maio": 5, "mai": 5, "junho": 6, "jun": 6, "julho": 7, "jul": 7,
+		"agosto": 8, "ago": 8, "setembro": 9, "set": 9, "outubro": 10, "out": 10,
+		"novembro": 11, "nov": 11, "dezembro": 12, "dez": 12,
+	},
+	"nl": {
+		"januari": 1, "jan": 1, "februari": 2, "feb": 2, "maart": 3, "mrt": 3, "april": 4,
+		"apr": 4, "mei": 5, "juni": 6, "jun": 6, "juli": 7, "jul": 7, "augustus": 8, "aug": 8,
+		"september": 9, "sept": 9, "sep": 9, "oktober": 10, "okt": 10, "november": 11,
+		"nov": 11, "december": 12, "dec": 12,
+	},
+}
+
+var (
+	// wordDatePattern matches a day, month name, and year in the orders European languages
+	// write them: "15 de marzo de 2024", "15. März 2024", "1er mars 2024".
+	wordDatePattern = regexp.MustCompile(`(?i)\b(\d{1,2})(?:\.|º|°|er)?\s+(?:de\s+)?(\p{L}+)\.?,?\s+(?:de\s+|del\s+)?(\d{4})\b`)
+	// cjkDatePattern matches the year-month-day form of Chinese, Japanese, and Korean:
+	// "2024年3月15日", "2024년 3월 15일".
+	cjkDatePattern = regexp.MustCompile(`(\d{4})\s*[年년]\s*(\d{1,2})\s*[月월]\s*(\d{1,2})\s*[日일]`)
+)
+
+// ParseDateIn is ParseDate for a page written in lang, such as "es". It also reads a date
+// written with that language's month names ("15 de marzo de 2024") or in the Chinese,
+// Japanese, and Korean year-month-day form ("2024年3月15日"), even amid other text such
+// as "Publicado el 15 de marzo de 2024". Such a date is read as midnight UTC. With lang
+// empty, the month names of every language are tried.
+func ParseDateIn(s, lang string) (time.Time, bool) {
+	if t, ok := ParseDate(s); ok {
+		return t, true
+	}
+	if m := cjkDatePattern.FindStringSubmatch(s); m != nil {
+		month, _ := strconv.Atoi(m[2])
+		if t, ok := civilDate(m[1], time.Month(month), m[3]); ok {
+			return t, true
+		}
+	}
+	for _, m := range wordDatePattern.FindAllStringSubmatch(s, -1) {
+		if month, ok := monthNamed(strings.ToLower(m[2]), lang); ok {
+			if t, ok := civilDate(m[3], month, m[1]); ok {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// monthNamed returns the month called name in lang, or in any language if lang is empty.
+func monthNamed(name, lang string) (time.Month, bool) {
+	if lang != "" {
+		month, ok := monthNames[lang][name]
+		return month, ok
+	}
+	for _, names := range monthNames {
+		if month, ok := names[name]; ok {
+			return month, true
+		}
+	}
+	return 0, false
+}
+
+// civilDate returns midnight UTC of the given date, reporting false for a day the month
+// does not have.
+func civilDate(year string, month time.Month, day string) (time.Time, bool) {
+	y, err := strconv.Atoi(year)
+	if err != nil {
+		return time.Time{}, false
+	}
+	d, err := strconv.Atoi(day)
+	if err != nil || month < time.January || month > time.December {
+		return time.Time{}, false
+	}
+	t := time.Date(y, month, d, 0, 0, 0, 0, time.UTC)
+	if t.Day() != d || t.Month() != month {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// pageDate finds the publication time of doc, the page's <html> element, for extractors
+// that found none. The sources are tried most reliable first: JSON-LD datePublished, the
+// article:published_time meta tag, then a <time datetime> element, preferring one marked
+// as the publication date over the first on the page, which may be a related story's.
+// Last comes the text of a <time> element without a datetime, read as written in lang.
+func pageDate(doc *goquery.Selection, lang string) (time.Time, Confidence) {
+	if t, ok := jsonLDDate(doc); ok {
+		return t, Confidence{Score: 0.9, Source: "json-ld:datePublished"}
+	}
+	if t, ok := metaDate(doc); ok {
+		return t, Confidence{Score: 0.8, Source: "meta:article:published_time"}
+	}
+	for _, c := range []struct {
+		selector string
+		score    float64
+	}{
+		{`time[itemprop="datePublished"][datetime], time[pubdate][datetime]`, 0.8},
+		{`article time[datetime]`, 0.6},
+		{`time[datetime]`, 0.5},
+	} {
+		if t, ok := ParseDate(doc.Find(c.selector).First().AttrOr("datetime", "")); ok {
+			return t, Confidence{Score: c.score, Source: "selector:" + c.selector}
+		}
+	}
+	if t, ok := ParseDateIn(doc.Find("time").First().Text(), lang); ok {
+		return t, Confidence{Score: 0.4, Source: "selector:time"}
+	}
+	return time.Time{}, Confidence{}
+}
+
+// jsonLDDate returns the datePublished of the page's JSON-LD Article, if it has one.
+func jsonLDDate(doc *goquery.Selection) (time.Time, bool) {
+	var published time.Time
+	var found bool
+	doc.Find(`script[type="application/ld+json"]`).EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		var data any
+		if json.Unmarshal([]byte(s.Text()), &data) != nil {
+			return true
+		}
+		article := findArticle(data)
+		if article == nil {
+			return true
+		}
+		date, _ := article["datePublished"].(string)
+		published, found = ParseDate(date)
+		return false
+	})
+	return published, found
+}
+
+// metaDate returns the time in the page's article:published_time meta tag, if it has one.
+func metaDate(doc *goquery.Selection) (time.Time, bool) {
+	return ParseDate(doc.Find(`meta[property="article:published_time"]`).First().AttrOr("content", ""))
+}
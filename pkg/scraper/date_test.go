@@ -0,0 +1,38 @@
+package scraper
+
+import (
+	"testing"
+	"time"
+)
+
+// TestParseDateIn checks dates written out in words and in the CJK year-month-day form.
+func TestParseDateIn(t *testing.T) {
+	march15 := time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		text, lang string
+		want       time.Time
+		ok         bool
+	}{
+		{"15 de marzo de 2024", "es", march15, true},
+		{"Publicado el viernes, 15 de marzo de 2024 a las 10:00", "es", march15, true},
+		{"15. März 2024", "de", march15, true},
+		{"1er mars 2024", "fr", time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), true},
+		{"15 maart 2024", "nl", march15, true},
+		{"15 de março de 2024", "pt", march15, true},
+		{"15 marzo 2024", "it", march15, true},
+		{"15 de marzo de 2024", "", march15, true},
+		{"2024年3月15日", "", march15, true},
+		{"2024년 3월 15일", "", march15, true},
+		{"2024-03-15T10:00:00Z", "es", time.Date(2024, time.March, 15, 10, 0, 0, 0, time.UTC), true},
+		// A hint rules out other languages' month names.
+		{"15 maart 2024", "es", time.Time{}, false},
+		{"31 de febrero de 2024", "es", time.Time{}, false},
+		{"2024年2月30日", "", time.Time{}, false},
+		{"hace 3 horas", "es", time.Time{}, false},
+	} {
+		got, ok := ParseDateIn(tc.text, tc.lang)
+		if ok != tc.ok || !got.Equal(tc.want) {
+			t.Errorf("ParseDateIn(%q, %q) = %v, %v; want %v, %v", tc.text, tc.lang, got, ok, tc.want, tc.ok)
+		}
+	}
+}
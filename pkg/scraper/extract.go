@@ -0,0 +1,170 @@
+package scraper
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Extractor pulls an article's fields out of a parsed page.
+type Extractor interface {
+	// Extract returns the fields found in doc, the page's <html> element. It may be
+	// called by several scrapes at once.
+	Extract(doc *goquery.Selection) Fields
+}
+
+// Fields are the values an Extractor found, with a confidence for each one it set.
+type Fields struct {
+	Title     string
+	Content   string
+	Byline    string
+	Published time.Time // Published is when the article was published, in UTC.
+	Publisher string    // Publisher names the outlet, e.g. "Associated Press".
+	// Confidence is keyed by field name: "title", "content", "byline", "published", and
+	// "publisher".
+	Confidence map[string]Confidence
+}
+
+// Confidence describes how far an extracted field can be trusted.
+type Confidence struct {
+	// Score runs from 0 (a guess, or nothing found) to 1 (certain).
+	Score float64 `json:"score"`
+	// Source says how the value was derived, e.g. "selector:div.Page-authors".
+	Source string `json:"source"`
+}
+
+// Content lengths, in characters, outside which extracted text is suspicious: shorter is
+// usually a teaser or paywall stub, longer usually means navigation and comments came too.
+const (
+	minPlausibleContent = 200
+	maxPlausibleContent = 100000
+)
+
+// maxPlausibleByline is the longest byline, in characters, that is likely to be only names.
+const maxPlausibleByline = 150
+
+// SelectorExtractor is an Extractor driven by CSS selectors. Any of them may instead be
+// an XPath expression marked with XPathPrefix.
+type SelectorExtractor struct {
+	// Content selects the elements whose text makes up the article, one per line.
+	Content string
+	// Byline selects the element holding the byline.
+	Byline string
+	// BylineNames selects individual author names inside the byline element. They are
+	// joined with " and " when the byline element itself has no text.
+	BylineNames string
+	// Title selects the headline element. Empty leaves the title to other extractors.
+	Title string
+	// Published selects the element giving the publication date, read from its
+	// datetime or content attribute, or else its text. Empty leaves the date to others.
+	Published string
+	// DateLocale is the language the Published text is written in, e.g. "es". Empty
+	// tries every language's month names; see ParseDateIn.
+	DateLocale string
+}
+
+// DefaultExtractor reads paragraphs as content and AP News' "Page-authors" block as the byline.
+var DefaultExtractor = SelectorExtractor{Content: "p", Byline: "div.Page-authors", BylineNames: "a"}
+
+// Extract implements Extractor.
+func (x SelectorExtractor) Extract(doc *goquery.Selection) Fields {
+	// content is every matching element's text followed by a newline.
+	var content strings.Builder
+	Select(doc, x.Content).Each(func(_ int, p *goquery.Selection) {
+		content.WriteString(p.Text() + "\n")
+	})
+
+	// author will store a combined byline if present.
+	var author string
+	// authors is a slice to store individual author names, if found.
+	var authors []string
+	if x.Byline != "" {
+		Select(doc, x.Byline).Each(func(_ int, b *goquery.Selection) {
+			if text := strings.TrimSpace(b.Text()); text != "" {
+				author = text
+			}
+			if x.BylineNames == "" {
+				return
+			}
+			// Often each name in the byline is linked.
+			Select(b, x.BylineNames).Each(func(_ int, a *goquery.Selection) {
+				if name := strings.TrimSpace(a.Text()); name != "" {
+					authors = append(authors, name)
+				}
+			})
+		})
+	}
+	// If individual author names were found but the combined author text is empty, join them.
+	bylineSelector := x.Byline
+	if author == "" && len(authors) > 0 {
+		author = strings.Join(authors, " and ")
+		bylineSelector = x.Byline + " " + x.BylineNames
+	}
+
+	f := Fields{Content: content.String(), Byline: author, Confidence: make(map[string]Confidence)}
+	if f.Content != "" {
+		f.Confidence["content"] = Confidence{Score: contentScore(x.Content, f.Content), Source: "selector:" + x.Content}
+	}
+	if f.Byline != "" {
+		f.Confidence["byline"] = Confidence{Score: bylineScore(x.Byline, f.Byline), Source: "selector:" + bylineSelector}
+	}
+	if x.Title != "" {
+		if f.Title = cleanTitle(Select(doc, x.Title).First().Text()); f.Title != "" {
+			f.Confidence["title"] = Confidence{Score: specificity(x.Title), Source: "selector:" + x.Title}
+		}
+	}
+	if x.Published != "" {
+		el := Select(doc, x.Published).First()
+		for _, v := range []string{el.AttrOr("datetime", ""), el.AttrOr("content", ""), el.Text()} {
+			if t, ok := ParseDateIn(v, x.DateLocale); ok {
+				f.Published = t
+				f.Confidence["published"] = Confidence{Score: specificity(x.Published), Source: "selector:" + x.Published}
+				break
+			}
+		}
+	}
+	return f
+}
+
+// contentScore rates content found with selector: a broad selector such as "p" picks up
+// stray paragraphs, and text of an implausible length is probably not just the article.
+func contentScore(selector, content string) float64 {
+	return specificity(selector) * lengthSanity(content)
+}
+
+// lengthSanity is 1 for content of a plausible article length and lower outside it.
+func lengthSanity(content string) float64 {
+	switch n := utf8.RuneCountInString(strings.TrimSpace(content)); {
+	case n < minPlausibleContent:
+		return 0.5
+	case n > maxPlausibleContent:
+		return 0.7
+	}
+	return 1
+}
+
+// bylineScore rates a byline found with selector by how specific the selector is and
+// whether the text is short enough to be only names.
+func bylineScore(selector, text string) float64 {
+	score := specificity(selector)
+	if utf8.RuneCountInString(text) > maxPlausibleByline {
+		score *= 0.5
+	}
+	return score
+}
+
+// specificity scores a CSS or XPath selector by how narrowly it targets the page: an ID
+// is nearly certain to be the intended element, a class or attribute likely, a bare tag
+// a guess.
+func specificity(selector string) float64 {
+	switch {
+	case strings.Contains(selector, "#"), strings.Contains(selector, "@id"):
+		return 0.95
+	case strings.ContainsAny(selector, ".["):
+		return 0.85
+	default:
+		return 0.6
+	}
+}
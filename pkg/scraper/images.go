@@ -0,0 +1,113 @@
+package scraper
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Image is a picture that belongs to the article.
+type Image struct {
+	// URL is the image's absolute address.
+	URL string `json:"url"`
+	// Alt is the image's alternative text.
+	Alt string `json:"alt,omitempty"`
+	// Caption is the text of the <figcaption> of the figure holding the image.
+	Caption string `json:"caption,omitempty"`
+	// Lead marks the hero image the page declares with og:image.
+	Lead bool `json:"lead,omitempty"`
+}
+
+// imageScopes select the part of a page that holds the article's own images, tried in
+// order, so that logos, author photos, and teasers for other stories are left out.
+var imageScopes = []string{`[itemprop="articleBody"]`, "article", "main", "body"}
+
+// imageSources are the attributes an <img> may keep its address in, lazy-loading ones
+// first since their src is often a placeholder.
+var imageSources = []string{"data-src", "data-lazy-src", "data-original", "src"}
+
+// pageImages collects the lead image of doc, the page's <html> element, followed by the
+// images in the article body with their alt text and captions. resolve makes an address
+// absolute; each image is listed once.
+func pageImages(doc *goquery.Selection, resolve func(string) string) []Image {
+	var images []Image
+	seen := make(map[string]int) // seen maps each image's URL to its index in images.
+	if src := firstContent(doc, `meta[property="og:image"]`, `meta[property="og:image:url"]`, `meta[name="twitter:image"]`); src != "" {
+		if u := resolve(src); u != "" {
+			alt := cleanTitle(doc.Find(`meta[property="og:image:alt"]`).First().AttrOr("content", ""))
+			images = append(images, Image{URL: u, Alt: alt, Lead: true})
+			seen[u] = 0
+		}
+	}
+
+	scope := doc.Find("body")
+	for _, sel := range imageScopes {
+		if s := doc.Find(sel); s.Length() > 0 {
+			scope = s.First()
+			break
+		}
+	}
+	scope.Find("img").Each(func(_ int, img *goquery.Selection) {
+		if img.Closest("nav, footer, aside").Length() > 0 || tiny(img) {
+			return
+		}
+		u := resolve(imageSource(img))
+		if u == "" {
+			return
+		}
+		alt := cleanTitle(img.AttrOr("alt", ""))
+		caption := cleanTitle(img.Closest("figure").Find("figcaption").First().Text())
+		if i, ok := seen[u]; ok {
+			// The lead image often appears in the body too, where it has its caption.
+			if images[i].Alt == "" {
+				images[i].Alt = alt
+			}
+			if images[i].Caption == "" {
+				images[i].Caption = caption
+			}
+			return
+		}
+		seen[u] = len(images)
+		images = append(images, Image{URL: u, Alt: alt, Caption: caption})
+	})
+	return images
+}
+
+// firstContent returns the content of the first of the meta selectors that has one.
+func firstContent(doc *goquery.Selection, selectors ...string) string {
+	for _, sel := range selectors {
+		if v := strings.TrimSpace(doc.Find(sel).First().AttrOr("content", "")); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// imageSource returns the address of img, falling back to the first srcset candidate.
+// Inline data: images, usually placeholders, are skipped.
+func imageSource(img *goquery.Selection) string {
+	for _, attr := range imageSources {
+		if v := strings.TrimSpace(img.AttrOr(attr, "")); v != "" && !strings.HasPrefix(v, "data:") {
+			return v
+		}
+	}
+	if set, ok := img.Attr("srcset"); ok {
+		first, _, _ := strings.Cut(set, ",")
+		if fields := strings.Fields(first); len(fields) > 0 && !strings.HasPrefix(fields[0], "data:") {
+			return fields[0]
+		}
+	}
+	return ""
+}
+
+// tiny reports whether img declares itself at most a few pixels wide or high, as
+// tracking pixels and spacers do.
+func tiny(img *goquery.Selection) bool {
+	for _, attr := range []string{"width", "height"} {
+		if n, err := strconv.Atoi(strings.TrimSpace(img.AttrOr(attr, ""))); err == nil && n <= 2 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,189 @@
+package scraper
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LimitRule throttles requests to the hosts it matches, like colly's LimitRule. Unlike
+// colly's, which a collector keeps to itself, the limits hold across every scrape of a
+// Scraper, and they apply to each matching host separately.
+type LimitRule struct {
+	// DomainGlob selects the hosts the rule applies to, in path.Match syntax, e.g.
+	// "*.apnews.com" or "*" for every host.
+	DomainGlob string
+	// Delay is the least time between the starts of two requests to a host.
+	Delay time.Duration
+	// RandomDelay adds up to this much more to each Delay, so requests do not arrive
+	// on a fixed beat.
+	RandomDelay time.Duration
+	// Parallelism is the most requests in flight to a host at once. Zero means no limit.
+	Parallelism int
+}
+
+// matches reports whether the rule applies to host.
+func (r LimitRule) matches(host string) bool {
+	ok, _ := path.Match(r.DomainGlob, host)
+	return ok
+}
+
+// limiter enforces a set of LimitRules, keeping the state of every host it has seen.
+type limiter struct {
+	rules []LimitRule
+	// adaptive slows down requests to the hosts that ask for it, every host included.
+	adaptive bool
+
+	mu    sync.Mutex
+	hosts map[string]*hostLimit
+}
+
+// hostLimit is the throttling state of one host.
+type hostLimit struct {
+	rule  LimitRule
+	slots chan struct{} // slots holds a token per request in flight; nil without a parallelism limit.
+
+	mu      sync.Mutex
+	next    time.Time     // next is the earliest start of the host's next request.
+	penalty time.Duration // penalty is added to the rule's delay while the host asks to slow down.
+}
+
+// Bounds of a host's penalty, and the longest Retry-After honored. A host's penalty
+// starts at minPenalty, doubles with each further answer asking to slow down, and halves
+// with each successful one until it is under minPenalty and dropped.
+const (
+	minPenalty    = time.Second
+	maxPenalty    = time.Minute
+	maxRetryAfter = 10 * time.Minute
+)
+
+// forHost returns the state of host, or nil if no rule matches it.
+func (l *limiter) forHost(host string) *hostLimit {
+	host = strings.ToLower(host)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if h, ok := l.hosts[host]; ok {
+		return h
+	}
+	var h *hostLimit
+	for _, r := range l.rules {
+		if r.matches(host) {
+			h = &hostLimit{rule: r}
+			if r.Parallelism > 0 {
+				h.slots = make(chan struct{}, r.Parallelism)
+			}
+			break
+		}
+	}
+	if h == nil && l.adaptive {
+		h = &hostLimit{rule: LimitRule{DomainGlob: host}}
+	}
+	if l.hosts == nil {
+		l.hosts = make(map[string]*hostLimit)
+	}
+	l.hosts[host] = h
+	return h
+}
+
+// limitedTransport is a RoundTripper that waits for its limiter before every request.
+type limitedTransport struct {
+	base    http.RoundTripper
+	limiter *limiter
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	h := t.limiter.forHost(req.URL.Hostname())
+	if h == nil {
+		return t.base.RoundTrip(req)
+	}
+	if h.slots != nil {
+		select {
+		case h.slots <- struct{}{}:
+			defer func() { <-h.slots }()
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+
+	h.mu.Lock()
+	start := time.Now()
+	if h.next.After(start) {
+		start = h.next
+	}
+	gap := h.rule.Delay + h.penalty
+	if h.rule.RandomDelay > 0 {
+		gap += rand.N(h.rule.RandomDelay)
+	}
+	h.next = start.Add(gap)
+	h.mu.Unlock()
+
+	select {
+	case <-time.After(time.Until(start)):
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err == nil && t.limiter.adaptive {
+		h.adapt(resp, time.Now())
+	}
+	return resp, err
+}
+
+// adapt slows down requests to the host when resp asks for it, with 429 Too Many
+// Requests or 503 Service Unavailable: none starts before its Retry-After has passed, and
+// the host's penalty grows. A successful response shrinks the penalty again.
+func (h *hostLimit) adapt(resp *http.Response, now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		if resp.StatusCode < 400 && h.penalty > 0 {
+			if h.penalty /= 2; h.penalty < minPenalty {
+				h.penalty = 0
+			}
+		}
+		return
+	}
+	h.penalty = min(max(2*h.penalty, minPenalty), maxPenalty)
+	if until := now.Add(retryAfter(resp.Header.Get("Retry-After"), now)); until.After(h.next) {
+		h.next = until
+	}
+}
+
+// retryAfter returns the wait a Retry-After header asks for, in seconds or as an HTTP
+// date, up to maxRetryAfter. A missing or invalid header asks for none.
+func retryAfter(v string, now time.Time) time.Duration {
+	var d time.Duration
+	if secs, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+		d = time.Duration(secs) * time.Second
+	} else if t, err := http.ParseTime(v); err == nil {
+		d = t.Sub(now)
+	}
+	return min(max(d, 0), maxRetryAfter)
+}
+
+// raiseDelay makes the gap between requests to host at least d, as a robots.txt
+// crawl delay asks. A host no rule matched is throttled from then on.
+func (l *limiter) raiseDelay(host string, d time.Duration) {
+	host = strings.ToLower(host)
+	l.mu.Lock()
+	h := l.hosts[host]
+	if h == nil {
+		h = &hostLimit{rule: LimitRule{DomainGlob: host}}
+		if l.hosts == nil {
+			l.hosts = make(map[string]*hostLimit)
+		}
+		l.hosts[host] = h
+	}
+	l.mu.Unlock()
+
+	h.mu.Lock()
+	if h.rule.Delay < d {
+		h.rule.Delay = d
+	}
+	h.mu.Unlock()
+}
@@ -0,0 +1,24 @@
+package scraper
+
+import "time"
+
+// Metrics receives the counts and timings of a Scraper, for exporting to whatever
+// monitoring the host application uses. Its methods may be called by several scrapes
+// at once.
+//
+// The counters are "scrapes", "scrape_errors", "retries", "renders", "render_errors",
+// "print_fallbacks", "robots_disallowed", and "circuit_open", for requests refused by
+// WithCircuitBreaker; the timings are "scrape", for the whole of
+// a call to Scrape, and "fetch", for each page request made for it.
+type Metrics interface {
+	// Count adds n to the counter called name.
+	Count(name string, n int64)
+	// Observe records that the operation called name took d.
+	Observe(name string, d time.Duration)
+}
+
+// nopMetrics is the Metrics of a Scraper not given any, which discards everything.
+type nopMetrics struct{}
+
+func (nopMetrics) Count(string, int64)           {}
+func (nopMetrics) Observe(string, time.Duration) {}
@@ -0,0 +1,230 @@
+package scraper
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Option configures a Scraper created by New.
+type Option func(*Scraper)
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(ua string) Option {
+	return func(s *Scraper) {
+		s.userAgent = ua
+	}
+}
+
+// WithUserAgents sends each scrape with a user agent picked at random from agents, such
+// as BrowserUserAgents, in place of the one set by WithUserAgent. That one is still
+// what robots.txt rules are matched against.
+func WithUserAgents(agents ...string) Option {
+	return func(s *Scraper) {
+		s.userAgents = agents
+	}
+}
+
+// WithLogger sends the scraper's log messages, such as retries and fetch errors, to l
+// instead of slog.Default().
+func WithLogger(l *slog.Logger) Option {
+	return func(s *Scraper) {
+		s.logger = l
+	}
+}
+
+// WithMetrics reports the scraper's counts and timings to m.
+func WithMetrics(m Metrics) Option {
+	return func(s *Scraper) {
+		s.metrics = m
+	}
+}
+
+// WithTimeout limits how long a single request may take, including reading the body.
+func WithTimeout(d time.Duration) Option {
+	return func(s *Scraper) {
+		s.timeout = d
+	}
+}
+
+// WithTransport makes the scraper send requests through rt instead of the shared
+// default transport: every page, retry, print version, and robots.txt fetch goes through
+// it, though pages given to ScrapeHTML and pages rendered by a Renderer do not. Use
+// NewTransport to build a pooled one, and wrap it to observe or audit traffic, or pass a
+// test double that answers without the network.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(s *Scraper) {
+		s.transport = rt
+	}
+}
+
+// WithTransportWrapper wraps the transport the scraper ends up with, the shared default
+// one or that of WithTransport, in wrap, for middleware such as request signing or a
+// corporate proxy's authentication that should keep the pooled connections. Wrappers
+// given by several options are applied in order, so the last is outermost.
+func WithTransportWrapper(wrap func(http.RoundTripper) http.RoundTripper) Option {
+	return func(s *Scraper) {
+		s.wrappers = append(s.wrappers, wrap)
+	}
+}
+
+// WithSiteSigner signs every request to domain and its subdomains with sg, robots.txt
+// fetches included, once the wrappers of WithTransportWrapper are done with it and just
+// before it goes through the transport. The most specific registered domain wins.
+func WithSiteSigner(domain string, sg Signer) Option {
+	return func(s *Scraper) {
+		if s.signers == nil {
+			s.signers = make(map[string]Signer)
+		}
+		s.signers[strings.ToLower(domain)] = sg
+	}
+}
+
+// WithExtractor replaces DefaultExtractor, for sites whose markup it does not understand.
+func WithExtractor(x Extractor) Option {
+	return func(s *Scraper) {
+		s.extractor = x
+	}
+}
+
+// WithOverrideExtractor tries x ahead of every other extractor, site extractors
+// included, on every page, so that its fields win wherever it finds them. Fields it
+// misses come from the extractors that would otherwise apply.
+func WithOverrideExtractor(x Extractor) Option {
+	return func(s *Scraper) {
+		s.override = x
+	}
+}
+
+// WithAllowedDomains restricts scraping to the given hosts (e.g. "apnews.com").
+// Visiting any other host fails with colly.ErrForbiddenDomain.
+func WithAllowedDomains(domains ...string) Option {
+	return func(s *Scraper) {
+		s.allowedDomains = append(s.allowedDomains, domains...)
+	}
+}
+
+// WithHeaders adds headers to every request, such as Accept-Language or a cookie.
+func WithHeaders(h http.Header) Option {
+	return func(s *Scraper) {
+		if s.headers == nil {
+			s.headers = make(http.Header)
+		}
+		for k, v := range h {
+			s.headers[k] = append(s.headers[k], v...)
+		}
+	}
+}
+
+// WithSiteHeaders adds h to every request to domain and its subdomains, after and over
+// the headers of WithHeaders. The most specific registered domain wins.
+func WithSiteHeaders(domain string, h http.Header) Option {
+	return func(s *Scraper) {
+		if s.siteHeaders == nil {
+			s.siteHeaders = make(map[string]http.Header)
+		}
+		s.siteHeaders[strings.ToLower(domain)] = h
+	}
+}
+
+// WithCacheDir caches fetched pages in dir, so repeated scrapes of a URL are served from
+// disk without a request. Pages supplied to ScrapeHTML are never cached.
+func WithCacheDir(dir string) Option {
+	return func(s *Scraper) {
+		s.cacheDir = dir
+	}
+}
+
+// WithRenderer fetches pages through r instead of a plain HTTP request, for sites that
+// build their content with JavaScript. Every page is rendered unless WithRenderRules
+// says otherwise.
+func WithRenderer(r Renderer) Option {
+	return func(s *Scraper) {
+		s.renderer = r
+	}
+}
+
+// WithRenderRules renders only the pages r selects, fetching the others plainly, and caps
+// how many pages are rendered at once. It has no effect without WithRenderer.
+func WithRenderRules(r RenderRules) Option {
+	return func(s *Scraper) {
+		s.renderRules = &r
+		s.renderSlots = nil
+		if r.MaxConcurrent > 0 {
+			s.renderSlots = make(chan struct{}, r.MaxConcurrent)
+		}
+	}
+}
+
+// WithRetry retries fetches that fail with a network error, such as a timeout or a reset
+// connection, a 5xx status, or 429 Too Many Requests, as described by p.
+func WithRetry(p RetryPolicy) Option {
+	return func(s *Scraper) {
+		s.retry = p
+	}
+}
+
+// WithSiteExtractor uses x instead of the default extractor for pages on domain and its
+// subdomains. The most specific registered domain wins.
+func WithSiteExtractor(domain string, x Extractor) Option {
+	return func(s *Scraper) {
+		if s.siteExtractors == nil {
+			s.siteExtractors = make(map[string]Extractor)
+		}
+		s.siteExtractors[strings.ToLower(domain)] = x
+	}
+}
+
+// WithCookieJar keeps cookies in jar for every request, so cookies a site sets on one
+// page are sent when the next is scraped. Without it, each scrape starts with no cookies.
+func WithCookieJar(jar http.CookieJar) Option {
+	return func(s *Scraper) {
+		s.jar = jar
+	}
+}
+
+// WithLimits throttles requests by host. For each host the first matching rule applies;
+// hosts no rule matches are not throttled.
+func WithLimits(rules ...LimitRule) Option {
+	return func(s *Scraper) {
+		s.limiter = &limiter{rules: rules}
+	}
+}
+
+// WithRetryAfter makes the scraper back off from hosts that answer 429 Too Many Requests
+// or 503 Service Unavailable: no request goes to the host before the Retry-After it sent
+// has passed, up to ten minutes, and each such answer widens the gap between requests to
+// the host, from a second up to a minute on top of any LimitRule's Delay. The gap narrows
+// again with each successful response. This holds across every scrape of the Scraper,
+// retries included.
+func WithRetryAfter() Option {
+	return func(s *Scraper) {
+		s.retryAfter = true
+	}
+}
+
+// WithCircuitBreaker stops requests to hosts that keep failing, as cb describes: while a
+// host's circuit is open, scrapes of its URLs fail at once with a *CircuitOpenError and
+// are not retried. The circuits hold across every scrape of the Scraper.
+func WithCircuitBreaker(cb CircuitBreaker) Option {
+	return func(s *Scraper) {
+		if cb.Failures <= 0 {
+			cb.Failures = DefaultCircuitBreaker.Failures
+		}
+		if cb.Cooldown <= 0 {
+			cb.Cooldown = DefaultCircuitBreaker.Cooldown
+		}
+		s.breaker = &breaker{opts: cb}
+	}
+}
+
+// WithRobotsTxt makes the scraper honor each site's robots.txt: a URL it disallows for
+// the scraper's user agent fails with a *DisallowedError without being fetched, and a
+// crawl delay it sets spaces out requests to the host like a LimitRule's Delay, or
+// lengthens a shorter one.
+func WithRobotsTxt() Option {
+	return func(s *Scraper) {
+		s.robots = &robotsTxt{}
+	}
+}
@@ -0,0 +1,114 @@
+package scraper
+
+import (
+	"context"
+	neturl "net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultPrintPatterns are the print-version URL patterns tried by WithPrintFallback when
+// none are given. See WithPrintFallback for the placeholders.
+var DefaultPrintPatterns = []string{
+	"{scheme}://{host}{path}?print=true",
+	"{scheme}://{host}{path}/print/",
+	"{scheme}://{host}/print{path}",
+}
+
+// printFallback holds the print-version fallback settings.
+type printFallback struct {
+	minLength int // minLength is the content length, in runes, below which a page counts as truncated.
+	patterns  []string
+}
+
+// WithPrintFallback tries the article's print version when the standard extraction fails,
+// finds no content, or finds fewer than minLength characters (as paywalled pages often do).
+//
+// Each pattern is a URL template in which {scheme}, {host}, and {path} are replaced by the
+// article URL's parts; {path} has no trailing slash and the original query is dropped. The
+// patterns are tried in order, and the first print page with more content than the
+// original wins. With no patterns, DefaultPrintPatterns are used.
+func WithPrintFallback(minLength int, patterns ...string) Option {
+	return func(s *Scraper) {
+		if len(patterns) == 0 {
+			patterns = DefaultPrintPatterns
+		}
+		s.print = &printFallback{minLength: minLength, patterns: patterns}
+	}
+}
+
+// WithPrintFallbackDomains limits the print fallback of WithPrintFallback to pages on
+// domains and their subdomains.
+func WithPrintFallbackDomains(domains ...string) Option {
+	return func(s *Scraper) {
+		s.printDomains = append(s.printDomains, domains...)
+	}
+}
+
+// needed reports whether a scrape's result calls for the print version.
+func (f *printFallback) needed(a Article, err error) bool {
+	n := utf8.RuneCountInString(strings.TrimSpace(a.Content))
+	return err != nil || n == 0 || n < f.minLength
+}
+
+// printURLs expands the patterns for rawURL.
+func (f *printFallback) printURLs(rawURL string) []string {
+	u, err := neturl.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return nil
+	}
+	r := strings.NewReplacer("{scheme}", u.Scheme, "{host}", u.Host, "{path}", strings.TrimSuffix(u.EscapedPath(), "/"))
+	urls := make([]string, 0, len(f.patterns))
+	for _, p := range f.patterns {
+		urls = append(urls, r.Replace(p))
+	}
+	return urls
+}
+
+// printVersion tries each print URL for rawURL and returns the first page with more content
+// than orig. If none is better, orig and origErr are returned unchanged. The robots
+// directives of the original page still apply to its print version, so they are kept.
+func (s *Scraper) printVersion(ctx context.Context, rawURL string, orig Article, origErr error) (Article, error) {
+	have := utf8.RuneCountInString(strings.TrimSpace(orig.Content))
+	for _, u := range s.print.printURLs(rawURL) {
+		if s.robots != nil && s.checkRobots(ctx, u) != nil {
+			// A print version robots.txt forbids is not tried.
+			continue
+		}
+		a, _, err := s.scrape(ctx, u, s.transport)
+		if err != nil || utf8.RuneCountInString(strings.TrimSpace(a.Content)) <= have {
+			continue
+		}
+		s.metrics.Count("print_fallbacks", 1)
+		s.logger.Info("Using print version", "print_url", u, "url", rawURL)
+		// The article is still the one at rawURL, and so are its addresses.
+		a.URL = rawURL
+		if orig.FinalURL != "" {
+			a.FinalURL, a.Canonical = orig.FinalURL, orig.Canonical
+		}
+		if a.Byline == "" {
+			a.Byline, a.Authors = orig.Byline, orig.Authors
+		}
+		if a.Title == "" {
+			a.Title = orig.Title
+		}
+		if a.Published.IsZero() {
+			a.Published = orig.Published
+		}
+		if a.Publisher == "" {
+			a.Publisher = orig.Publisher
+		}
+		if a.Section == "" && len(a.Tags) == 0 && len(a.Keywords) == 0 {
+			a.Section, a.Tags, a.Keywords = orig.Section, orig.Tags, orig.Keywords
+		}
+		if len(orig.Images) > 0 {
+			// Print layouts tend to drop the lead image and figures.
+			a.Images = orig.Images
+		}
+		for _, d := range orig.Robots {
+			a.Robots = a.Robots.add(d)
+		}
+		return a, nil
+	}
+	return orig, origErr
+}
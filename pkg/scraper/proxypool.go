@@ -0,0 +1,190 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	neturl "net/url"
+	"sync"
+	"time"
+)
+
+// ErrNoProxy is returned for requests made through a ProxyPool while every one of its
+// proxies is evicted. Requests are never sent without a proxy instead.
+var ErrNoProxy = errors.New("scraper: every proxy in the pool is evicted")
+
+// ProxyPoolOptions tune a ProxyPool.
+type ProxyPoolOptions struct {
+	// MaxFailures is how many failures in a row evict a proxy: requests through it that
+	// fail to connect or are answered 403 Forbidden, 407 Proxy Authentication Required,
+	// or 429 Too Many Requests.
+	MaxFailures int
+	// Cooldown is how long an evicted proxy is left out before it is tried again.
+	Cooldown time.Duration
+	// CheckURL, if set, is fetched through an evicted proxy once its cooldown is over,
+	// and the proxy is only taken back if that succeeds. Without it, the proxy is taken
+	// back on probation: a single failure evicts it again.
+	CheckURL string
+	// Logger is told of evictions and returns; slog.Default() if nil.
+	Logger *slog.Logger
+}
+
+// DefaultProxyPoolOptions are used for the zero fields of the options given to
+// NewProxyPool.
+var DefaultProxyPoolOptions = ProxyPoolOptions{MaxFailures: 3, Cooldown: 10 * time.Minute}
+
+// proxyCheckTimeout limits how long a health check may take.
+const proxyCheckTimeout = 15 * time.Second
+
+// ProxyPool spreads requests across proxies, each request going through the next
+// healthy one in turn, and evicts proxies that keep failing, such as those a site has
+// started to block. It is safe for concurrent use.
+type ProxyPool struct {
+	opts ProxyPoolOptions
+
+	mu      sync.Mutex
+	proxies []*poolProxy
+	next    int             // next is the index of the proxy to try first for the next request.
+	check   *http.Transport // check is the transport health checks go through.
+}
+
+// poolProxy is a proxy of a ProxyPool and its health.
+type poolProxy struct {
+	url      *neturl.URL
+	failures int       // failures counts the failures in a row.
+	evicted  time.Time // evicted is when the proxy was evicted; zero while it is in use.
+	checking bool      // checking is set while a health check of the proxy is under way.
+}
+
+// NewProxyPool returns a pool of proxies, such as those parsed by ParseProxy.
+func NewProxyPool(proxies []*neturl.URL, opts ProxyPoolOptions) *ProxyPool {
+	if opts.MaxFailures <= 0 {
+		opts.MaxFailures = DefaultProxyPoolOptions.MaxFailures
+	}
+	if opts.Cooldown <= 0 {
+		opts.Cooldown = DefaultProxyPoolOptions.Cooldown
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	p := &ProxyPool{opts: opts}
+	for _, u := range proxies {
+		p.proxies = append(p.proxies, &poolProxy{url: u})
+	}
+	return p
+}
+
+// proxyKey is the context key of the proxy a request was given by the pool.
+type proxyKey struct{}
+
+// Transport returns an http.RoundTripper that sends every request through t by way of
+// the pool's proxies, for WithTransport. It sets t's Proxy, so t should be used only
+// through the pool from then on.
+func (p *ProxyPool) Transport(t *http.Transport) http.RoundTripper {
+	t.Proxy = func(req *http.Request) (*neturl.URL, error) {
+		if u, ok := req.Context().Value(proxyKey{}).(*neturl.URL); ok {
+			return u, nil
+		}
+		return nil, ErrNoProxy
+	}
+	p.mu.Lock()
+	p.check = t
+	p.mu.Unlock()
+	return &proxyTransport{pool: p, next: t}
+}
+
+// proxyTransport is the http.RoundTripper returned by ProxyPool.Transport.
+type proxyTransport struct {
+	pool *ProxyPool
+	next http.RoundTripper
+}
+
+// RoundTrip sends req through the next healthy proxy and records how it went.
+func (t *proxyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	proxy := t.pool.pick()
+	if proxy == nil {
+		return nil, ErrNoProxy
+	}
+	resp, err := t.next.RoundTrip(req.WithContext(context.WithValue(req.Context(), proxyKey{}, proxy.url)))
+	t.pool.report(proxy, err == nil && !blockedStatus(resp.StatusCode))
+	return resp, err
+}
+
+// blockedStatus reports whether a response with this status suggests the proxy it came
+// through is blocked or refused.
+func blockedStatus(code int) bool {
+	return code == http.StatusForbidden || code == http.StatusProxyAuthRequired || code == http.StatusTooManyRequests
+}
+
+// pick returns the next proxy in use, or nil if every one is evicted. Evicted proxies
+// whose cooldown is over are checked or taken back on the way.
+func (p *ProxyPool) pick() *poolProxy {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	for _, proxy := range p.proxies {
+		if proxy.evicted.IsZero() || proxy.checking || now.Sub(proxy.evicted) < p.opts.Cooldown {
+			continue
+		}
+		if p.opts.CheckURL != "" && p.check != nil {
+			proxy.checking = true
+			go p.healthCheck(proxy)
+			continue
+		}
+		// On probation, the next failure evicts it again.
+		proxy.evicted, proxy.failures = time.Time{}, p.opts.MaxFailures-1
+		p.opts.Logger.Info("Trying proxy again", "proxy", proxy.url.Redacted())
+	}
+	for range p.proxies {
+		proxy := p.proxies[p.next]
+		p.next = (p.next + 1) % len(p.proxies)
+		if proxy.evicted.IsZero() {
+			return proxy
+		}
+	}
+	return nil
+}
+
+// report records whether a request through proxy succeeded, evicting the proxy once it
+// has failed too often in a row.
+func (p *ProxyPool) report(proxy *poolProxy, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if ok {
+		proxy.failures = 0
+		return
+	}
+	proxy.failures++
+	// Requests already under way when the proxy was evicted do not evict it again.
+	if proxy.failures >= p.opts.MaxFailures && proxy.evicted.IsZero() {
+		proxy.evicted = time.Now()
+		p.opts.Logger.Warn("Evicted proxy", "proxy", proxy.url.Redacted(), "failures", proxy.failures)
+	}
+}
+
+// healthCheck fetches CheckURL through proxy, taking it back if that succeeds and
+// starting another cooldown if not.
+func (p *ProxyPool) healthCheck(proxy *poolProxy) {
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), proxyKey{}, proxy.url), proxyCheckTimeout)
+	defer cancel()
+	ok := false
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.opts.CheckURL, nil)
+	if err == nil {
+		var resp *http.Response
+		if resp, err = p.check.RoundTrip(req); err == nil {
+			resp.Body.Close()
+			ok = resp.StatusCode < 400
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	proxy.checking = false
+	if !ok {
+		proxy.evicted = time.Now()
+		return
+	}
+	proxy.evicted, proxy.failures = time.Time{}, 0
+	p.opts.Logger.Info("Proxy passed its health check and is back in use", "proxy", proxy.url.Redacted())
+}
@@ -0,0 +1,185 @@
+package scraper
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// minParagraph is the shortest paragraph, in characters, that counts towards a
+// container's score; shorter ones are usually captions, credits, or buttons.
+const minParagraph = 40
+
+// skipContainers are the elements that never hold the article.
+const skipContainers = "nav, header, footer, aside, form, script, style, noscript"
+
+// positiveNames and negativeNames are words in class and id attributes that mark an
+// element as the article, or as something around it.
+var positiveNames, negativeNames = nameSet("article body content entry main post story text prose"), nameSet(`
+	ad ads advert advertisement banner comment comments consent cookie cookies footer gdpr
+	menu modal nav navigation newsletter outbrain popup promo recommended related share
+	sharing sidebar signup social sponsor sponsored subscribe taboola`)
+
+// nameSet builds a word set from a whitespace-separated list.
+func nameSet(list string) map[string]bool {
+	m := make(map[string]bool)
+	for _, w := range strings.Fields(list) {
+		m[w] = true
+	}
+	return m
+}
+
+// ReadabilityExtractor finds the article without site knowledge, the way reader modes do:
+// every substantial paragraph scores for its parent (and half for its grandparent); a
+// container's score is then weighed by its class and id, and by how much of its text is
+// links; and the paragraphs of the best container are the content, less those in
+// boilerplate inside it, such as a "related stories" box. It finds no byline.
+type ReadabilityExtractor struct{}
+
+// Extract implements Extractor.
+func (ReadabilityExtractor) Extract(doc *goquery.Selection) Fields {
+	f := Fields{Confidence: make(map[string]Confidence)}
+
+	scores := make(map[*html.Node]float64)
+	var order []*goquery.Selection // order keeps candidates in document order, for stable ties.
+	credit := func(s *goquery.Selection, points float64) {
+		if s.Length() == 0 {
+			return
+		}
+		if _, ok := scores[s.Get(0)]; !ok {
+			order = append(order, s)
+		}
+		scores[s.Get(0)] += points
+	}
+	doc.Find("p").Each(func(_ int, p *goquery.Selection) {
+		if boilerplateNode(p) {
+			return
+		}
+		text := strings.TrimSpace(p.Text())
+		n := utf8.RuneCountInString(text)
+		if n < minParagraph {
+			return
+		}
+		// Commas mark running prose rather than lists of links or labels.
+		points := float64(n) + 10*float64(strings.Count(text, ","))
+		credit(p.Parent(), points)
+		credit(p.Parent().Parent(), points/2)
+	})
+
+	var best *goquery.Selection
+	var bestScore float64
+	for _, s := range order {
+		score := scores[s.Get(0)] * nameWeight(s) * (1 - linkDensity(s))
+		if best == nil || score > bestScore {
+			best, bestScore = s, score
+		}
+	}
+	if best == nil {
+		return f
+	}
+
+	var content strings.Builder
+	best.Find("p").Each(func(_ int, p *goquery.Selection) {
+		if !boilerplateNode(p) {
+			content.WriteString(p.Text() + "\n")
+		}
+	})
+	f.Content = content.String()
+	f.Confidence["content"] = Confidence{Score: 0.6 * lengthSanity(f.Content), Source: "readability"}
+	return f
+}
+
+// boilerplateNode reports whether s lies in an element that never holds the article:
+// navigation and the like, or one whose class or id names it as boilerplate. The
+// <body> and <html> elements are not judged by their names, which describe the page.
+func boilerplateNode(s *goquery.Selection) bool {
+	if s.Closest(skipContainers).Length() > 0 {
+		return true
+	}
+	for n := s.Get(0); n != nil && n.Type == html.ElementNode && n.Data != "body" && n.Data != "html"; n = n.Parent {
+		for _, w := range nodeNames(n) {
+			if negativeNames[w] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nameWeight is how far s's class and id suggest it is the article: more than 1 for
+// names such as "article-body", less for names such as "sidebar".
+func nameWeight(s *goquery.Selection) float64 {
+	weight := 1.0
+	for _, w := range nodeNames(s.Get(0)) {
+		switch {
+		case negativeNames[w]:
+			return 0.25
+		case positiveNames[w]:
+			weight = 1.25
+		}
+	}
+	return weight
+}
+
+// nodeNames returns the lowercase words of n's class and id, split at anything that
+// is not a letter, so "story-body__inner" gives "story", "body", and "inner".
+func nodeNames(n *html.Node) []string {
+	var words []string
+	for _, a := range n.Attr {
+		if a.Key == "class" || a.Key == "id" {
+			words = append(words, strings.FieldsFunc(strings.ToLower(a.Val), func(r rune) bool { return !unicode.IsLetter(r) })...)
+		}
+	}
+	return words
+}
+
+// linkDensity is the share of s's text that is link text: high for menus and lists of
+// other stories, low for prose.
+func linkDensity(s *goquery.Selection) float64 {
+	total := utf8.RuneCountInString(strings.TrimSpace(s.Text()))
+	if total == 0 {
+		return 0
+	}
+	var links int
+	s.Find("a").Each(func(_ int, a *goquery.Selection) {
+		links += utf8.RuneCountInString(strings.TrimSpace(a.Text()))
+	})
+	return min(float64(links)/float64(total), 1)
+}
+
+// boilerplatePhrases are found in the cookie banners, sign-up prompts, and footers that
+// a plain paragraph selector picks up along with the article.
+var boilerplatePhrases = []string{
+	"cookie", "subscribe", "newsletter", "sign up", "all rights reserved", "privacy policy",
+	"terms of use", "terms of service", "advertisement", "related stories", "read more",
+	"follow us", "share this",
+}
+
+// looksLikeBoilerplate reports whether content, one paragraph per line, is padded with
+// page furniture: when at least a third of its paragraphs are too short to be prose
+// or use the phrases of banners and footers.
+func looksLikeBoilerplate(content string) bool {
+	var total, furniture int
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		total++
+		if utf8.RuneCountInString(line) < minParagraph {
+			furniture++
+			continue
+		}
+		lower := strings.ToLower(line)
+		for _, p := range boilerplatePhrases {
+			if strings.Contains(lower, p) {
+				furniture++
+				break
+			}
+		}
+	}
+	return total > 0 && 3*furniture >= total
+}
@@ -0,0 +1,91 @@
+package scraper
+
+import (
+	"context"
+	neturl "net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// RenderRules decide which pages go through the Renderer, since rendering a page in a
+// browser is many times slower and heavier than fetching it. A page is rendered up front
+// if its domain is listed; any other page is fetched plainly first and rendered only if
+// that comes back empty or short, as the rules allow.
+type RenderRules struct {
+	// Domains are always rendered, subdomains included, e.g. "washingtonpost.com".
+	Domains []string
+	// WhenEmpty renders a page whose plain fetch failed or found no content.
+	WhenEmpty bool
+	// EmptyDomains are rendered as WhenEmpty says, whatever WhenEmpty is set to.
+	EmptyDomains []string
+	// MinLength renders a page whose plain fetch found fewer characters of content than
+	// this. Zero disables the check.
+	MinLength int
+	// MaxConcurrent is the most pages rendered at once, across every scrape of the
+	// Scraper. Zero means no limit.
+	MaxConcurrent int
+}
+
+// onDomain reports whether rawURL is on one of domains, subdomains included.
+func onDomain(rawURL string, domains []string) bool {
+	u, err := neturl.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	for _, d := range domains {
+		d = strings.TrimPrefix(strings.ToLower(d), "www.")
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// retry reports whether the result of a plain fetch of rawURL calls for rendering the page.
+func (r *RenderRules) retry(rawURL string, a Article, err error) bool {
+	n := utf8.RuneCountInString(strings.TrimSpace(a.Content))
+	whenEmpty := r.WhenEmpty || onDomain(rawURL, r.EmptyDomains)
+	return (whenEmpty && (err != nil || n == 0)) || (r.MinLength > 0 && n < r.MinLength)
+}
+
+// renderHTML renders url with the scraper's Renderer, waiting for a free slot if renders
+// are capped. No render is started once ctx is done.
+func (s *Scraper) renderHTML(ctx context.Context, url string) (string, error) {
+	if s.renderSlots != nil {
+		select {
+		case s.renderSlots <- struct{}{}:
+			defer func() { <-s.renderSlots }()
+		case <-ctx.Done():
+			return "", context.Cause(ctx)
+		}
+	}
+	if err := context.Cause(ctx); err != nil {
+		return "", err
+	}
+	return s.renderer.Render(url)
+}
+
+// render renders url and extracts the article from the result.
+func (s *Scraper) render(ctx context.Context, url string) (Article, error) {
+	s.metrics.Count("renders", 1)
+	html, err := s.renderHTML(ctx, url)
+	if err != nil {
+		s.metrics.Count("render_errors", 1)
+		return Article{}, err
+	}
+	a, _, err := s.scrape(ctx, url, staticTransport(html))
+	return a, err
+}
+
+// fetch scrapes url with a plain request, retrying as the retry policy allows.
+func (s *Scraper) fetch(ctx context.Context, url string) (Article, error) {
+	var a Article
+	var err error
+	err = s.withRetries(ctx, func() (bool, error) {
+		var retryable bool
+		a, retryable, err = s.scrape(ctx, url, s.transport)
+		return retryable, err
+	})
+	return a, err
+}
@@ -0,0 +1,113 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// resolverTTL is how long a Resolver keeps an answer. The system resolver does not say
+// how long the record lives, so this is a guess at the usual TTL of a news site's host.
+const resolverTTL = 5 * time.Minute
+
+// Resolver looks up host names for a transport built with it in TransportOptions,
+// keeping each answer a few minutes, so that the lookups of a batch's hosts can be made
+// ahead of their first requests with Prefetch. It is safe for concurrent use.
+type Resolver struct {
+	mu    sync.Mutex
+	hosts map[string]*lookup
+}
+
+// lookup is one host's lookup, under way until done is closed.
+type lookup struct {
+	done    chan struct{}
+	addrs   []string
+	err     error
+	expires time.Time
+}
+
+// NewResolver creates a Resolver with no answers kept yet.
+func NewResolver() *Resolver {
+	return &Resolver{hosts: make(map[string]*lookup)}
+}
+
+// Prefetch starts looking up host in the background, unless its answer is kept or
+// already being looked up. It does nothing on a nil Resolver.
+func (r *Resolver) Prefetch(host string) {
+	if r == nil || host == "" || net.ParseIP(host) != nil {
+		return
+	}
+	go r.lookupHost(context.Background(), host)
+}
+
+// lookupHost returns the addresses of host, looking them up only if no answer is kept
+// or being looked up. A failed lookup is not kept.
+func (r *Resolver) lookupHost(ctx context.Context, host string) ([]string, error) {
+	r.mu.Lock()
+	l, ok := r.hosts[host]
+	if !ok || (l.expires.Before(time.Now()) && isClosed(l.done)) {
+		l = &lookup{done: make(chan struct{})}
+		r.hosts[host] = l
+		r.mu.Unlock()
+		// The lookup is shared, so it must not end with the ctx of one caller.
+		lctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		l.addrs, l.err = net.DefaultResolver.LookupHost(lctx, host)
+		cancel()
+		l.expires = time.Now().Add(resolverTTL)
+		close(l.done)
+		if l.err != nil {
+			r.mu.Lock()
+			if r.hosts[host] == l {
+				delete(r.hosts, host)
+			}
+			r.mu.Unlock()
+		}
+	} else {
+		r.mu.Unlock()
+	}
+	select {
+	case <-l.done:
+		return l.addrs, l.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// isClosed reports whether ch is closed.
+func isClosed(ch chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+// dialContext dials addr with d, resolving its host through r, and tries each of the
+// host's addresses in turn.
+func (r *Resolver) dialContext(d *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil || net.ParseIP(host) != nil {
+			return d.DialContext(ctx, network, addr)
+		}
+		addrs, err := r.lookupHost(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		var errs []error
+		for _, ip := range addrs {
+			conn, err := d.DialContext(ctx, network, net.JoinHostPort(ip, port))
+			if err == nil {
+				return conn, nil
+			}
+			errs = append(errs, err)
+			if ctx.Err() != nil {
+				break
+			}
+		}
+		return nil, errors.Join(errs...)
+	}
+}
@@ -0,0 +1,38 @@
+package scraper
+
+import "strings"
+
+// Robots holds the lowercase robots directives a page declared about itself, from
+// <meta name="robots"> tags and X-Robots-Tag headers (e.g. "noarchive", "nosnippet").
+type Robots []string
+
+// Has reports whether the page declared directive d. "none" implies noindex and nofollow.
+func (r Robots) Has(d string) bool {
+	for _, v := range r {
+		if v == d || (v == "none" && (d == "noindex" || d == "nofollow")) {
+			return true
+		}
+	}
+	return false
+}
+
+// add parses a comma-separated directive list and appends any new directives.
+// Header values scoped to a specific crawler ("googlebot: noarchive") are ignored,
+// since they are not addressed to this scraper.
+func (r Robots) add(value string) Robots {
+	if agent, _, ok := strings.Cut(value, ":"); ok && !strings.ContainsAny(agent, ",") {
+		// Directives such as "unavailable_after: <date>" also contain a colon but are
+		// recognized by name; anything else before a colon is a user agent.
+		if a := strings.ToLower(strings.TrimSpace(agent)); a != "unavailable_after" && a != "max-snippet" &&
+			a != "max-image-preview" && a != "max-video-preview" {
+			return r
+		}
+	}
+	for _, d := range strings.Split(value, ",") {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if d != "" && !r.Has(d) {
+			r = append(r, d)
+		}
+	}
+	return r
+}
@@ -0,0 +1,130 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"sync"
+
+	"github.com/temoto/robotstxt"
+)
+
+// DisallowedError is returned when a site's robots.txt forbids the scraper from fetching
+// a URL. No request is made for the page itself.
+type DisallowedError struct {
+	URL string
+	// Agent is the user agent token the robots.txt rules were matched against.
+	Agent string
+}
+
+// Error names the URL and the agent it was refused to.
+func (e *DisallowedError) Error() string {
+	return "robots.txt disallows " + e.URL + " for user agent " + e.Agent
+}
+
+// robotsTxt fetches each site's robots.txt once and keeps it for the life of the Scraper.
+type robotsTxt struct {
+	mu    sync.Mutex
+	sites map[string]*robotsSite // sites is keyed by scheme and host, e.g. "https://apnews.com".
+}
+
+// robotsSite is one site's robots.txt, fetched by whichever scrape asks for it first.
+type robotsSite struct {
+	done chan struct{}
+	data *robotstxt.RobotsData
+	err  error
+}
+
+// robotsAgent returns the token robots.txt groups are matched against: the product name
+// of the user agent ("my-bot" for "my-bot/1.0 (+https://example.com)"), or colly's for
+// the default user agent.
+func (s *Scraper) robotsAgent() string {
+	fields := strings.Fields(s.userAgent)
+	if len(fields) == 0 {
+		return "colly"
+	}
+	agent, _, _ := strings.Cut(fields[0], "/")
+	return agent
+}
+
+// checkRobots fetches the robots.txt of rawURL's site, if it has not been fetched yet,
+// and returns a *DisallowedError if it forbids rawURL. A crawl delay it asks for is
+// applied to every later request to the host. A robots.txt that cannot be fetched is
+// an error, and is tried again on the next scrape of the site; one answered with a 4xx
+// status allows everything and one answered with a 5xx status forbids everything.
+// Waiting for another scrape's fetch of it is given up once ctx is done; the fetch itself
+// is shared, and so is not cancelled.
+func (s *Scraper) checkRobots(ctx context.Context, rawURL string) error {
+	u, err := neturl.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	site := u.Scheme + "://" + u.Host
+
+	s.robots.mu.Lock()
+	r, ok := s.robots.sites[site]
+	if !ok {
+		r = &robotsSite{done: make(chan struct{})}
+		if s.robots.sites == nil {
+			s.robots.sites = make(map[string]*robotsSite)
+		}
+		s.robots.sites[site] = r
+	}
+	s.robots.mu.Unlock()
+
+	if !ok {
+		r.data, r.err = s.fetchRobots(site + "/robots.txt")
+		if r.err != nil {
+			s.robots.mu.Lock()
+			delete(s.robots.sites, site)
+			s.robots.mu.Unlock()
+		} else if d := r.data.FindGroup(s.robotsAgent()).CrawlDelay; d > 0 {
+			s.limiter.raiseDelay(u.Hostname(), d)
+		}
+		close(r.done)
+	}
+	select {
+	case <-r.done:
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+	if r.err != nil {
+		return fmt.Errorf("fetching robots.txt for %s: %w", site, r.err)
+	}
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	if !r.data.TestAgent(path, s.robotsAgent()) {
+		return &DisallowedError{URL: rawURL, Agent: s.robotsAgent()}
+	}
+	return nil
+}
+
+// fetchRobots downloads and parses one robots.txt through the scraper's transport and
+// limits, with its user agent and headers.
+func (s *Scraper) fetchRobots(url string) (*robotstxt.RobotsData, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range s.headers {
+		req.Header[k] = append([]string(nil), v...)
+	}
+	if s.userAgent != "" {
+		req.Header.Set("User-Agent", s.userAgent)
+	}
+	client := &http.Client{Transport: limitedTransport{base: s.transport, limiter: s.limiter}, Timeout: s.timeout, Jar: s.jar}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return robotstxt.FromResponse(resp)
+}
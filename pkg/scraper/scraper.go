@@ -0,0 +1,717 @@
+// Package scraper scrapes news articles: it fetches a page, extracts the article content,
+// title, byline, and publication date, and reports the robots directives the page declared about itself.
+//
+// A Scraper is configured with options:
+//
+//	s := scraper.New(scraper.WithUserAgent("my-bot/1.0"), scraper.WithTimeout(30*time.Second))
+//	article, err := s.Scrape("https://apnews.com/article/...")
+//
+// # Concurrency
+//
+// A Scraper is safe for concurrent use by multiple goroutines, and is meant to be shared,
+// for example by every handler of a web service, with no locking of its own: it is not
+// changed after New, and each call to Scrape or ScrapeHTML builds its own collector.
+// What scrapes do share is safe for concurrent use too: the pooled transport, the
+// throttling of WithLimits, the robots.txt rules of WithRobotsTxt, and the cache of
+// WithCacheDir, which fetches a URL once at a time so that two scrapes of it cannot
+// corrupt its cache file. The values given to options, such as a Renderer, a cookie
+// jar, or an Extractor, must be safe for concurrent use as well; those of this package
+// are. The package-level maps Extractors and SiteExtractors are read by ParseChain and
+// New, and must not be changed once scrapers are in use.
+package scraper
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gocolly/colly/v2"
+
+	"github.com/hail2skins/zero-scraper/internal/byline"
+)
+
+// TransportOptions tunes a pooled HTTP transport built by NewTransport.
+type TransportOptions struct {
+	// MaxIdleConnsPerHost is the number of keep-alive connections kept open to each host.
+	MaxIdleConnsPerHost int
+	// IdleConnTimeout is how long an unused keep-alive connection stays open.
+	IdleConnTimeout time.Duration
+	// Proxy, if set, is the HTTP, HTTPS, or SOCKS5 proxy every request goes through;
+	// ParseProxy builds it. Otherwise the proxy environment variables are honored:
+	// HTTP_PROXY, HTTPS_PROXY, and ALL_PROXY, with the exceptions in NO_PROXY.
+	Proxy *neturl.URL
+	// Resolver, if set, looks up host names, keeping its answers for a while so that
+	// they can be prefetched. Hosts reached through a proxy are looked up by the proxy.
+	Resolver *Resolver
+}
+
+// DefaultTransportOptions are used for the transport shared by scrapers that were not
+// given one with WithTransport.
+var DefaultTransportOptions = TransportOptions{
+	MaxIdleConnsPerHost: 4,
+	IdleConnTimeout:     90 * time.Second,
+}
+
+// defaultTransport is shared by every Scraper without its own transport, so keep-alive
+// connections and TLS sessions persist from one article to the next instead of being
+// renegotiated per URL.
+var defaultTransport = NewTransport(DefaultTransportOptions)
+
+// NewTransport builds a pooled transport with a TLS session cache for resumption.
+// Reuse one transport for every scrape in a process rather than building one per URL.
+func NewTransport(opts TransportOptions) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+	t.IdleConnTimeout = opts.IdleConnTimeout
+	t.TLSClientConfig = &tls.Config{ClientSessionCache: tls.NewLRUClientSessionCache(0)}
+	if opts.Resolver != nil {
+		// The dialer's settings are those of http.DefaultTransport.
+		t.DialContext = opts.Resolver.dialContext(&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second})
+	}
+	if opts.Proxy != nil {
+		t.Proxy = http.ProxyURL(opts.Proxy)
+	} else {
+		t.Proxy = environmentProxy()
+	}
+	return t
+}
+
+// Scraper fetches and extracts articles. It is safe for concurrent use; every scrape
+// uses its own collector.
+type Scraper struct {
+	userAgent      string
+	userAgents     []string // userAgents, if set, are picked from at random for each scrape.
+	timeout        time.Duration
+	transport      http.RoundTripper
+	wrappers       []func(http.RoundTripper) http.RoundTripper // wrappers are applied to transport by New.
+	signers        map[string]Signer                           // signers maps lowercase domains to the signers of their requests.
+	extractor      Extractor
+	override       Extractor            // override, if set, is tried before any other extractor.
+	siteExtractors map[string]Extractor // siteExtractors maps lowercase domains to their extractors.
+	allowedDomains []string
+	headers        http.Header
+	siteHeaders    map[string]http.Header // siteHeaders maps lowercase domains to headers for their requests.
+	cacheDir       string
+	renderer       Renderer
+	renderRules    *RenderRules  // renderRules, if set, limit rendering to the pages they select.
+	renderSlots    chan struct{} // renderSlots holds a token per render in flight; nil without a cap.
+	retry          RetryPolicy
+	print          *printFallback // print, if set, is tried when extraction fails or comes back short.
+	printDomains   []string       // printDomains, if set, limit the print fallback to their pages.
+	jar            http.CookieJar // jar, if set, is shared by every collector instead of one each.
+	limiter        *limiter       // limiter, if set, throttles every request to the network.
+	robots         *robotsTxt     // robots, if set, is checked before every page is fetched.
+	retryAfter     bool           // retryAfter slows down requests to hosts that ask for it.
+	breaker        *breaker       // breaker, if set, refuses requests to hosts that keep failing.
+	logger         *slog.Logger
+	metrics        Metrics
+}
+
+// Renderer produces the final HTML of a page, for example by loading it in a headless
+// browser so that content built by JavaScript is present.
+type Renderer interface {
+	Render(url string) (string, error)
+}
+
+// RetryPolicy controls how often a failed fetch is retried.
+type RetryPolicy struct {
+	// Attempts is the total number of tries, including the first. Zero or one disables retries.
+	Attempts int
+	// Backoff is the wait before the first retry; it doubles after each further failure.
+	Backoff time.Duration
+	// MaxBackoff caps each wait. Zero means no cap.
+	MaxBackoff time.Duration
+	// Jitter is the fraction, from 0 to 1, of each wait that is left to chance, so that
+	// scrapes failing together do not retry together: with 0.5, a wait of 2s lasts
+	// anywhere from 1s to 2s.
+	Jitter float64
+}
+
+// wait returns how long to wait before the retry that follows a backoff of delay.
+func (p RetryPolicy) wait(delay time.Duration) time.Duration {
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	if j := min(max(p.Jitter, 0), 1); j > 0 && delay > 0 {
+		delay -= time.Duration(j * rand.Float64() * float64(delay))
+	}
+	return delay
+}
+
+// New creates a Scraper. Without options it uses colly's default user agent and request
+// timeout, the shared pooled transport, and DefaultExtractor behind the SiteExtractors;
+// it may visit any domain, does not cache, does not retry, does not consult robots.txt,
+// and does not heed Retry-After.
+// It logs to slog.Default() and reports no metrics.
+func New(opts ...Option) *Scraper {
+	s := &Scraper{transport: defaultTransport, extractor: DefaultExtractor, siteExtractors: make(map[string]Extractor, len(SiteExtractors)), logger: slog.Default(), metrics: nopMetrics{}}
+	for domain, x := range SiteExtractors {
+		s.siteExtractors[domain] = x
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if len(s.signers) > 0 {
+		s.transport = signingTransport{base: s.transport, signers: s.signers}
+	}
+	for _, wrap := range s.wrappers {
+		s.transport = wrap(s.transport)
+	}
+	if (s.robots != nil || s.retryAfter) && s.limiter == nil {
+		// Crawl delays and Retry-After are enforced by the limiter, even with no limits of
+		// our own.
+		s.limiter = &limiter{}
+	}
+	if s.limiter != nil {
+		s.limiter.adaptive = s.retryAfter
+	}
+	if s.breaker != nil {
+		s.breaker.logger, s.breaker.metrics = s.logger, s.metrics
+	}
+	return s
+}
+
+// Scrape fetches the article at url using Colly and returns what was extracted from it,
+// including the raw HTML so it can be stored and re-extracted later without fetching
+// the page again.
+func (s *Scraper) Scrape(url string) (Article, error) {
+	return s.ScrapeContext(context.Background(), url)
+}
+
+// ScrapeContext is like Scrape, but gives up once ctx is done, returning its error: the
+// request in flight is cancelled, and no retry, throttled request, or render is started.
+// A render under way is left to finish within the Renderer's own limits.
+func (s *Scraper) ScrapeContext(ctx context.Context, url string) (Article, error) {
+	start := time.Now()
+	s.metrics.Count("scrapes", 1)
+	a, err := s.scrapeURL(ctx, url)
+	if err != nil && !errors.Is(err, ErrNotModified) {
+		s.metrics.Count("scrape_errors", 1)
+	}
+	s.metrics.Observe("scrape", time.Since(start))
+	return a, err
+}
+
+// scrapeURL does the work of ScrapeContext.
+func (s *Scraper) scrapeURL(ctx context.Context, url string) (Article, error) {
+	if s.robots != nil {
+		if err := s.checkRobots(ctx, url); err != nil {
+			var disallowed *DisallowedError
+			if errors.As(err, &disallowed) {
+				s.metrics.Count("robots_disallowed", 1)
+			}
+			return Article{}, err
+		}
+	}
+	var a Article
+	var err error
+	// renderErr is why rendering failed when the plain result was kept instead.
+	var renderErr error
+	switch {
+	case s.renderer == nil:
+		a, err = s.fetch(ctx, url)
+	case s.renderRules == nil || onDomain(url, s.renderRules.Domains):
+		a, err = s.render(ctx, url)
+	default:
+		a, err = s.fetch(ctx, url)
+		if ctx.Err() == nil && !errors.Is(err, ErrNotModified) && s.renderRules.retry(url, a, err) {
+			// Keep the plain result unless rendering finds more.
+			if r, rerr := s.render(ctx, url); rerr == nil && utf8.RuneCountInString(strings.TrimSpace(r.Content)) > utf8.RuneCountInString(strings.TrimSpace(a.Content)) {
+				a, err = r, nil
+			} else if rerr != nil {
+				s.logger.Warn("Rendering failed", "url", url, "err", rerr)
+				renderErr = rerr
+			}
+		}
+	}
+	if s.print != nil && ctx.Err() == nil && !errors.Is(err, ErrNotModified) && (s.printDomains == nil || onDomain(url, s.printDomains)) && s.print.needed(a, err) {
+		a, err = s.printVersion(ctx, url, a, err)
+	}
+	if err == nil {
+		// The fallbacks may have filled in fields the first extraction missed.
+		a.FieldErrors = fieldErrors(a)
+		if _, missing := a.FieldErrors["content"]; missing && renderErr != nil {
+			a.FieldErrors["content"] = "not found on the page, and rendering it failed: " + renderErr.Error()
+		}
+	}
+	return a, err
+}
+
+// withRetries calls try until it succeeds, fails permanently, the retry policy runs
+// out of attempts, or ctx is done. try reports whether its error is worth retrying.
+func (s *Scraper) withRetries(ctx context.Context, try func() (bool, error)) error {
+	delay := s.retry.Backoff
+	for attempt := 1; ; attempt++ {
+		retryable, err := try()
+		if err == nil || !retryable || attempt >= s.retry.Attempts || ctx.Err() != nil {
+			return err
+		}
+		s.metrics.Count("retries", 1)
+		s.logger.Info("Retrying after error", "err", err, "attempt", attempt+1, "of", s.retry.Attempts)
+		select {
+		case <-time.After(s.retry.wait(delay)):
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		}
+		if s.retry.MaxBackoff <= 0 || delay < s.retry.MaxBackoff {
+			delay *= 2
+		}
+	}
+}
+
+// ScrapeHTML runs the same extraction as Scrape over HTML that has already been
+// fetched (for example, a page captured by the browser bookmarklet), as if it had been
+// served from url. No network request is made.
+func (s *Scraper) ScrapeHTML(url, html string) (Article, error) {
+	a, _, err := s.scrape(context.Background(), url, staticTransport(html))
+	return a, err
+}
+
+// staticTransport is an http.RoundTripper that answers every request with the same HTML body.
+type staticTransport string
+
+// RoundTrip returns a 200 response containing the static HTML.
+func (t staticTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Status:     "200 OK",
+		Header:     http.Header{"Content-Type": []string{"text/html; charset=utf-8"}},
+		Body:       io.NopCloser(strings.NewReader(string(t))),
+		Request:    req,
+	}, nil
+}
+
+// contextTransport is an http.RoundTripper that cancels its requests, bodies included,
+// once ctx is done. Colly gives its requests no context of their own to cancel.
+type contextTransport struct {
+	base http.RoundTripper
+	ctx  context.Context
+}
+
+// RoundTrip implements http.RoundTripper. The request keeps its own context, which holds
+// the client's timeout, and is cancelled by either.
+func (t contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithCancel(req.Context())
+	stop := context.AfterFunc(t.ctx, cancel)
+	done := func() {
+		stop()
+		cancel()
+	}
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		done()
+		if cause := context.Cause(t.ctx); cause != nil {
+			return nil, cause
+		}
+		return nil, err
+	}
+	resp.Body = &contextBody{ReadCloser: resp.Body, ctx: t.ctx, done: done}
+	return resp, nil
+}
+
+// contextBody is the body of a response from a contextTransport, whose request is
+// cancelled only once the body is closed.
+type contextBody struct {
+	io.ReadCloser
+	ctx  context.Context
+	done func()
+}
+
+// Read reports the cause of a cancelled read as its error.
+func (b *contextBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		if cause := context.Cause(b.ctx); cause != nil {
+			err = cause
+		}
+	}
+	return n, err
+}
+
+// Close implements io.Closer.
+func (b *contextBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.done()
+	return err
+}
+
+// collector creates a Colly collector with the scraper's settings, using transport.
+// Responses are cached, and requests throttled, only for real network requests. Its
+// requests are cancelled once ctx is done.
+func (s *Scraper) collector(ctx context.Context, transport http.RoundTripper) *colly.Collector {
+	// Create a new Colly collector.
+	// The collector handles HTTP requests, response parsing, and event callbacks.
+	c := colly.NewCollector()
+	c.AllowedDomains = s.allowedDomains
+	// Every request of one scrape, retries included, goes out with the same user agent.
+	if ua := s.pickUserAgent(); ua != "" {
+		c.UserAgent = ua
+	}
+	if _, static := transport.(staticTransport); !static {
+		c.CacheDir = s.cacheDir
+		if s.limiter != nil {
+			transport = limitedTransport{base: transport, limiter: s.limiter}
+		}
+		// A host whose circuit is open is refused before waiting on its limits.
+		if s.breaker != nil {
+			transport = breakerTransport{base: transport, breaker: s.breaker}
+		}
+		if ctx.Done() != nil {
+			transport = contextTransport{base: transport, ctx: ctx}
+		}
+	}
+	c.WithTransport(transport)
+	if s.jar != nil {
+		c.SetCookieJar(s.jar)
+	}
+	if s.timeout > 0 {
+		c.SetRequestTimeout(s.timeout)
+	}
+	if _, static := transport.(staticTransport); !static && ctx.Value(validatorsKey{}) != nil {
+		c.OnRequest(func(r *colly.Request) {
+			setValidators(ctx, *r.Headers)
+		})
+	}
+	if len(s.headers) > 0 || len(s.siteHeaders) > 0 {
+		// The values are copied, so that nothing done to one request's headers can reach
+		// the scraper's, or another request's.
+		c.OnRequest(func(r *colly.Request) {
+			for k, v := range s.headers {
+				(*r.Headers)[k] = append([]string(nil), v...)
+			}
+			if h, ok := forDomain(s.siteHeaders, r.URL.String()); ok {
+				for k, v := range h {
+					(*r.Headers)[k] = append([]string(nil), v...)
+				}
+			}
+		})
+	}
+	return c
+}
+
+// transientStatus reports whether a failed response with this status is worth retrying.
+// Colly reports network errors with a zero status.
+func transientStatus(code int) bool {
+	return code == 0 || code >= 500 || code == http.StatusTooManyRequests
+}
+
+// extractorFor returns the extractor registered for rawURL's host or its closest parent
+// domain, chained before the default extractor so that fields the site extractor misses
+// still come from it. Without a site extractor it returns the default one. An override
+// extractor goes ahead of either. Dates written out in words are read in the language
+// set for the domain with byline.SetDomainLocales.
+func (s *Scraper) extractorFor(rawURL string) Extractor {
+	x := s.extractor
+	if site, ok := forDomain(s.siteExtractors, rawURL); ok {
+		x = Chain{site, s.extractor}
+	}
+	if s.override != nil {
+		x = Chain{s.override, x}
+	}
+	return withDateLocale(x, byline.LocaleFor(rawURL))
+}
+
+// withDateLocale returns x with each SelectorExtractor in it, including those in a
+// Chain, reading dates as written in lang unless it has a DateLocale of its own.
+func withDateLocale(x Extractor, lang string) Extractor {
+	if lang == "" {
+		return x
+	}
+	switch x := x.(type) {
+	case SelectorExtractor:
+		if x.DateLocale == "" {
+			x.DateLocale = lang
+		}
+		return x
+	case Chain:
+		c := make(Chain, len(x))
+		for i, e := range x {
+			c[i] = withDateLocale(e, lang)
+		}
+		return c
+	}
+	return x
+}
+
+// forDomain returns the value registered in m, keyed by lowercase domain, for rawURL's
+// host or its closest parent domain.
+func forDomain[T any](m map[string]T, rawURL string) (T, bool) {
+	var zero T
+	if len(m) == 0 {
+		return zero, false
+	}
+	u, err := neturl.Parse(rawURL)
+	if err != nil {
+		return zero, false
+	}
+	for host := strings.ToLower(u.Hostname()); host != ""; {
+		if v, ok := m[host]; ok {
+			return v, true
+		}
+		_, parent, ok := strings.Cut(host, ".")
+		if !ok {
+			break
+		}
+		host = parent
+	}
+	return zero, false
+}
+
+// cacheLocks serialize the cached fetches of each URL, across every Scraper, picked by a
+// hash of the URL. Colly writes a page's cache file under a fixed temporary name, so two
+// fetches of one URL at once would write over each other.
+var cacheLocks [64]sync.Mutex
+
+// cacheLock returns the lock of url's cached fetches.
+func cacheLock(url string) *sync.Mutex {
+	h := fnv.New32a()
+	h.Write([]byte(url))
+	return &cacheLocks[h.Sum32()%uint32(len(cacheLocks))]
+}
+
+// scrape visits url with a collector that uses transport. Besides the results, it reports
+// whether a failure is transient: a network error, a 5xx status, or 429 Too Many Requests.
+func (s *Scraper) scrape(ctx context.Context, url string, transport http.RoundTripper) (Article, bool, error) {
+	if _, static := transport.(staticTransport); !static && s.cacheDir != "" {
+		mu := cacheLock(url)
+		mu.Lock()
+		defer mu.Unlock()
+	}
+
+	// a accumulates the article's text, byline, and robots directives from headers and meta tags.
+	a := Article{URL: url}
+
+	// transient records whether the request failed in a way worth retrying, and
+	// notModified whether a conditional request found the page unchanged.
+	var transient, notModified bool
+
+	c := s.collector(ctx, transport)
+	extractor := s.extractorFor(url)
+	_, siteKnown := forDomain(s.siteExtractors, url)
+
+	// Hand the whole parsed page to the extractor.
+	c.OnHTML("html", func(e *colly.HTMLElement) {
+		f := extractor.Extract(e.DOM)
+		if !siteKnown && f.Confidence["content"].Source == "selector:"+DefaultExtractor.Content && looksLikeBoilerplate(f.Content) {
+			// Every paragraph of a page no site extractor knows came back, and with it the
+			// banners and footers; find the article's own paragraphs instead.
+			if r := (ReadabilityExtractor{}).Extract(e.DOM); r.Content != "" {
+				f.Content, f.Confidence["content"] = r.Content, r.Confidence["content"]
+			}
+		}
+		a.Title, a.Content, a.Byline, a.Published, a.Publisher, a.Confidence = f.Title, f.Content, f.Byline, f.Published, f.Publisher, f.Confidence
+		if a.Confidence == nil {
+			a.Confidence = make(map[string]Confidence)
+		}
+		if a.Title == "" {
+			if title, c := pageTitle(e.DOM); title != "" {
+				a.Title = title
+				a.Confidence["title"] = c
+			}
+		}
+		if a.Published.IsZero() {
+			if published, c := pageDate(e.DOM, byline.LocaleFor(url)); !published.IsZero() {
+				a.Published = published
+				a.Confidence["published"] = c
+			}
+		}
+		a.Images = pageImages(e.DOM, e.Request.AbsoluteURL)
+		a.Section, a.Tags, a.Keywords = pageTaxonomy(e.DOM)
+	})
+
+	// Collect robots directives sent as an X-Robots-Tag header.
+	// The raw body is kept too.
+	c.OnResponse(func(r *colly.Response) {
+		a.HTML = string(r.Body)
+		a.FetchedAt = time.Now().UTC()
+		// Colly points the request at the last URL of a redirect chain.
+		a.FinalURL = r.Request.URL.String()
+		a.ETag, a.LastModified = r.Headers.Get("ETag"), r.Headers.Get("Last-Modified")
+		for _, v := range r.Headers.Values("X-Robots-Tag") {
+			a.Robots = a.Robots.add(v)
+		}
+	})
+	// Collect robots directives from <meta name="robots">.
+	c.OnHTML(`meta[name]`, func(e *colly.HTMLElement) {
+		if strings.EqualFold(e.Attr("name"), "robots") {
+			a.Robots = a.Robots.add(e.Attr("content"))
+		}
+	})
+
+	// Record the canonical address, the first one if a page declares several.
+	c.OnHTML(`link[rel][href]`, func(e *colly.HTMLElement) {
+		if a.Canonical == "" && hasToken(e.Attr("rel"), "canonical") {
+			a.Canonical = e.Request.AbsoluteURL(strings.TrimSpace(e.Attr("href")))
+		}
+	})
+
+	// Handle HTTP errors during scraping.
+	c.OnError(func(r *colly.Response, err error) {
+		// Colly reports a 304 as an error.
+		if r.StatusCode == http.StatusNotModified {
+			notModified = true
+			return
+		}
+		s.logger.Warn("Fetch failed", "url", r.Request.URL.String(), "err", err)
+		var open *CircuitOpenError
+		transient = transientStatus(r.StatusCode) && !errors.As(err, &open)
+	})
+
+	// Begin the scraping process by visiting the specified URL.
+	start := time.Now()
+	err := c.Visit(url)
+	if _, static := transport.(staticTransport); !static {
+		s.metrics.Observe("fetch", time.Since(start))
+	}
+	if notModified {
+		return Article{}, false, ErrNotModified
+	}
+	if err != nil {
+		return Article{}, transient, err
+	}
+
+	// Split the byline into names, then return the scraped article and no error.
+	// A byline in which no names can be found is doubtful whatever the extractor thought.
+	a.Authors = byline.ParseURL(url, a.Byline)
+	if c, ok := a.Confidence["byline"]; ok && len(a.Authors) == 0 {
+		c.Score *= 0.5
+		a.Confidence["byline"] = c
+	}
+	a.FieldErrors = fieldErrors(a)
+	return a, false, nil
+}
+
+// fieldErrors says why each of a's extracted fields is empty, or returns nil if none is.
+func fieldErrors(a Article) map[string]string {
+	var errs map[string]string
+	for field, empty := range map[string]bool{
+		"title":     strings.TrimSpace(a.Title) == "",
+		"content":   strings.TrimSpace(a.Content) == "",
+		"byline":    strings.TrimSpace(a.Byline) == "",
+		"published": a.Published.IsZero(),
+		"publisher": a.Publisher == "",
+	} {
+		if empty {
+			if errs == nil {
+				errs = make(map[string]string)
+			}
+			errs[field] = "not found on the page"
+		}
+	}
+	return errs
+}
+
+// hasToken reports whether the space-separated list attr contains token, ignoring case,
+// as HTML compares rel values.
+func hasToken(attr, token string) bool {
+	for _, t := range strings.Fields(attr) {
+		if strings.EqualFold(t, token) {
+			return true
+		}
+	}
+	return false
+}
+
+// FetchHTML downloads the page at url with the same collector settings used for scraping
+// and returns its raw HTML, so it can be inspected or re-extracted without fetching again.
+func (s *Scraper) FetchHTML(url string) (string, error) {
+	return s.FetchHTMLContext(context.Background(), url)
+}
+
+// FetchHTMLContext is like FetchHTML, but gives up once ctx is done, as ScrapeContext does.
+func (s *Scraper) FetchHTMLContext(ctx context.Context, url string) (string, error) {
+	if s.robots != nil {
+		if err := s.checkRobots(ctx, url); err != nil {
+			return "", err
+		}
+	}
+	// Raw HTML is wanted as the page stands, so the empty and length rules, which judge
+	// extracted content, do not apply; only the domain list does.
+	if s.renderer != nil && (s.renderRules == nil || onDomain(url, s.renderRules.Domains)) {
+		return s.renderHTML(ctx, url)
+	}
+	var body string
+	err := s.withRetries(ctx, func() (bool, error) {
+		var transient bool
+		c := s.collector(ctx, s.transport)
+		c.OnResponse(func(r *colly.Response) {
+			body = string(r.Body)
+		})
+		c.OnError(func(r *colly.Response, err error) {
+			var open *CircuitOpenError
+			transient = transientStatus(r.StatusCode) && !errors.As(err, &open)
+		})
+		return transient, c.Visit(url)
+	})
+	if err != nil {
+		return "", err
+	}
+	return body, nil
+}
+
+// Fields that can be listed as required. "author" is the byline, "body" the article
+// content, and "date" the publication date.
+var requirableFields = []string{"title", "author", "date", "body"}
+
+// MissingFieldsError is returned by CheckRequired when required fields came back empty.
+type MissingFieldsError struct {
+	URL    string
+	Fields []string
+}
+
+// Error lists the missing fields.
+func (e *MissingFieldsError) Error() string {
+	return "missing required fields at " + e.URL + ": " + strings.Join(e.Fields, ", ")
+}
+
+// ValidateRequired checks that every name in fields can be required.
+func ValidateRequired(fields []string) error {
+	for _, f := range fields {
+		known := false
+		for _, r := range requirableFields {
+			known = known || f == r
+		}
+		if !known {
+			return fmt.Errorf("field %q cannot be required (supported: %s)", f, strings.Join(requirableFields, ", "))
+		}
+	}
+	return nil
+}
+
+// CheckRequired returns a *MissingFieldsError if any of the required fields is empty
+// in the scraped article.
+func CheckRequired(a Article, required []string) error {
+	var missing []string
+	for _, f := range required {
+		var empty bool
+		switch f {
+		case "title":
+			empty = strings.TrimSpace(a.Title) == ""
+		case "author":
+			empty = strings.TrimSpace(a.Byline) == ""
+		case "date":
+			empty = a.Published.IsZero()
+		case "body":
+			empty = strings.TrimSpace(a.Content) == ""
+		}
+		if empty {
+			missing = append(missing, f)
+		}
+	}
+	if len(missing) > 0 {
+		return &MissingFieldsError{URL: a.URL, Fields: missing}
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package scraper
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingMetrics is a Metrics that counts, safely for concurrent scrapes.
+type countingMetrics struct {
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+func (m *countingMetrics) Count(name string, n int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.counts == nil {
+		m.counts = make(map[string]int64)
+	}
+	m.counts[name] += n
+}
+
+func (m *countingMetrics) Observe(string, time.Duration) {}
+
+func (m *countingMetrics) get(name string) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.counts[name]
+}
+
+// TestConcurrentScrapes runs many scrapes at once through one Scraper with its cache,
+// limits, Retry-After handling, robots.txt, and circuit breaker all in use, so that
+// "go test -race" checks the state they share.
+func TestConcurrentScrapes(t *testing.T) {
+	var busy atomic.Bool
+	good := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/robots.txt":
+			fmt.Fprint(w, "User-agent: *\nDisallow: /private\n")
+		case r.URL.Path == "/busy" && !busy.Swap(true):
+			// The first request is asked to come back later.
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+		default:
+			fmt.Fprintf(w, `<html><head><title>Story %s</title></head><body><article>
+<p class="byline">By Jane Doe</p>
+<p>%s</p></article></body></html>`, r.URL.Path, strings.Repeat("Something happened today. ", 20))
+		}
+	}))
+	defer good.Close()
+	// The failing site is reached as localhost, so that its circuit is not the good one's.
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		http.Error(w, "down", http.StatusInternalServerError)
+	}))
+	defer bad.Close()
+	badURL := strings.Replace(bad.URL, "127.0.0.1", "localhost", 1)
+
+	metrics := &countingMetrics{}
+	s := New(
+		WithCacheDir(t.TempDir()),
+		WithLimits(LimitRule{DomainGlob: "*", Parallelism: 4}),
+		WithRetry(RetryPolicy{Attempts: 2, Backoff: 10 * time.Millisecond}),
+		WithRetryAfter(),
+		WithRobotsTxt(),
+		WithCircuitBreaker(CircuitBreaker{Failures: 2, Cooldown: time.Minute}),
+		WithMetrics(metrics),
+	)
+
+	const n = 40
+	var wg sync.WaitGroup
+	var open, disallowed, articles atomic.Int32
+	errs := make(chan error, n)
+	for i := range n {
+		var url string
+		switch i % 5 {
+		case 0:
+			url = badURL + "/story"
+		case 1:
+			url = good.URL + "/private/story"
+		case 2:
+			url = good.URL + "/busy"
+		default:
+			// Several scrapes of each URL, so that the cache is written and read at once.
+			url = fmt.Sprintf("%s/story/%d", good.URL, i%3)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a, err := s.Scrape(url)
+			var openErr *CircuitOpenError
+			var disallowedErr *DisallowedError
+			switch {
+			case strings.HasPrefix(url, badURL):
+				if errors.As(err, &openErr) {
+					open.Add(1)
+				} else if err == nil {
+					errs <- fmt.Errorf("%s: scraped a failing site", url)
+				}
+			case strings.Contains(url, "/private/"):
+				if !errors.As(err, &disallowedErr) {
+					errs <- fmt.Errorf("%s: err = %v, want a *DisallowedError", url, err)
+				}
+				disallowed.Add(1)
+			case err != nil:
+				errs <- fmt.Errorf("%s: %v", url, err)
+			case !strings.Contains(a.Content, "Something happened today."):
+				errs <- fmt.Errorf("%s: content = %q", url, a.Content)
+			default:
+				articles.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	if open.Load() == 0 {
+		t.Error("no scrape of the failing site was refused by its open circuit")
+	}
+	if got, want := disallowed.Load(), int32(n/5); got != want {
+		t.Errorf("%d scrapes disallowed by robots.txt, want %d", got, want)
+	}
+	if got, want := articles.Load(), int32(3*n/5); got != want {
+		t.Errorf("%d articles scraped, want %d", got, want)
+	}
+	if got := metrics.get("scrapes"); got != n {
+		t.Errorf("scrapes metric = %d, want %d", got, n)
+	}
+}
+
+// TestScrapeHTMLFieldErrors checks that a page missing some fields still yields the
+// ones it has, with the missing ones listed in FieldErrors.
+func TestScrapeHTMLFieldErrors(t *testing.T) {
+	a, err := New().ScrapeHTML("https://example.com/story", `<html><head><title>Story</title></head>
+<body><article><p>Something happened today.</p></article></body></html>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Title != "Story" || !strings.Contains(a.Content, "Something happened today.") {
+		t.Errorf("title = %q, content = %q; want the fields the page has", a.Title, a.Content)
+	}
+	if _, ok := a.FieldErrors["byline"]; !ok {
+		t.Errorf("FieldErrors = %v, want the missing byline listed", a.FieldErrors)
+	}
+	for _, field := range []string{"title", "content"} {
+		if msg, ok := a.FieldErrors[field]; ok {
+			t.Errorf("FieldErrors[%q] = %q for a field that was found", field, msg)
+		}
+	}
+}
+
+// TestCheckRequired checks each requirable field against an article that has it and one
+// that does not.
+func TestCheckRequired(t *testing.T) {
+	full := Article{
+		URL:       "https://example.com/story",
+		Title:     "Story",
+		Byline:    "By Jane Doe",
+		Published: time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC),
+		Content:   "Something happened today.",
+	}
+	for _, tc := range []struct {
+		field string
+		clear func(*Article)
+	}{
+		{"title", func(a *Article) { a.Title = " " }},
+		{"author", func(a *Article) { a.Byline = "" }},
+		{"date", func(a *Article) { a.Published = time.Time{} }},
+		{"body", func(a *Article) { a.Content = "\n" }},
+	} {
+		if err := ValidateRequired([]string{tc.field}); err != nil {
+			t.Errorf("ValidateRequired(%q) = %v", tc.field, err)
+		}
+		if err := CheckRequired(full, []string{tc.field}); err != nil {
+			t.Errorf("CheckRequired(%q) of a full article = %v", tc.field, err)
+		}
+		partial := full
+		tc.clear(&partial)
+		var missing *MissingFieldsError
+		err := CheckRequired(partial, []string{"title", "author", "date", "body"})
+		if !errors.As(err, &missing) || len(missing.Fields) != 1 || missing.Fields[0] != tc.field {
+			t.Errorf("CheckRequired of an article without %s = %v, want only %s missing", tc.field, err, tc.field)
+		}
+	}
+	if err := ValidateRequired([]string{"summary"}); err == nil {
+		t.Error("ValidateRequired accepted an unknown field")
+	}
+}
@@ -0,0 +1,81 @@
+package scraper
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestOAuthBaseString checks the signature base string against the worked example of
+// RFC 5849, section 3.4.1.1.
+func TestOAuthBaseString(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "http://example.com/request?b5=%3D%253D&a3=a&c%40=&a2=r%20b", strings.NewReader("c2&a3=2+q"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	oauth := map[string]string{
+		"oauth_consumer_key":     "9djdj82h48djs9d2",
+		"oauth_token":            "kkk9d7dh3k39sjv7",
+		"oauth_signature_method": "HMAC-SHA1",
+		"oauth_timestamp":        "137131201",
+		"oauth_nonce":            "7d8f3e4a",
+	}
+	got, err := oauthBaseString(req, oauth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = "POST&http%3A%2F%2Fexample.com%2Frequest&a2%3Dr%2520b%26a3%3D2%2520q" +
+		"%26a3%3Da%26b5%3D%253D%25253D%26c%2540%3D%26c2%3D%26oauth_consumer_key%3D9dj" +
+		"dj82h48djs9d2%26oauth_nonce%3D7d8f3e4a%26oauth_signature_method%3DHMAC-SHA1" +
+		"%26oauth_timestamp%3D137131201%26oauth_token%3Dkkk9d7dh3k39sjv7"
+	if got != want {
+		t.Errorf("base string\n got %s\nwant %s", got, want)
+	}
+
+	// The body must still be there to send.
+	body := make([]byte, 32)
+	n, _ := req.Body.Read(body)
+	if string(body[:n]) != "c2&a3=2+q" {
+		t.Errorf("body after signing = %q, want %q", body[:n], "c2&a3=2+q")
+	}
+}
+
+// TestOAuthBaseStringOrder checks that parameters are sorted by name before value, so
+// that "page" comes before "page2" even though "=" sorts after "2".
+func TestOAuthBaseStringOrder(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "https://example.com/list?page2=b&page=a", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := oauthBaseString(req, map[string]string{"oauth_nonce": "n"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = "GET&https%3A%2F%2Fexample.com%2Flist&oauth_nonce%3Dn%26page%3Da%26page2%3Db"
+	if got != want {
+		t.Errorf("base string\n got %s\nwant %s", got, want)
+	}
+}
+
+// TestOAuth1SignerSign checks the Authorization header against the example of RFC 5849,
+// section 1.2.
+func TestOAuth1SignerSign(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "http://photos.example.net/photos?file=vacation.jpg&size=original", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := OAuth1Signer{
+		ConsumerKey:    "dpf43f3p2l4k3l03",
+		ConsumerSecret: "kd94hf93k423kf44",
+		Token:          "nnch734d00sl2jdk",
+		TokenSecret:    "pfkkdhi9sl3r4s00",
+	}
+	if err := s.sign(req, "chapoH", "137131202"); err != nil {
+		t.Fatal(err)
+	}
+	got := req.Header.Get("Authorization")
+	if !strings.Contains(got, `oauth_signature="MdpQcU8iPSUjWoN%2FUDMsK2sui9I%3D"`) {
+		t.Errorf("Authorization = %s, want signature MdpQcU8iPSUjWoN/UDMsK2sui9I=", got)
+	}
+}
@@ -0,0 +1,187 @@
+package scraper
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// JSONLDExtractor reads the schema.org Article (NewsArticle, BlogPosting, and so on) that
+// many publishers embed as JSON-LD for search engines: the headline as the title,
+// articleBody as content, the author names as the byline, datePublished, and the
+// publisher's name. Authors and publishers given as "@id" references to other nodes of
+// the document, as Yoast and other SEO plugins write them, are resolved.
+type JSONLDExtractor struct{}
+
+// Extract implements Extractor.
+func (JSONLDExtractor) Extract(doc *goquery.Selection) Fields {
+	f := Fields{Confidence: make(map[string]Confidence)}
+	doc.Find(`script[type="application/ld+json"]`).EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		var data any
+		if json.Unmarshal([]byte(s.Text()), &data) != nil {
+			// Broken JSON-LD is common; skip the block.
+			return true
+		}
+		article := findArticle(data)
+		if article == nil {
+			return true
+		}
+		nodes := make(map[string]map[string]any)
+		indexNodes(data, nodes)
+		if headline, _ := article["headline"].(string); strings.TrimSpace(headline) != "" {
+			f.Title = cleanTitle(headline)
+			f.Confidence["title"] = Confidence{Score: 0.9, Source: "json-ld:headline"}
+		}
+		if body, _ := article["articleBody"].(string); strings.TrimSpace(body) != "" {
+			f.Content = body
+			f.Confidence["content"] = Confidence{Score: 0.9 * lengthSanity(body), Source: "json-ld:articleBody"}
+		}
+		if names := personNames(resolve(article["author"], nodes)); len(names) > 0 {
+			f.Byline = strings.Join(names, " and ")
+			f.Confidence["byline"] = Confidence{Score: 0.9, Source: "json-ld:author"}
+		}
+		if date, _ := article["datePublished"].(string); date != "" {
+			if t, ok := ParseDate(date); ok {
+				f.Published = t
+				f.Confidence["published"] = Confidence{Score: 0.9, Source: "json-ld:datePublished"}
+			}
+		}
+		if names := personNames(resolve(article["publisher"], nodes)); len(names) > 0 {
+			f.Publisher = names[0]
+			f.Confidence["publisher"] = Confidence{Score: 0.9, Source: "json-ld:publisher"}
+		}
+		return false
+	})
+	return f
+}
+
+// findArticle returns the first object in a JSON-LD document whose @type is an Article
+// type, searching arrays and @graph collections.
+func findArticle(v any) map[string]any {
+	switch v := v.(type) {
+	case []any:
+		for _, item := range v {
+			if a := findArticle(item); a != nil {
+				return a
+			}
+		}
+	case map[string]any:
+		if isArticleType(v["@type"]) {
+			return v
+		}
+		return findArticle(v["@graph"])
+	}
+	return nil
+}
+
+// indexNodes records every object in a JSON-LD document that has an @id, so that
+// references to it can be resolved.
+func indexNodes(v any, nodes map[string]map[string]any) {
+	switch v := v.(type) {
+	case []any:
+		for _, item := range v {
+			indexNodes(item, nodes)
+		}
+	case map[string]any:
+		if id, _ := v["@id"].(string); id != "" && len(v) > 1 {
+			nodes[id] = v
+		}
+		indexNodes(v["@graph"], nodes)
+	}
+}
+
+// resolve replaces {"@id": ...} references in a JSON-LD value, or a list of them, with
+// the nodes they point to. Unknown references are left as they are.
+func resolve(v any, nodes map[string]map[string]any) any {
+	switch v := v.(type) {
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = resolve(item, nodes)
+		}
+		return out
+	case map[string]any:
+		if id, _ := v["@id"].(string); id != "" && v["name"] == nil {
+			if node, ok := nodes[id]; ok {
+				return node
+			}
+		}
+	}
+	return v
+}
+
+// isArticleType reports whether a JSON-LD @type (a string or a list) names an Article:
+// schema.org's Article or one of its subtypes, which all end in "Article" or "Posting".
+func isArticleType(t any) bool {
+	switch t := t.(type) {
+	case string:
+		return strings.HasSuffix(t, "Article") || strings.HasSuffix(t, "Posting")
+	case []any:
+		for _, item := range t {
+			if isArticleType(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// personNames extracts names from a JSON-LD author value: a string, a Person or
+// Organization object, or a list of either.
+func personNames(v any) []string {
+	switch v := v.(type) {
+	case string:
+		if name := strings.TrimSpace(v); name != "" && !strings.HasPrefix(name, "http") {
+			return []string{name}
+		}
+	case map[string]any:
+		if name, _ := v["name"].(string); strings.TrimSpace(name) != "" {
+			return []string{strings.TrimSpace(name)}
+		}
+	case []any:
+		var names []string
+		for _, item := range v {
+			names = append(names, personNames(item)...)
+		}
+		return names
+	}
+	return nil
+}
+
+// MetaExtractor reads the byline from author <meta> tags, the publication time from
+// article:published_time, and the publisher from og:site_name. It finds no content: meta
+// descriptions are summaries, not the article.
+type MetaExtractor struct{}
+
+// authorMetas are the author meta tags checked, most reliable first.
+var authorMetas = []struct{ selector, name string }{
+	{`meta[name="author"]`, "author"},
+	{`meta[property="article:author"]`, "article:author"},
+	{`meta[name="parsely-author"]`, "parsely-author"},
+	{`meta[name="byl"]`, "byl"},
+}
+
+// Extract implements Extractor.
+func (MetaExtractor) Extract(doc *goquery.Selection) Fields {
+	f := Fields{Confidence: make(map[string]Confidence)}
+	for _, m := range authorMetas {
+		content := strings.TrimSpace(doc.Find(m.selector).First().AttrOr("content", ""))
+		// article:author is often a profile URL rather than a name.
+		if content == "" || strings.HasPrefix(content, "http") {
+			continue
+		}
+		f.Byline = content
+		f.Confidence["byline"] = Confidence{Score: 0.75, Source: "meta:" + m.name}
+		break
+	}
+	if t, ok := metaDate(doc); ok {
+		f.Published = t
+		f.Confidence["published"] = Confidence{Score: 0.8, Source: "meta:article:published_time"}
+	}
+	if site := cleanTitle(doc.Find(`meta[property="og:site_name"]`).First().AttrOr("content", "")); site != "" {
+		f.Publisher = site
+		f.Confidence["publisher"] = Confidence{Score: 0.75, Source: "meta:og:site_name"}
+	}
+	return f
+}
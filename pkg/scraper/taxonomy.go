@@ -0,0 +1,91 @@
+package scraper
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// pageTaxonomy reads how doc, the page's <html> element, files the article: its section
+// or desk, from article:section or JSON-LD articleSection; its tags, from article:tag and
+// rel="tag" links; and its keywords, from the news_keywords and keywords meta tags and
+// JSON-LD keywords. Lists keep the page's order, each value once whatever its case.
+func pageTaxonomy(doc *goquery.Selection) (section string, tags, keywords []string) {
+	var ld map[string]any
+	doc.Find(`script[type="application/ld+json"]`).EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		var data any
+		if json.Unmarshal([]byte(s.Text()), &data) == nil {
+			ld = findArticle(data)
+		}
+		return ld == nil
+	})
+
+	section = firstContent(doc, `meta[property="article:section"]`, `meta[name="section"]`)
+	if section == "" {
+		if s := ldStrings(ld["articleSection"]); len(s) > 0 {
+			section = s[0]
+		}
+	}
+	section = cleanTitle(section)
+
+	var t, k terms
+	doc.Find(`meta[property="article:tag"]`).Each(func(_ int, m *goquery.Selection) {
+		t.add(m.AttrOr("content", ""))
+	})
+	doc.Find("a[rel]").Each(func(_ int, a *goquery.Selection) {
+		if hasToken(a.AttrOr("rel", ""), "tag") {
+			t.add(a.Text())
+		}
+	})
+	for _, sel := range []string{`meta[name="news_keywords"]`, `meta[name="keywords"]`} {
+		doc.Find(sel).Each(func(_ int, m *goquery.Selection) {
+			for _, kw := range strings.Split(m.AttrOr("content", ""), ",") {
+				k.add(kw)
+			}
+		})
+	}
+	for _, v := range ldStrings(ld["keywords"]) {
+		for _, kw := range strings.Split(v, ",") {
+			k.add(kw)
+		}
+	}
+	return section, t.list, k.list
+}
+
+// ldStrings returns a JSON-LD value given as a string or a list of strings as a list.
+func ldStrings(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		var out []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// terms collects distinct terms, ignoring case, in the order first seen.
+type terms struct {
+	list []string
+	seen map[string]bool
+}
+
+// add adds term, with its whitespace collapsed, unless it is empty or already there.
+func (t *terms) add(term string) {
+	term = cleanTitle(term)
+	key := strings.ToLower(term)
+	if term == "" || t.seen[key] {
+		return
+	}
+	if t.seen == nil {
+		t.seen = make(map[string]bool)
+	}
+	t.seen[key] = true
+	t.list = append(t.list, term)
+}
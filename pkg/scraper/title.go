@@ -0,0 +1,63 @@
+package scraper
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// titleSeparators split a <title> into the headline and the site name publishers append,
+// as in "Headline | Site" or "Headline - Site".
+var titleSeparators = []string{" | ", " - ", " – ", " — ", " :: "}
+
+// pageTitle finds the headline of doc, the page's <html> element, for extractors that
+// found none: the first <h1>, then og:title, then <title> without the site name. An
+// <h1> is trusted less when the page has several, since one may be the site's logo.
+func pageTitle(doc *goquery.Selection) (string, Confidence) {
+	if h1 := doc.Find("h1"); h1.Length() > 0 {
+		if title := cleanTitle(h1.First().Text()); title != "" {
+			score := 0.85
+			if h1.Length() > 1 {
+				score = 0.6
+			}
+			return title, Confidence{Score: score, Source: "selector:h1"}
+		}
+	}
+	if title := cleanTitle(doc.Find(`meta[property="og:title"]`).First().AttrOr("content", "")); title != "" {
+		return title, Confidence{Score: 0.8, Source: "meta:og:title"}
+	}
+	if title := cleanTitle(doc.Find("head title").First().Text()); title != "" {
+		site := cleanTitle(doc.Find(`meta[property="og:site_name"]`).First().AttrOr("content", ""))
+		for _, sep := range titleSeparators {
+			if head, tail, ok := cutLast(title, sep); ok && siteName(head, tail, site) {
+				title = head
+				break
+			}
+		}
+		return title, Confidence{Score: 0.6, Source: "selector:title"}
+	}
+	return "", Confidence{}
+}
+
+// siteName reports whether tail, the part of a <title> after a separator, is the site
+// name: og:site_name when the page declares one, otherwise a few words shorter than head.
+func siteName(head, tail, site string) bool {
+	if site != "" {
+		return strings.EqualFold(tail, site)
+	}
+	return len(strings.Fields(tail)) <= 4 && len(tail) < len(head)
+}
+
+// cleanTitle collapses the whitespace of a title.
+func cleanTitle(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// cutLast is strings.Cut at the last occurrence of sep.
+func cutLast(s, sep string) (before, after string, found bool) {
+	i := strings.LastIndex(s, sep)
+	if i < 0 {
+		return s, "", false
+	}
+	return s[:i], s[i+len(sep):], true
+}
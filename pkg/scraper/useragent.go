@@ -0,0 +1,26 @@
+package scraper
+
+import "math/rand/v2"
+
+// BrowserUserAgents are the user agents of current desktop browsers, for WithUserAgents.
+// Several publishers block colly's default user agent on sight; these blend in with
+// their readers' traffic.
+var BrowserUserAgents = []string{
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/141.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/141.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/141.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/141.0.0.0 Safari/537.36 Edg/141.0.0.0",
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:143.0) Gecko/20100101 Firefox/143.0",
+	"Mozilla/5.0 (Macintosh; Intel Mac OS X 10.15; rv:143.0) Gecko/20100101 Firefox/143.0",
+	"Mozilla/5.0 (X11; Linux x86_64; rv:143.0) Gecko/20100101 Firefox/143.0",
+	"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/26.0 Safari/605.1.15",
+}
+
+// pickUserAgent returns the user agent for the next scrape: one of the pool at random,
+// or else the fixed user agent, which is empty for colly's default.
+func (s *Scraper) pickUserAgent() string {
+	if len(s.userAgents) > 0 {
+		return s.userAgents[rand.IntN(len(s.userAgents))]
+	}
+	return s.userAgent
+}
@@ -0,0 +1,47 @@
+package scraper
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/xpath"
+	"golang.org/x/net/html"
+)
+
+// XPathPrefix marks a selector as an XPath expression rather than a CSS selector, for
+// what CSS cannot reach, such as the text after a label:
+// "xpath://span[@class='by']/following-sibling::text()[1]".
+const XPathPrefix = "xpath:"
+
+// Select returns what selector matches in s. The selector is CSS, or XPath evaluated
+// from each node of s when it starts with XPathPrefix. Either way, a selector that
+// cannot be parsed matches nothing; ValidateSelector reports why.
+func Select(s *goquery.Selection, selector string) *goquery.Selection {
+	expr, ok := strings.CutPrefix(selector, XPathPrefix)
+	if !ok {
+		return s.Find(selector)
+	}
+	compiled, err := xpath.Compile(expr)
+	if err != nil {
+		return s.FindNodes()
+	}
+	var nodes []*html.Node
+	for _, n := range s.Nodes {
+		nodes = append(nodes, htmlquery.QuerySelectorAll(n, compiled)...)
+	}
+	// An XPath expression may select text nodes or leave the subtree it started from,
+	// which FindNodes would drop, so the matches are added as they are.
+	return s.FindNodes().AddNodes(nodes...)
+}
+
+// ValidateSelector reports whether selector, CSS or XPath as for Select, can be parsed.
+func ValidateSelector(selector string) error {
+	if expr, ok := strings.CutPrefix(selector, XPathPrefix); ok {
+		_, err := xpath.Compile(expr)
+		return err
+	}
+	_, err := cascadia.Compile(selector)
+	return err
+}